@@ -0,0 +1,40 @@
+// Sends the session's CSRF token with every mutating request and returns to the login page when the session ends
+(function () {
+    const originalFetch = window.fetch;
+
+    function csrfToken() {
+        const match = document.cookie.match(/(?:^|;\s*)blog_csrf=([^;]*)/);
+        return match ? decodeURIComponent(match[1]) : '';
+    }
+
+    window.fetch = async function (input, init) {
+        init = init || {};
+        const method = (init.method || 'GET').toUpperCase();
+        if (method !== 'GET' && method !== 'HEAD') {
+            const headers = new Headers(init.headers || {});
+            headers.set('X-CSRF-Token', csrfToken());
+            init.headers = headers;
+        }
+
+        const response = await originalFetch(input, init);
+        if (response.status === 401) {
+            window.location.href = '/admin/login?next=' + encodeURIComponent(window.location.pathname + window.location.search);
+        }
+        return response;
+    };
+
+    document.addEventListener('DOMContentLoaded', function () {
+        const logoutBtn = document.getElementById('logout-btn');
+        if (!logoutBtn) {
+            return;
+        }
+
+        logoutBtn.addEventListener('click', async function () {
+            try {
+                await fetch('/api/auth/logout', { method: 'POST' });
+            } finally {
+                window.location.href = '/admin/login';
+            }
+        });
+    });
+})();
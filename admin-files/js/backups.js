@@ -0,0 +1,110 @@
+// Backups on the settings page
+document.addEventListener('DOMContentLoaded', function() {
+    loadBackups();
+    document.getElementById('createBackupBtn').addEventListener('click', createBackup);
+    document.getElementById('restoreForm').addEventListener('submit', function(e) {
+        e.preventDefault();
+        restoreUploadedBackup();
+    });
+});
+
+async function loadBackups() {
+    const list = document.getElementById('backupsList');
+
+    try {
+        const response = await fetch('/api/backups');
+        if (!response.ok) {
+            throw new Error(`HTTP error! status: ${response.status}`);
+        }
+        const backups = await response.json();
+
+        list.innerHTML = '';
+        if (backups.length === 0) {
+            list.innerHTML = '<li class="token-item token-meta">No backups yet.</li>';
+            return;
+        }
+        backups.forEach(backup => {
+            const li = document.createElement('li');
+            li.className = 'token-item';
+            li.innerHTML = `
+                <span>
+                    <strong>${new Date(backup.created_at).toLocaleString()}</strong>
+                    <span class="token-meta">&middot; ${formatBackupSize(backup.size)}</span>
+                </span>
+                <span>
+                    <a class="btn btn-secondary" href="/api/backups/${encodeURIComponent(backup.name)}">Download</a>
+                    <button type="button" class="btn btn-secondary">Restore</button>
+                </span>`;
+            li.querySelector('button').addEventListener('click', () => restoreBackup(backup));
+            list.appendChild(li);
+        });
+    } catch (error) {
+        console.error('Error loading backups:', error);
+        list.innerHTML = '<li class="token-item">Failed to load backups.</li>';
+    }
+}
+
+async function createBackup() {
+    const button = document.getElementById('createBackupBtn');
+    button.disabled = true;
+    try {
+        const response = await fetch('/api/backups', { method: 'POST' });
+        if (!response.ok) {
+            throw new Error(await response.text());
+        }
+        loadBackups();
+    } catch (error) {
+        console.error('Error creating backup:', error);
+        alert('Failed to create backup: ' + error.message);
+    } finally {
+        button.disabled = false;
+    }
+}
+
+async function restoreBackup(backup) {
+    const date = new Date(backup.created_at).toLocaleString();
+    if (!confirm(`Restore the backup from ${date}? Posts, pages, settings, images and templates will be replaced.`)) {
+        return;
+    }
+    await sendRestore(`/api/backups/${encodeURIComponent(backup.name)}/restore`, {});
+}
+
+async function restoreUploadedBackup() {
+    const input = document.getElementById('restoreFile');
+    if (input.files.length === 0) {
+        return;
+    }
+    if (!confirm('Restore this backup? Posts, pages, settings, images and templates will be replaced.')) {
+        return;
+    }
+
+    const formData = new FormData();
+    formData.append('file', input.files[0]);
+    await sendRestore('/api/backups/restore', { body: formData });
+    input.value = '';
+}
+
+async function sendRestore(url, options) {
+    try {
+        const response = await fetch(url, Object.assign({ method: 'POST' }, options));
+        if (!response.ok) {
+            throw new Error(await response.text());
+        }
+        const result = await response.json();
+        alert(`Backup restored. The previous data was saved as ${result.safety_backup}. Publish to update the site.`);
+        loadBackups();
+    } catch (error) {
+        console.error('Error restoring backup:', error);
+        alert('Restore failed: ' + error.message);
+    }
+}
+
+function formatBackupSize(size) {
+    if (size >= 1024 * 1024) {
+        return (size / (1024 * 1024)).toFixed(1) + ' MB';
+    }
+    if (size >= 1024) {
+        return (size / 1024).toFixed(1) + ' KB';
+    }
+    return size + ' B';
+}
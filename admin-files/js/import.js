@@ -0,0 +1,40 @@
+// Content import on the settings page
+document.addEventListener('DOMContentLoaded', function() {
+    document.getElementById('importForm').addEventListener('submit', function(e) {
+        e.preventDefault();
+        importContent();
+    });
+});
+
+async function importContent() {
+    const input = document.getElementById('importFile');
+    if (input.files.length === 0) {
+        return;
+    }
+
+    const formData = new FormData();
+    formData.append('file', input.files[0]);
+
+    try {
+        const response = await fetch('/api/import', {
+            method: 'POST',
+            body: formData
+        });
+        const data = await response.json();
+        const result = response.ok ? data : data.result;
+
+        let message = response.ok ? 'Import finished.' : data.error;
+        if (result) {
+            message += `\n\nPosts: ${result.posts_created} created, ${result.posts_updated} updated` +
+                `\nPages: ${result.pages_created} created, ${result.pages_updated} updated` +
+                `\nPortfolio: ${result.portfolio_created} created, ${result.portfolio_updated} updated`;
+        }
+        alert(message);
+        if (response.ok) {
+            input.value = '';
+        }
+    } catch (error) {
+        console.error('Error importing content:', error);
+        alert('Error importing content');
+    }
+}
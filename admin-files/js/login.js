@@ -0,0 +1,41 @@
+document.addEventListener('DOMContentLoaded', function () {
+    const form = document.getElementById('loginForm');
+    const errorEl = document.getElementById('loginError');
+    const isSetup = form.dataset.setup === 'true';
+
+    form.addEventListener('submit', async function (e) {
+        e.preventDefault();
+        errorEl.hidden = true;
+
+        const btn = form.querySelector('button[type="submit"]');
+        btn.disabled = true;
+
+        try {
+            const response = await fetch(isSetup ? '/api/auth/setup' : '/api/auth/login', {
+                method: 'POST',
+                headers: {
+                    'Content-Type': 'application/json'
+                },
+                body: JSON.stringify({
+                    username: document.getElementById('username').value,
+                    password: document.getElementById('password').value
+                })
+            });
+
+            if (response.ok) {
+                window.location.href = form.dataset.next || '/admin/dashboard';
+                return;
+            }
+
+            const result = await response.json().catch(function () { return {}; });
+            errorEl.textContent = result.error || 'Sign in failed. Please try again.';
+            errorEl.hidden = false;
+        } catch (error) {
+            console.error('Login error:', error);
+            errorEl.textContent = 'Network error. Please try again.';
+            errorEl.hidden = false;
+        } finally {
+            btn.disabled = false;
+        }
+    });
+});
@@ -148,6 +148,9 @@ document.addEventListener('DOMContentLoaded', function() {
     const pathMatch = window.location.pathname.match(/^\/admin\/pages\/(\d+)\/edit$/);
     if (pathMatch) {
         const pageId = pathMatch[1];
+        const previewLink = document.getElementById('previewLink');
+        previewLink.href = `/admin/preview/pages/${pageId}`;
+        previewLink.classList.remove('hidden');
         // Fetch page data
         fetch(`/api/pages/${pageId}`)
             .then(response => {
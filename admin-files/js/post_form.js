@@ -21,6 +21,7 @@ if (pathMatch) {
         content = document.getElementById('content').value.trim();
     }
     const published = document.getElementById('published').checked;
+    const publishAtValue = document.getElementById('publishAt').value;
 
     // Basic validation
     if (!title || !slug || !content) {
@@ -34,6 +35,7 @@ if (pathMatch) {
         tags: tags,
         content: content,
         published: published,
+        publish_at: publishAtValue ? new Date(publishAtValue).toISOString() : null,
         featuredImage: document.getElementById('featuredImageURL').value
     };
 
@@ -193,6 +195,9 @@ document.addEventListener('DOMContentLoaded', function() {
     const pathMatch = window.location.pathname.match(/^\/admin\/posts\/(\d+)\/edit$/);
     if (pathMatch) {
         postId = pathMatch[1];
+        const previewLink = document.getElementById('previewLink');
+        previewLink.href = `/admin/preview/posts/${postId}`;
+        previewLink.classList.remove('hidden');
         // Fetch post data
         fetch(`/api/posts/${postId}`)
             .then(response => {
@@ -212,6 +217,12 @@ document.addEventListener('DOMContentLoaded', function() {
                     document.getElementById('content').value = post.content || '';
                 }
                 document.getElementById('published').checked = post.published || false;
+                if (post.publish_at) {
+                    // datetime-local expects local time without a timezone suffix
+                    const publishAt = new Date(post.publish_at);
+                    publishAt.setMinutes(publishAt.getMinutes() - publishAt.getTimezoneOffset());
+                    document.getElementById('publishAt').value = publishAt.toISOString().slice(0, 16);
+                }
                 document.getElementById('slug').dataset.original = post.slug || '';
 
                 // Set featured image
@@ -1,12 +1,28 @@
 let currentPage = 1;
+let searchQuery = '';
 const limit = 10;
 
 document.addEventListener('DOMContentLoaded', function() {
     loadPosts(currentPage);
+
+    const searchInput = document.getElementById('post-search');
+    let searchTimer = null;
+    searchInput.addEventListener('input', () => {
+        clearTimeout(searchTimer);
+        searchTimer = setTimeout(() => {
+            searchQuery = searchInput.value.trim();
+            currentPage = 1;
+            loadPosts(currentPage);
+        }, 250);
+    });
 });
 
 function loadPosts(page) {
-    fetch(`/api/posts?page=${page}&limit=${limit}`)
+    const params = new URLSearchParams({ page: page, limit: limit });
+    if (searchQuery) {
+        params.set('q', searchQuery);
+    }
+    fetch(`/api/posts?${params}`)
         .then(response => {
             if (!response.ok) {
                 throw new Error(`HTTP error! status: ${response.status}`);
@@ -41,9 +57,16 @@ function loadPosts(page) {
                 row.className = 'admin-table-row';
                 row.setAttribute('data-post-id', post.id);
                 row.innerHTML = `
-                <td class="table-cell-title">${post.title}</td>
+                <td class="table-cell-title">
+                    ${post.title}
+                    ${post.snippet ? `<div class="table-cell-snippet">${post.snippet}</div>` : ''}
+                </td>
                 <td>
-                    ${post.published ? `
+                    ${post.scheduled ? `
+                        <span class="badge-status badge-warning" title="Publishes ${new Date(post.publish_at).toLocaleString()}">
+                            <span class="badge-dot"></span>
+                            Scheduled
+                        </span>` : post.published ? `
                         <span class="badge-status badge-success">
                             <span class="badge-dot"></span>
                             Published
@@ -72,7 +95,9 @@ function loadPosts(page) {
             // Update total count
             const countInfo = document.getElementById('posts-count');
             if (countInfo) {
-                countInfo.textContent = `Total: ${paginationData.total} posts`;
+                countInfo.textContent = searchQuery
+                    ? `${paginationData.total} posts match "${searchQuery}"`
+                    : `Total: ${paginationData.total} posts`;
             }
 
             updatePagination(paginationData);
@@ -0,0 +1,103 @@
+// Revision history panel for the post and page edit forms
+document.addEventListener('DOMContentLoaded', function() {
+    const match = window.location.pathname.match(/^\/admin\/(posts|pages)\/(\d+)\/edit$/);
+    if (!match) {
+        return;
+    }
+
+    const baseUrl = `/api/${match[1]}/${match[2]}/revisions`;
+    document.getElementById('revisionsCard').classList.remove('hidden');
+    loadRevisions(baseUrl);
+});
+
+async function loadRevisions(baseUrl) {
+    const list = document.getElementById('revisionsList');
+
+    try {
+        const response = await fetch(baseUrl);
+        if (!response.ok) {
+            throw new Error(`HTTP error! status: ${response.status}`);
+        }
+        const data = await response.json();
+
+        list.innerHTML = '';
+        data.revisions.forEach((revision, index) => {
+            const li = document.createElement('li');
+            li.className = 'revision-item';
+            const label = index === 0 ? ' (current)' : '';
+            li.innerHTML = `
+                <span class="revision-meta">#${revision.revision}${label} &middot; ${new Date(revision.created_at).toLocaleString()} &middot; ${escapeRevisionHTML(revision.title)}</span>
+                <span class="revision-actions">
+                    <button type="button" class="btn btn-secondary" data-action="diff">Changes</button>
+                    ${index === 0 ? '' : '<button type="button" class="btn btn-secondary" data-action="restore">Restore</button>'}
+                </span>`;
+            li.querySelector('[data-action="diff"]').addEventListener('click', () => showRevisionDiff(baseUrl, revision.revision));
+            const restoreBtn = li.querySelector('[data-action="restore"]');
+            if (restoreBtn) {
+                restoreBtn.addEventListener('click', () => restoreRevision(baseUrl, revision.revision));
+            }
+            list.appendChild(li);
+        });
+    } catch (error) {
+        console.error('Error loading revisions:', error);
+        list.innerHTML = '<li class="revision-item">Failed to load revisions.</li>';
+    }
+}
+
+async function showRevisionDiff(baseUrl, revision) {
+    const pre = document.getElementById('revisionDiff');
+
+    try {
+        const response = await fetch(`${baseUrl}/diff?to=${revision}`);
+        if (!response.ok) {
+            throw new Error(`HTTP error! status: ${response.status}`);
+        }
+        const data = await response.json();
+
+        pre.innerHTML = '';
+        const diff = data.diff || 'No changes.';
+        diff.split('\n').forEach(line => {
+            const span = document.createElement('span');
+            if (line.startsWith('@@')) {
+                span.className = 'diff-hunk';
+            } else if (line.startsWith('+') && !line.startsWith('+++')) {
+                span.className = 'diff-add';
+            } else if (line.startsWith('-') && !line.startsWith('---')) {
+                span.className = 'diff-del';
+            }
+            span.textContent = line + '\n';
+            pre.appendChild(span);
+        });
+        pre.classList.remove('hidden');
+    } catch (error) {
+        console.error('Error loading diff:', error);
+        alert('Error loading revision changes.');
+    }
+}
+
+async function restoreRevision(baseUrl, revision) {
+    if (!confirm(`Restore revision #${revision}? The current content is kept as a revision.`)) {
+        return;
+    }
+
+    try {
+        const response = await fetch(`${baseUrl}/${revision}/restore`, { method: 'POST' });
+        if (response.ok) {
+            alert('Revision restored successfully!');
+            window.location.reload();
+        } else if (response.status === 409) {
+            alert('Cannot restore: the slug of this revision is already used by another entry.');
+        } else {
+            alert('Error restoring revision.');
+        }
+    } catch (error) {
+        console.error('Error restoring revision:', error);
+        alert('Error restoring revision.');
+    }
+}
+
+function escapeRevisionHTML(text) {
+    const div = document.createElement('div');
+    div.textContent = text || '';
+    return div.innerHTML;
+}
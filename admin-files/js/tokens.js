@@ -0,0 +1,135 @@
+// API token management on the settings page
+document.addEventListener('DOMContentLoaded', function() {
+    loadTokens();
+    document.getElementById('tokenForm').addEventListener('submit', function(e) {
+        e.preventDefault();
+        createToken();
+    });
+});
+
+async function loadTokens() {
+    const list = document.getElementById('tokensList');
+
+    try {
+        const response = await fetch('/api/tokens');
+        if (!response.ok) {
+            throw new Error(`HTTP error! status: ${response.status}`);
+        }
+        const data = await response.json();
+
+        renderScopeOptions(data.scopes);
+
+        list.innerHTML = '';
+        if (data.tokens.length === 0) {
+            list.innerHTML = '<li class="token-item token-meta">No tokens yet.</li>';
+            return;
+        }
+        data.tokens.forEach(token => {
+            const li = document.createElement('li');
+            li.className = 'token-item' + (token.revoked_at ? ' token-revoked' : '');
+            li.innerHTML = `
+                <span>
+                    <strong>${escapeTokenHTML(token.name)}</strong>
+                    <code>${escapeTokenHTML(token.prefix)}&hellip;</code>
+                    <span class="token-meta">&middot; ${escapeTokenHTML(token.scopes.join(', '))} &middot; ${describeToken(token)}</span>
+                </span>
+                ${token.revoked_at ? '' : '<button type="button" class="btn btn-secondary">Revoke</button>'}`;
+            const revokeBtn = li.querySelector('button');
+            if (revokeBtn) {
+                revokeBtn.addEventListener('click', () => revokeToken(token));
+            }
+            list.appendChild(li);
+        });
+    } catch (error) {
+        console.error('Error loading tokens:', error);
+        list.innerHTML = '<li class="token-item">Failed to load tokens.</li>';
+    }
+}
+
+function renderScopeOptions(scopes) {
+    const container = document.getElementById('tokenScopes');
+    if (container.children.length > 0) {
+        return;
+    }
+    scopes.forEach(scope => {
+        const div = document.createElement('div');
+        div.className = 'form-checkbox-group';
+        div.innerHTML = `
+            <input type="checkbox" id="scope-${scope}" value="${scope}" class="form-checkbox">
+            <label for="scope-${scope}" class="form-checkbox-label">${scope}</label>`;
+        container.appendChild(div);
+    });
+}
+
+function describeToken(token) {
+    if (token.revoked_at) {
+        return 'revoked ' + new Date(token.revoked_at).toLocaleDateString();
+    }
+    const parts = [];
+    parts.push(token.expires_at ? 'expires ' + new Date(token.expires_at).toLocaleDateString() : 'never expires');
+    parts.push(token.last_used_at ? 'last used ' + new Date(token.last_used_at).toLocaleString() : 'never used');
+    return parts.join(' &middot; ');
+}
+
+async function createToken() {
+    const scopes = Array.from(document.querySelectorAll('#tokenScopes input:checked')).map(input => input.value);
+    if (scopes.length === 0) {
+        alert('Select at least one scope.');
+        return;
+    }
+
+    const days = document.getElementById('tokenExpiry').value;
+    const body = {
+        name: document.getElementById('tokenName').value.trim(),
+        scopes: scopes
+    };
+    if (days) {
+        body.expires_at = new Date(Date.now() + parseInt(days, 10) * 24 * 60 * 60 * 1000).toISOString();
+    }
+
+    try {
+        const response = await fetch('/api/tokens', {
+            method: 'POST',
+            headers: {
+                'Content-Type': 'application/json'
+            },
+            body: JSON.stringify(body)
+        });
+        const result = await response.json();
+        if (!response.ok) {
+            alert('Failed to create token: ' + (result.error || response.status));
+            return;
+        }
+
+        document.getElementById('newTokenValue').textContent = result.token;
+        document.getElementById('newTokenBox').classList.remove('hidden');
+        document.getElementById('tokenForm').reset();
+        loadTokens();
+    } catch (error) {
+        console.error('Error creating token:', error);
+        alert('Network error. Please try again.');
+    }
+}
+
+async function revokeToken(token) {
+    if (!confirm(`Revoke the token "${token.name}"? Scripts using it will stop working.`)) {
+        return;
+    }
+
+    try {
+        const response = await fetch(`/api/tokens/${token.id}`, { method: 'DELETE' });
+        if (!response.ok) {
+            throw new Error(`HTTP error! status: ${response.status}`);
+        }
+        loadTokens();
+    } catch (error) {
+        console.error('Error revoking token:', error);
+        alert('Failed to revoke token.');
+    }
+}
+
+function escapeTokenHTML(text) {
+    const div = document.createElement('div');
+    div.textContent = text;
+    return div.innerHTML;
+}
@@ -0,0 +1,213 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/models"
+	"github.com/ariefbayu/personal-blog-generator/internal/repository"
+)
+
+const (
+	// SessionCookie holds the session token. It is HttpOnly so scripts cannot read it.
+	SessionCookie = "blog_session"
+	// CSRFCookie holds the session's CSRF token so the admin scripts can echo it in CSRFHeader
+	CSRFCookie = "blog_csrf"
+	// CSRFHeader must carry the session's CSRF token on every mutating request
+	CSRFHeader = "X-CSRF-Token"
+	// LoginPath is where unauthenticated admin page requests are redirected
+	LoginPath = "/admin/login"
+)
+
+// DefaultSessionTTL is how long a login lasts
+const DefaultSessionTTL = 7 * 24 * time.Hour
+
+type contextKey int
+
+const userContextKey contextKey = iota
+
+// Authenticator manages login sessions and API tokens and guards routes
+type Authenticator struct {
+	users      *repository.UserRepository
+	tokens     *repository.APITokenRepository
+	SessionTTL time.Duration
+}
+
+func NewAuthenticator(users *repository.UserRepository, tokens *repository.APITokenRepository) *Authenticator {
+	return &Authenticator{users: users, tokens: tokens, SessionTTL: DefaultSessionTTL}
+}
+
+// Login starts a session for the user and sets the session and CSRF cookies
+func (a *Authenticator) Login(w http.ResponseWriter, r *http.Request, user *models.User) error {
+	token, err := NewToken()
+	if err != nil {
+		return err
+	}
+	csrfToken, err := NewToken()
+	if err != nil {
+		return err
+	}
+
+	session := &models.Session{
+		TokenHash: HashToken(token),
+		UserID:    user.ID,
+		CSRFToken: csrfToken,
+		ExpiresAt: time.Now().Add(a.SessionTTL),
+	}
+	if err := a.users.CreateSession(session); err != nil {
+		return err
+	}
+
+	// Logging in is a good moment to drop sessions nobody will use again
+	a.users.DeleteExpiredSessions(time.Now())
+
+	secure := isSecure(r)
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionCookie,
+		Value:    token,
+		Path:     "/",
+		Expires:  session.ExpiresAt,
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.SetCookie(w, &http.Cookie{
+		Name:     CSRFCookie,
+		Value:    csrfToken,
+		Path:     "/",
+		Expires:  session.ExpiresAt,
+		Secure:   secure,
+		SameSite: http.SameSiteStrictMode,
+	})
+	return nil
+}
+
+// Logout ends the request's session and clears its cookies
+func (a *Authenticator) Logout(w http.ResponseWriter, r *http.Request) error {
+	if cookie, err := r.Cookie(SessionCookie); err == nil {
+		if err := a.users.DeleteSession(HashToken(cookie.Value)); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range []string{SessionCookie, CSRFCookie} {
+		http.SetCookie(w, &http.Cookie{
+			Name:     name,
+			Value:    "",
+			Path:     "/",
+			MaxAge:   -1,
+			HttpOnly: name == SessionCookie,
+			Secure:   isSecure(r),
+		})
+	}
+	return nil
+}
+
+// CurrentUser returns the user of the request's session, or nil when it has none
+func (a *Authenticator) CurrentUser(r *http.Request) (*models.User, *models.Session, error) {
+	cookie, err := r.Cookie(SessionCookie)
+	if err != nil || cookie.Value == "" {
+		return nil, nil, nil
+	}
+
+	session, err := a.users.GetSession(HashToken(cookie.Value))
+	if err == sql.ErrNoRows {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	user, err := a.users.GetUserByID(session.UserID)
+	if err == sql.ErrNoRows {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, session, nil
+}
+
+// RequireUser rejects requests without a valid session or API token.
+// API requests get a 401, admin pages redirect to the login page.
+// Mutating session requests must also send the session's CSRF token in CSRFHeader.
+// Token requests need no CSRF token, since browsers never attach the Authorization header on their own.
+func (a *Authenticator) RequireUser(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if bearer, ok := bearerToken(r); ok {
+			user, token, err := a.bearerUser(bearer)
+			if err != nil {
+				http.Error(w, "Failed to check token", http.StatusInternalServerError)
+				return
+			}
+			if user == nil {
+				writeJSONError(w, "Invalid or expired token", http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(WithToken(WithUser(r.Context(), user), token)))
+			return
+		}
+
+		user, session, err := a.CurrentUser(r)
+		if err != nil {
+			http.Error(w, "Failed to check session", http.StatusInternalServerError)
+			return
+		}
+
+		if user == nil {
+			if strings.HasPrefix(r.URL.Path, "/api/") {
+				writeJSONError(w, "Authentication required", http.StatusUnauthorized)
+				return
+			}
+			http.Redirect(w, r, LoginPath+"?next="+url.QueryEscape(r.URL.RequestURI()), http.StatusFound)
+			return
+		}
+
+		if isMutating(r.Method) && !validCSRFToken(r.Header.Get(CSRFHeader), session.CSRFToken) {
+			writeJSONError(w, "Invalid CSRF token", http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(WithUser(r.Context(), user)))
+	})
+}
+
+// WithUser returns a context carrying the authenticated user
+func WithUser(ctx context.Context, user *models.User) context.Context {
+	return context.WithValue(ctx, userContextKey, user)
+}
+
+// UserFromContext returns the authenticated user stored by RequireUser, or nil
+func UserFromContext(ctx context.Context) *models.User {
+	user, _ := ctx.Value(userContextKey).(*models.User)
+	return user
+}
+
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	return true
+}
+
+func validCSRFToken(got, want string) bool {
+	return got != "" && subtle.ConstantTimeCompare([]byte(got), []byte(want)) == 1
+}
+
+// isSecure reports whether the request reached us over HTTPS, directly or through the nginx proxy
+func isSecure(r *http.Request) bool {
+	return r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https"
+}
+
+func writeJSONError(w http.ResponseWriter, message string, status int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
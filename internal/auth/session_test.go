@@ -0,0 +1,169 @@
+package auth
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/db"
+	"github.com/ariefbayu/personal-blog-generator/internal/models"
+	"github.com/ariefbayu/personal-blog-generator/internal/repository"
+)
+
+func setupAuthenticator(t *testing.T) (*Authenticator, *repository.UserRepository) {
+	testDB, err := db.Connect(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to connect to test DB: %v", err)
+	}
+	t.Cleanup(func() { testDB.Close() })
+	if err := db.Migrate(testDB); err != nil {
+		t.Fatalf("Failed to migrate test DB: %v", err)
+	}
+
+	users := repository.NewUserRepository(testDB)
+	return NewAuthenticator(users, repository.NewAPITokenRepository(testDB)), users
+}
+
+func TestRequireUser(t *testing.T) {
+	authenticator, users := setupAuthenticator(t)
+	user, err := CreateInitialUser(users, "admin", "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	protected := authenticator.RequireUser(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := UserFromContext(r.Context()); got == nil || got.ID != user.ID {
+			t.Errorf("Expected user %d in context, got %+v", user.ID, got)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	// No session
+	w := httptest.NewRecorder()
+	protected.ServeHTTP(w, httptest.NewRequest("GET", "/api/posts", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for API request without session, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	protected.ServeHTTP(w, httptest.NewRequest("GET", "/admin/posts?page=2", nil))
+	if w.Code != http.StatusFound || w.Header().Get("Location") != "/admin/login?next=%2Fadmin%2Fposts%3Fpage%3D2" {
+		t.Errorf("Expected redirect to login, got %d %q", w.Code, w.Header().Get("Location"))
+	}
+
+	// Log in and collect the cookies
+	w = httptest.NewRecorder()
+	if err := authenticator.Login(w, httptest.NewRequest("POST", "/api/auth/login", nil), user); err != nil {
+		t.Fatal(err)
+	}
+	var sessionCookie, csrfCookie *http.Cookie
+	for _, c := range w.Result().Cookies() {
+		switch c.Name {
+		case SessionCookie:
+			sessionCookie = c
+		case CSRFCookie:
+			csrfCookie = c
+		}
+	}
+	if sessionCookie == nil || csrfCookie == nil {
+		t.Fatal("Expected session and CSRF cookies")
+	}
+	if !sessionCookie.HttpOnly {
+		t.Error("Session cookie should be HttpOnly")
+	}
+
+	req := httptest.NewRequest("GET", "/api/posts", nil)
+	req.AddCookie(sessionCookie)
+	w = httptest.NewRecorder()
+	protected.ServeHTTP(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Expected GET with session to pass, got %d", w.Code)
+	}
+
+	// Mutating requests need the CSRF token
+	req = httptest.NewRequest("DELETE", "/api/posts/1", nil)
+	req.AddCookie(sessionCookie)
+	w = httptest.NewRecorder()
+	protected.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 without CSRF token, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest("DELETE", "/api/posts/1", nil)
+	req.AddCookie(sessionCookie)
+	req.Header.Set(CSRFHeader, "wrong")
+	w = httptest.NewRecorder()
+	protected.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 with wrong CSRF token, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest("DELETE", "/api/posts/1", nil)
+	req.AddCookie(sessionCookie)
+	req.Header.Set(CSRFHeader, csrfCookie.Value)
+	w = httptest.NewRecorder()
+	protected.ServeHTTP(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Expected DELETE with CSRF token to pass, got %d", w.Code)
+	}
+
+	// Logging out ends the session
+	req = httptest.NewRequest("POST", "/api/auth/logout", nil)
+	req.AddCookie(sessionCookie)
+	if err := authenticator.Logout(httptest.NewRecorder(), req); err != nil {
+		t.Fatal(err)
+	}
+	req = httptest.NewRequest("GET", "/api/posts", nil)
+	req.AddCookie(sessionCookie)
+	w = httptest.NewRecorder()
+	protected.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 after logout, got %d", w.Code)
+	}
+}
+
+func TestHashPassword(t *testing.T) {
+	if _, err := HashPassword("short"); err != ErrPasswordTooShort {
+		t.Errorf("Expected ErrPasswordTooShort, got %v", err)
+	}
+
+	hash, err := HashPassword("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !CheckPassword(hash, "correct horse") {
+		t.Error("Expected password to match its hash")
+	}
+	if CheckPassword(hash, "wrong horse") {
+		t.Error("Expected wrong password not to match")
+	}
+}
+
+func TestDeleteExpiredSessions(t *testing.T) {
+	_, users := setupAuthenticator(t)
+	user, err := CreateInitialUser(users, "admin", "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	soon := &models.Session{TokenHash: "soon", UserID: user.ID, CSRFToken: "a", ExpiresAt: now.Add(time.Hour)}
+	later := &models.Session{TokenHash: "later", UserID: user.ID, CSRFToken: "b", ExpiresAt: now.Add(3 * time.Hour)}
+	for _, session := range []*models.Session{soon, later} {
+		if err := users.CreateSession(session); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := users.DeleteExpiredSessions(now.Add(2 * time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := users.GetSession("soon"); err != sql.ErrNoRows {
+		t.Errorf("Expected the expired session to be deleted, got %v", err)
+	}
+	if _, err := users.GetSession("later"); err != nil {
+		t.Errorf("Expected the other session to be kept, got %v", err)
+	}
+}
@@ -0,0 +1,181 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/models"
+)
+
+// Scopes an API token can be granted. Signed-in browser sessions have every scope.
+const (
+	ScopePostsRead      = "posts:read"
+	ScopePostsWrite     = "posts:write"
+	ScopePagesRead      = "pages:read"
+	ScopePagesWrite     = "pages:write"
+	ScopePortfolioRead  = "portfolio:read"
+	ScopePortfolioWrite = "portfolio:write"
+	ScopeMediaWrite     = "media:write"
+	ScopeSettingsRead   = "settings:read"
+	ScopeSettingsWrite  = "settings:write"
+	ScopeTemplatesRead  = "templates:read"
+	ScopeTemplatesWrite = "templates:write"
+	ScopePublish        = "publish"
+	ScopeExport         = "export"
+	ScopeImport         = "import"
+	ScopeBackup         = "backup"
+)
+
+// Scopes lists every scope in the order shown to users
+var Scopes = []string{
+	ScopePostsRead, ScopePostsWrite,
+	ScopePagesRead, ScopePagesWrite,
+	ScopePortfolioRead, ScopePortfolioWrite,
+	ScopeMediaWrite,
+	ScopeSettingsRead, ScopeSettingsWrite,
+	ScopeTemplatesRead, ScopeTemplatesWrite,
+	ScopePublish,
+	ScopeExport, ScopeImport,
+	ScopeBackup,
+}
+
+// tokenPrefix starts every API token, so leaked tokens are easy to recognise
+const tokenPrefix = "pbg_"
+
+// ErrInvalidScope is returned when creating a token with an unknown scope
+var ErrInvalidScope = errors.New("invalid scope")
+
+// ErrTokenNameRequired is returned when creating a token without a name
+var ErrTokenNameRequired = errors.New("token name is required")
+
+const tokenContextKey contextKey = iota + 1
+
+// ValidScope reports whether scope is a known scope
+func ValidScope(scope string) bool {
+	for _, s := range Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateAPIToken creates a token for the user and returns it with its plain text value, which is never stored
+func (a *Authenticator) CreateAPIToken(user *models.User, name string, scopes []string, expiresAt *time.Time) (*models.APIToken, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, "", ErrTokenNameRequired
+	}
+
+	seen := map[string]bool{}
+	var unique []string
+	for _, scope := range scopes {
+		if !ValidScope(scope) {
+			return nil, "", ErrInvalidScope
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			unique = append(unique, scope)
+		}
+	}
+	if len(unique) == 0 {
+		return nil, "", ErrInvalidScope
+	}
+
+	random, err := NewToken()
+	if err != nil {
+		return nil, "", err
+	}
+	plain := tokenPrefix + random
+
+	token := &models.APIToken{
+		UserID:    user.ID,
+		Name:      name,
+		TokenHash: HashToken(plain),
+		Prefix:    plain[:len(tokenPrefix)+8],
+		Scopes:    unique,
+		ExpiresAt: expiresAt,
+	}
+	if err := a.tokens.CreateAPIToken(token); err != nil {
+		return nil, "", err
+	}
+	return token, plain, nil
+}
+
+// bearerUser authenticates a request carrying an "Authorization: Bearer" API token.
+// It returns a nil user when the token is unknown, expired or revoked, and an error when the lookup fails.
+func (a *Authenticator) bearerUser(bearer string) (*models.User, *models.APIToken, error) {
+	token, err := a.tokens.GetAPITokenByHash(HashToken(bearer))
+	if err == sql.ErrNoRows {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	now := time.Now()
+	if !token.Active(now) {
+		return nil, nil, nil
+	}
+
+	user, err := a.users.GetUserByID(token.UserID)
+	if err == sql.ErrNoRows {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := a.tokens.TouchAPIToken(token.ID, now); err != nil {
+		return nil, nil, err
+	}
+	return user, token, nil
+}
+
+// bearerToken returns the token from an "Authorization: Bearer" header, if any
+func bearerToken(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	if len(header) < 7 || !strings.EqualFold(header[:7], "Bearer ") {
+		return "", false
+	}
+	return strings.TrimSpace(header[7:]), true
+}
+
+// RequireScope lets API token requests through only when the token has the scope.
+// Session requests are not restricted.
+func RequireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if token := TokenFromContext(r.Context()); token != nil && !token.HasScope(scope) {
+				writeJSONError(w, "Token is missing the "+scope+" scope", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireSession rejects API token requests, for routes only a signed-in browser may use
+func RequireSession(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if TokenFromContext(r.Context()) != nil {
+			writeJSONError(w, "API tokens cannot be used here", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// WithToken returns a context carrying the API token that authenticated the request
+func WithToken(ctx context.Context, token *models.APIToken) context.Context {
+	return context.WithValue(ctx, tokenContextKey, token)
+}
+
+// TokenFromContext returns the API token that authenticated the request, or nil for session requests
+func TokenFromContext(ctx context.Context) *models.APIToken {
+	token, _ := ctx.Value(tokenContextKey).(*models.APIToken)
+	return token
+}
@@ -0,0 +1,111 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/db"
+	"github.com/ariefbayu/personal-blog-generator/internal/repository"
+)
+
+func TestBearerTokens(t *testing.T) {
+	authenticator, users := setupAuthenticator(t)
+	user, err := CreateInitialUser(users, "admin", "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := authenticator.CreateAPIToken(user, "ci", []string{"posts:everything"}, nil); err != ErrInvalidScope {
+		t.Errorf("Expected ErrInvalidScope, got %v", err)
+	}
+	if _, _, err := authenticator.CreateAPIToken(user, " ", []string{ScopePostsWrite}, nil); err != ErrTokenNameRequired {
+		t.Errorf("Expected ErrTokenNameRequired, got %v", err)
+	}
+
+	token, plain, err := authenticator.CreateAPIToken(user, "ci", []string{ScopePostsWrite}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.TokenHash == plain || token.Prefix != plain[:len(token.Prefix)] {
+		t.Error("Expected only a hash and a display prefix of the token to be kept")
+	}
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	route := func(h http.Handler) http.Handler {
+		return authenticator.RequireUser(h)
+	}
+
+	request := func(h http.Handler, method, bearer string) int {
+		req := httptest.NewRequest(method, "/api/posts", nil)
+		if bearer != "" {
+			req.Header.Set("Authorization", "Bearer "+bearer)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// A token with the scope needs no CSRF token
+	if code := request(route(RequireScope(ScopePostsWrite)(ok)), "POST", plain); code != http.StatusNoContent {
+		t.Errorf("Expected token with scope to pass, got %d", code)
+	}
+	if code := request(route(RequireScope(ScopePublish)(ok)), "POST", plain); code != http.StatusForbidden {
+		t.Errorf("Expected token without scope to get 403, got %d", code)
+	}
+	if code := request(route(RequireSession(ok)), "GET", plain); code != http.StatusForbidden {
+		t.Errorf("Expected token on a session-only route to get 403, got %d", code)
+	}
+	if code := request(route(ok), "GET", "pbg_unknown"); code != http.StatusUnauthorized {
+		t.Errorf("Expected unknown token to get 401, got %d", code)
+	}
+
+	tokens, err := authenticator.tokens.GetAPITokens(user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tokens) != 1 || tokens[0].LastUsedAt == nil {
+		t.Errorf("Expected last use to be recorded, got %+v", tokens)
+	}
+
+	if err := authenticator.tokens.RevokeAPIToken(token.ID, user.ID); err != nil {
+		t.Fatal(err)
+	}
+	if code := request(route(ok), "GET", plain); code != http.StatusUnauthorized {
+		t.Errorf("Expected revoked token to get 401, got %d", code)
+	}
+
+	// Expired tokens are refused
+	past := time.Now().Add(-time.Minute)
+	_, expired, err := authenticator.CreateAPIToken(user, "old", []string{ScopePostsRead}, &past)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code := request(route(ok), "GET", expired); code != http.StatusUnauthorized {
+		t.Errorf("Expected expired token to get 401, got %d", code)
+	}
+}
+
+func TestBearerTokenLookupFailure(t *testing.T) {
+	testDB, err := db.Connect(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to connect to test DB: %v", err)
+	}
+	if err := db.Migrate(testDB); err != nil {
+		t.Fatalf("Failed to migrate test DB: %v", err)
+	}
+	authenticator := NewAuthenticator(repository.NewUserRepository(testDB), repository.NewAPITokenRepository(testDB))
+	// A failing lookup is a server error, not an unknown token
+	testDB.Close()
+
+	req := httptest.NewRequest("GET", "/api/posts", nil)
+	req.Header.Set("Authorization", "Bearer pbg_unknown")
+	w := httptest.NewRecorder()
+	authenticator.RequireUser(http.NotFoundHandler()).ServeHTTP(w, req)
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected a failed token lookup to get 500, got %d", w.Code)
+	}
+}
@@ -0,0 +1,31 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/models"
+	"github.com/ariefbayu/personal-blog-generator/internal/repository"
+)
+
+// ErrUsernameRequired is returned when creating a user without a username
+var ErrUsernameRequired = errors.New("username is required")
+
+// CreateInitialUser creates the first admin account. It fails with repository.ErrUserExists once any user exists.
+func CreateInitialUser(users *repository.UserRepository, username, password string) (*models.User, error) {
+	username = strings.TrimSpace(username)
+	if username == "" {
+		return nil, ErrUsernameRequired
+	}
+
+	hash, err := HashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &models.User{Username: username, PasswordHash: hash}
+	if err := users.CreateFirstUser(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
@@ -0,0 +1,677 @@
+// Package backup archives the database, uploaded images and templates, and restores them.
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"modernc.org/sqlite"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/db"
+)
+
+// DefaultKeep is the number of backups kept when BACKUP_KEEP is not set
+const DefaultKeep = 7
+
+// ManifestVersion is the archive layout version written by this package
+const ManifestVersion = 1
+
+// Archive layout
+const (
+	archivePrefix = "backup-"
+	archiveExt    = ".tar.gz"
+	manifestEntry = "manifest.json"
+	databaseEntry = "blog.db"
+	imagesEntry   = "images"
+	templateEntry = "templates"
+)
+
+// ErrBackupNotFound is returned for a backup name that is not in the backup directory
+var ErrBackupNotFound = errors.New("backup not found")
+
+// ErrInvalidArchive is returned when an archive is damaged or was not written by this package
+var ErrInvalidArchive = errors.New("invalid backup archive")
+
+// ErrIncompatible is returned when a backup comes from a newer version with migrations this version does not know
+var ErrIncompatible = errors.New("backup is not compatible with this version")
+
+// Manifest describes the contents of an archive
+type Manifest struct {
+	Version    int       `json:"version"`
+	CreatedAt  time.Time `json:"created_at"`
+	Migrations []string  `json:"migrations"`
+	Images     int       `json:"images"`
+	Templates  int       `json:"templates"`
+}
+
+// Archive is a backup file in the backup directory
+type Archive struct {
+	Name      string    `json:"name"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// RestoreResult describes a completed restore
+type RestoreResult struct {
+	Manifest Manifest `json:"manifest"`
+	// SafetyBackup is the backup of the data that the restore replaced
+	SafetyBackup string `json:"safety_backup"`
+}
+
+// Manager creates, lists and restores backups in BackupPath
+type Manager struct {
+	DB           *sql.DB
+	BackupPath   string
+	OutputPath   string
+	TemplatePath string
+	// Keep is the number of backups kept, older ones are removed after each new backup
+	Keep int
+
+	mu sync.Mutex
+}
+
+// NewManager creates a manager for the database and the images and templates under outputPath and templatePath
+func NewManager(database *sql.DB, backupPath, outputPath, templatePath string) *Manager {
+	return &Manager{
+		DB:           database,
+		BackupPath:   backupPath,
+		OutputPath:   outputPath,
+		TemplatePath: templatePath,
+		Keep:         KeepBackups(),
+	}
+}
+
+// KeepBackups returns the number of backups to keep from BACKUP_KEEP, falling back to DefaultKeep
+func KeepBackups() int {
+	if n, err := strconv.Atoi(os.Getenv("BACKUP_KEEP")); err == nil && n > 0 {
+		return n
+	}
+	return DefaultKeep
+}
+
+// Interval returns the scheduled backup interval from BACKUP_INTERVAL, or zero when scheduled backups are off
+func Interval() time.Duration {
+	interval, err := time.ParseDuration(os.Getenv("BACKUP_INTERVAL"))
+	if err != nil || interval < 0 {
+		return 0
+	}
+	return interval
+}
+
+// Run creates a backup on every tick until the context is cancelled
+func (m *Manager) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			archive, err := m.Create()
+			if err != nil {
+				log.Printf("Scheduled backup failed: %v", err)
+				continue
+			}
+			log.Printf("Created scheduled backup %s", archive.Name)
+		}
+	}
+}
+
+// Create writes a new archive with an online snapshot of the database, the
+// uploaded images and the templates, then removes backups beyond Keep
+func (m *Manager) Create() (*Archive, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.create()
+}
+
+func (m *Manager) create() (*Archive, error) {
+	if err := os.MkdirAll(m.BackupPath, 0755); err != nil {
+		return nil, err
+	}
+
+	workDir, err := os.MkdirTemp(m.BackupPath, ".work-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(workDir)
+
+	dbFile := filepath.Join(workDir, databaseEntry)
+	if err := snapshot(m.DB, dbFile); err != nil {
+		return nil, fmt.Errorf("failed to snapshot database: %w", err)
+	}
+	migrations, err := appliedMigrations(dbFile)
+	if err != nil {
+		return nil, err
+	}
+
+	tmp, err := os.CreateTemp(m.BackupPath, ".partial-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+
+	manifest := Manifest{Version: ManifestVersion, CreatedAt: time.Now().UTC(), Migrations: migrations}
+	if err := m.writeArchive(tmp, dbFile, &manifest); err != nil {
+		tmp.Close()
+		return nil, err
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, err
+	}
+
+	name := m.newName(manifest.CreatedAt)
+	target := filepath.Join(m.BackupPath, name)
+	if err := os.Chmod(tmp.Name(), 0600); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return nil, err
+	}
+	if err := m.prune(); err != nil {
+		log.Printf("Failed to remove old backups: %v", err)
+	}
+
+	info, err := os.Stat(target)
+	if err != nil {
+		return nil, err
+	}
+	return &Archive{Name: name, Size: info.Size(), CreatedAt: info.ModTime()}, nil
+}
+
+func (m *Manager) writeArchive(w io.Writer, dbFile string, manifest *Manifest) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	if err := addFile(tw, dbFile, databaseEntry); err != nil {
+		return err
+	}
+	var err error
+	if manifest.Images, err = addTree(tw, filepath.Join(m.OutputPath, imagesEntry), imagesEntry); err != nil {
+		return fmt.Errorf("failed to archive images: %w", err)
+	}
+	if manifest.Templates, err = addTree(tw, m.TemplatePath, templateEntry); err != nil {
+		return fmt.Errorf("failed to archive templates: %w", err)
+	}
+
+	// The manifest goes last because it records the file counts
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	header := &tar.Header{Name: manifestEntry, Mode: 0644, Size: int64(len(data)), ModTime: manifest.CreatedAt}
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	if _, err := tw.Write(data); err != nil {
+		return err
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// newName returns an unused archive name for the given time
+func (m *Manager) newName(t time.Time) string {
+	base := archivePrefix + t.Local().Format("20060102-150405")
+	name := base + archiveExt
+	for i := 2; fileExists(filepath.Join(m.BackupPath, name)); i++ {
+		name = fmt.Sprintf("%s-%d%s", base, i, archiveExt)
+	}
+	return name
+}
+
+// List returns the backups in the backup directory, newest first
+func (m *Manager) List() ([]Archive, error) {
+	entries, err := os.ReadDir(m.BackupPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var archives []Archive
+	for _, entry := range entries {
+		if entry.IsDir() || !isArchiveName(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		archives = append(archives, Archive{Name: entry.Name(), Size: info.Size(), CreatedAt: info.ModTime()})
+	}
+	sort.Slice(archives, func(i, j int) bool {
+		if !archives[i].CreatedAt.Equal(archives[j].CreatedAt) {
+			return archives[i].CreatedAt.After(archives[j].CreatedAt)
+		}
+		return archives[i].Name > archives[j].Name
+	})
+	return archives, nil
+}
+
+// Path returns the file of a backup in the backup directory
+func (m *Manager) Path(name string) (string, error) {
+	if !isArchiveName(name) || name != filepath.Base(name) {
+		return "", ErrBackupNotFound
+	}
+	file := filepath.Join(m.BackupPath, name)
+	if !fileExists(file) {
+		return "", ErrBackupNotFound
+	}
+	return file, nil
+}
+
+func (m *Manager) prune() error {
+	archives, err := m.List()
+	if err != nil || m.Keep <= 0 || len(archives) <= m.Keep {
+		return err
+	}
+	for _, archive := range archives[m.Keep:] {
+		if err := os.Remove(filepath.Join(m.BackupPath, archive.Name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RestoreFile restores a backup from the backup directory
+func (m *Manager) RestoreFile(name string) (*RestoreResult, error) {
+	file, err := m.Path(name)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return m.Restore(f)
+}
+
+// Restore replaces the database, images and templates with the contents of an archive.
+// The archive is unpacked and checked first: its database must pass an integrity check
+// and must not contain migrations unknown to this version. A backup of the current data
+// is taken before anything is replaced, and migrations newer than the backup are applied
+// after the database is restored.
+func (m *Manager) Restore(r io.Reader) (*RestoreResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := os.MkdirAll(m.BackupPath, 0755); err != nil {
+		return nil, err
+	}
+	workDir, err := os.MkdirTemp(m.BackupPath, ".restore-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(workDir)
+
+	if err := extract(r, workDir); err != nil {
+		return nil, err
+	}
+	manifest, err := Verify(workDir)
+	if err != nil {
+		return nil, err
+	}
+
+	safety, err := m.create()
+	if err != nil {
+		return nil, fmt.Errorf("failed to back up current data before restoring: %w", err)
+	}
+	result := &RestoreResult{Manifest: *manifest, SafetyBackup: safety.Name}
+
+	if err := restoreDatabase(m.DB, filepath.Join(workDir, databaseEntry)); err != nil {
+		return nil, fmt.Errorf("failed to restore database: %w", err)
+	}
+	if err := db.Migrate(m.DB); err != nil {
+		return nil, fmt.Errorf("failed to migrate restored database: %w", err)
+	}
+
+	if err := replaceDir(filepath.Join(workDir, imagesEntry), filepath.Join(m.OutputPath, imagesEntry)); err != nil {
+		return nil, fmt.Errorf("failed to restore images: %w", err)
+	}
+	if err := replaceDir(filepath.Join(workDir, templateEntry), m.TemplatePath); err != nil {
+		return nil, fmt.Errorf("failed to restore templates: %w", err)
+	}
+	return result, nil
+}
+
+// Verify checks an unpacked archive in dir and returns its manifest
+func Verify(dir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, manifestEntry))
+	if err != nil {
+		return nil, fmt.Errorf("%w: missing manifest", ErrInvalidArchive)
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("%w: unreadable manifest: %v", ErrInvalidArchive, err)
+	}
+	if manifest.Version < 1 || manifest.Version > ManifestVersion {
+		return nil, fmt.Errorf("%w: archive version %d is not supported", ErrIncompatible, manifest.Version)
+	}
+
+	dbFile := filepath.Join(dir, databaseEntry)
+	if !fileExists(dbFile) {
+		return nil, fmt.Errorf("%w: missing database", ErrInvalidArchive)
+	}
+	if err := checkIntegrity(dbFile); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+
+	// Trust the database over the manifest
+	migrations, err := appliedMigrations(dbFile)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+	var unknown []string
+	for _, id := range migrations {
+		if _, ok := db.Migrations[id]; !ok {
+			unknown = append(unknown, id)
+		}
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("%w: it was made by a newer version with migrations %s", ErrIncompatible, strings.Join(unknown, ", "))
+	}
+	manifest.Migrations = migrations
+	return &manifest, nil
+}
+
+// sqliteBackup is implemented by the modernc.org/sqlite driver connection
+type sqliteBackup interface {
+	NewBackup(dstURI string) (*sqlite.Backup, error)
+	NewRestore(srcURI string) (*sqlite.Backup, error)
+}
+
+// snapshot copies the live database to file with SQLite's online backup, which is consistent while the server keeps writing
+func snapshot(database *sql.DB, file string) error {
+	return withBackup(database, func(conn sqliteBackup) (*sqlite.Backup, error) {
+		return conn.NewBackup(file)
+	})
+}
+
+// restoreDatabase copies file over the live database. Other connections see the restored data once it completes.
+func restoreDatabase(database *sql.DB, file string) error {
+	return withBackup(database, func(conn sqliteBackup) (*sqlite.Backup, error) {
+		return conn.NewRestore(file)
+	})
+}
+
+func withBackup(database *sql.DB, start func(conn sqliteBackup) (*sqlite.Backup, error)) error {
+	conn, err := database.Conn(context.Background())
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn interface{}) error {
+		c, ok := driverConn.(sqliteBackup)
+		if !ok {
+			return errors.New("database driver does not support online backup")
+		}
+		bk, err := start(c)
+		if err != nil {
+			return err
+		}
+		for {
+			more, err := bk.Step(-1)
+			if err != nil {
+				bk.Finish()
+				return err
+			}
+			if !more {
+				break
+			}
+		}
+		return bk.Finish()
+	})
+}
+
+func appliedMigrations(file string) ([]string, error) {
+	database, err := db.Connect(file)
+	if err != nil {
+		return nil, err
+	}
+	defer database.Close()
+
+	rows, err := database.Query("SELECT id FROM schema_migrations ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func checkIntegrity(file string) error {
+	database, err := db.Connect(file)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	var result string
+	if err := database.QueryRow("PRAGMA quick_check").Scan(&result); err != nil {
+		return fmt.Errorf("database check failed: %w", err)
+	}
+	if result != "ok" {
+		return fmt.Errorf("database is damaged: %s", result)
+	}
+	return nil
+}
+
+// addFile writes a single file into the archive
+func addFile(tw *tar.Writer, file, name string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	header, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return err
+	}
+	header.Name = name
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err = io.Copy(tw, f)
+	return err
+}
+
+// addTree writes the regular files below root into the archive under prefix and returns how many were written.
+// The prefix directory is always written, so a restore knows to empty it even when root is missing.
+func addTree(tw *tar.Writer, root, prefix string) (int, error) {
+	if err := tw.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: prefix + "/", Mode: 0755, ModTime: time.Now()}); err != nil {
+		return 0, err
+	}
+	if _, err := os.Stat(root); errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+
+	count := 0
+	err := filepath.WalkDir(root, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, file)
+		if err != nil || rel == "." {
+			return err
+		}
+		name := path.Join(prefix, filepath.ToSlash(rel))
+		if d.IsDir() {
+			return tw.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: name + "/", Mode: 0755, ModTime: time.Now()})
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		count++
+		return addFile(tw, file, name)
+	})
+	return count, err
+}
+
+// extract unpacks an archive into dir, accepting only the entries this package writes
+func extract(r io.Reader, dir string) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidArchive, err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidArchive, err)
+		}
+
+		name := path.Clean(strings.TrimPrefix(header.Name, "./"))
+		if !allowedEntry(name) {
+			return fmt.Errorf("%w: unexpected entry %q", ErrInvalidArchive, header.Name)
+		}
+		target := filepath.Join(dir, filepath.FromSlash(name))
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(f, tr); err != nil {
+				f.Close()
+				return fmt.Errorf("%w: %w", ErrInvalidArchive, err)
+			}
+			if err := f.Close(); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("%w: unsupported entry %q", ErrInvalidArchive, header.Name)
+		}
+	}
+}
+
+func allowedEntry(name string) bool {
+	if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+		return false
+	}
+	switch name {
+	case manifestEntry, databaseEntry, imagesEntry, templateEntry:
+		return true
+	}
+	return strings.HasPrefix(name, imagesEntry+"/") || strings.HasPrefix(name, templateEntry+"/")
+}
+
+// replaceDir replaces dst with a copy of src. The copy is made next to dst and swapped in
+// with renames, so dst is never left half-written. Nothing happens when src does not exist.
+func replaceDir(src, dst string) error {
+	if _, err := os.Stat(src); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	dst = filepath.Clean(dst)
+	incoming := dst + ".restore-new"
+	outgoing := dst + ".restore-old"
+	os.RemoveAll(incoming)
+	os.RemoveAll(outgoing)
+
+	if err := copyTree(src, incoming); err != nil {
+		os.RemoveAll(incoming)
+		return err
+	}
+
+	if _, err := os.Stat(dst); err == nil {
+		if err := os.Rename(dst, outgoing); err != nil {
+			os.RemoveAll(incoming)
+			return err
+		}
+	}
+	if err := os.Rename(incoming, dst); err != nil {
+		os.Rename(outgoing, dst)
+		return err
+	}
+	return os.RemoveAll(outgoing)
+}
+
+func copyTree(src, dst string) error {
+	return filepath.WalkDir(src, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, file)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+
+		in, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+		out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(out, in); err != nil {
+			out.Close()
+			return err
+		}
+		return out.Close()
+	})
+}
+
+func isArchiveName(name string) bool {
+	return strings.HasPrefix(name, archivePrefix) && strings.HasSuffix(name, archiveExt)
+}
+
+func fileExists(name string) bool {
+	info, err := os.Stat(name)
+	return err == nil && !info.IsDir()
+}
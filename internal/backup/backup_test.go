@@ -0,0 +1,154 @@
+package backup
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/db"
+	"github.com/ariefbayu/personal-blog-generator/internal/models"
+	"github.com/ariefbayu/personal-blog-generator/internal/repository"
+)
+
+func setupManager(t *testing.T) (*Manager, *repository.PostRepository) {
+	t.Helper()
+	dir := t.TempDir()
+	database, err := db.Connect(filepath.Join(dir, "blog.db"))
+	if err != nil {
+		t.Fatalf("Failed to connect to test DB: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+	if err := db.Migrate(database); err != nil {
+		t.Fatalf("Failed to migrate test DB: %v", err)
+	}
+
+	m := NewManager(database, filepath.Join(dir, "backups"), filepath.Join(dir, "html-outputs"), filepath.Join(dir, "templates"))
+	writeFile(t, filepath.Join(m.OutputPath, "images", "a.png"), "image a")
+	writeFile(t, filepath.Join(m.TemplatePath, "post.html"), "original template")
+	return m, repository.NewPostRepository(database)
+}
+
+func writeFile(t *testing.T, name, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestCreateAndRestore(t *testing.T) {
+	m, postRepo := setupManager(t)
+	if err := postRepo.CreatePost(&models.Post{Title: "Kept", Slug: "kept", Content: "Body"}); err != nil {
+		t.Fatal(err)
+	}
+
+	archive, err := m.Create()
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	// Change everything the backup covers
+	post, _ := postRepo.GetPostBySlug("kept")
+	if err := postRepo.DeletePost(post.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := postRepo.CreatePost(&models.Post{Title: "Later", Slug: "later", Content: "Body"}); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(m.OutputPath, "images", "b.png"), "image b")
+	writeFile(t, filepath.Join(m.TemplatePath, "post.html"), "edited template")
+
+	result, err := m.RestoreFile(archive.Name)
+	if err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if len(result.Manifest.Migrations) != len(db.Migrations) || result.Manifest.Images != 1 || result.Manifest.Templates != 1 {
+		t.Errorf("Unexpected manifest: %+v", result.Manifest)
+	}
+
+	if _, err := postRepo.GetPostBySlug("kept"); err != nil {
+		t.Errorf("Expected the backed up post to be restored: %v", err)
+	}
+	if _, err := postRepo.GetPostBySlug("later"); err == nil {
+		t.Error("Expected the newer post to be gone after restore")
+	}
+	if _, err := os.Stat(filepath.Join(m.OutputPath, "images", "b.png")); !os.IsNotExist(err) {
+		t.Error("Expected images to match the backup")
+	}
+	if got := readFile(t, filepath.Join(m.TemplatePath, "post.html")); got != "original template" {
+		t.Errorf("Expected the template to be restored, got %q", got)
+	}
+
+	// The data replaced by the restore was backed up first
+	if _, err := m.Path(result.SafetyBackup); err != nil {
+		t.Fatalf("Safety backup missing: %v", err)
+	}
+	if _, err := m.RestoreFile(result.SafetyBackup); err != nil {
+		t.Fatalf("Restoring the safety backup failed: %v", err)
+	}
+	if _, err := postRepo.GetPostBySlug("later"); err != nil {
+		t.Errorf("Expected the safety backup to bring the newer post back: %v", err)
+	}
+}
+
+func TestRestoreRejectsNewerSchema(t *testing.T) {
+	m, postRepo := setupManager(t)
+	if _, err := m.DB.Exec("INSERT INTO schema_migrations (id) VALUES ('999_from_the_future')"); err != nil {
+		t.Fatal(err)
+	}
+	archive, err := m.Create()
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if _, err := m.DB.Exec("DELETE FROM schema_migrations WHERE id = '999_from_the_future'"); err != nil {
+		t.Fatal(err)
+	}
+	if err := postRepo.CreatePost(&models.Post{Title: "Current", Slug: "current", Content: "Body"}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = m.RestoreFile(archive.Name)
+	if !errors.Is(err, ErrIncompatible) {
+		t.Fatalf("Expected ErrIncompatible, got %v", err)
+	}
+	if _, err := postRepo.GetPostBySlug("current"); err != nil {
+		t.Error("A rejected restore must not touch the database")
+	}
+
+	if _, err := m.Restore(strings.NewReader("not an archive")); !errors.Is(err, ErrInvalidArchive) {
+		t.Errorf("Expected ErrInvalidArchive for garbage input, got %v", err)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	m, _ := setupManager(t)
+	m.Keep = 2
+	for i := 0; i < 4; i++ {
+		if _, err := m.Create(); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+	}
+	archives, err := m.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(archives) != 2 {
+		t.Errorf("Expected 2 backups after pruning, got %d", len(archives))
+	}
+	if _, err := m.Path("../blog.db"); !errors.Is(err, ErrBackupNotFound) {
+		t.Errorf("Expected ErrBackupNotFound for a path outside the backups, got %v", err)
+	}
+}
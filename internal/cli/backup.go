@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+)
+
+func runBackup(args []string, stdio IO) error {
+	fs, apply := NewFlagSet("backup", stdio)
+	dir := fs.String("dir", "", "backup directory (overrides BACKUP_PATH)")
+	list := fs.Bool("list", false, "list backups instead of creating one")
+	if err := parse(fs, apply, args); err != nil {
+		return err
+	}
+	if *dir != "" {
+		os.Setenv("BACKUP_PATH", *dir)
+	}
+
+	app, err := Open()
+	if err != nil {
+		return err
+	}
+	defer app.Close()
+	manager := app.BackupManager()
+
+	if *list {
+		archives, err := manager.List()
+		if err != nil {
+			return err
+		}
+		for _, archive := range archives {
+			fmt.Fprintf(stdio.Stdout, "%s  %s  %s\n", archive.Name, archive.CreatedAt.Format("2006-01-02 15:04"), formatSize(archive.Size))
+		}
+		return nil
+	}
+
+	archive, err := manager.Create()
+	if err != nil {
+		return fmt.Errorf("backup failed: %w", err)
+	}
+	fmt.Fprintf(stdio.Stdout, "Created %s/%s (%s)\n", manager.BackupPath, archive.Name, formatSize(archive.Size))
+	return nil
+}
+
+func runRestore(args []string, stdio IO) error {
+	// Accept the backup before or after the flags
+	var ref string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		ref, args = args[0], args[1:]
+	}
+
+	fs, apply := NewFlagSet("restore", stdio)
+	dir := fs.String("dir", "", "backup directory (overrides BACKUP_PATH)")
+	if err := parse(fs, apply, args); err != nil {
+		return err
+	}
+	if *dir != "" {
+		os.Setenv("BACKUP_PATH", *dir)
+	}
+	if ref == "" {
+		ref = fs.Arg(0)
+	}
+	if ref == "" {
+		fmt.Fprintln(stdio.Stderr, "restore: a backup name or archive file is required")
+		return ErrUsage
+	}
+
+	app, err := Open()
+	if err != nil {
+		return err
+	}
+	defer app.Close()
+	manager := app.BackupManager()
+
+	// A name from "backup --list", or the path of an archive anywhere
+	file, err := manager.Path(ref)
+	if err != nil {
+		file = ref
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	log.SetOutput(io.Discard)
+	result, err := manager.Restore(f)
+	log.SetOutput(os.Stderr)
+	if err != nil {
+		return fmt.Errorf("restore failed: %w", err)
+	}
+
+	fmt.Fprintf(stdio.Stdout, "Restored backup from %s: %d images, %d templates\n", result.Manifest.CreatedAt.Local().Format("2006-01-02 15:04"), result.Manifest.Images, result.Manifest.Templates)
+	fmt.Fprintf(stdio.Stdout, "The previous data was saved as %s\n", result.SafetyBackup)
+	fmt.Fprintln(stdio.Stdout, "Publish to regenerate the site from the restored content.")
+	return nil
+}
+
+func formatSize(size int64) string {
+	switch {
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(size)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", size)
+}
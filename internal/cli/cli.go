@@ -0,0 +1,190 @@
+// Package cli implements the command-line subcommands that work on the database and generator directly,
+// so publishing and content changes can run from cron jobs and git hooks without the HTTP server.
+package cli
+
+import (
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/backup"
+	"github.com/ariefbayu/personal-blog-generator/internal/content"
+	"github.com/ariefbayu/personal-blog-generator/internal/db"
+	"github.com/ariefbayu/personal-blog-generator/internal/repository"
+	"github.com/ariefbayu/personal-blog-generator/internal/utils"
+)
+
+// ErrUsage is returned for invalid command lines. The usage text has already been printed.
+var ErrUsage = errors.New("invalid usage")
+
+const usage = `Usage: personal-blog-generator <command> [flags]
+
+Commands:
+  serve                    Start the admin server (default when no command is given)
+  publish                  Generate the site and swap it live
+  deploy                   Publish and upload the changed files to the deployment target
+  posts list               List posts
+  posts create             Create a post
+  posts edit <id|slug>     Edit a post
+  migrate                  Apply database migrations
+  export                   Export all content as Markdown (--dir, --zip) or JSON (--file)
+  import                   Import content written by export
+  import-wordpress <file>  Import posts and pages from a WordPress export (WXR)
+  import-site <dir>        Import posts and pages from a Hugo or Jekyll site
+  backup                   Back up the database, images and templates (--list to list)
+  restore <backup>         Restore a backup by name or archive file
+
+Every command accepts --db, --templates and --output to override
+DB_PATH, TEMPLATE_PATH and OUTPUT_PATH.
+Run "personal-blog-generator <command> -h" for the flags of a command.
+`
+
+// IO holds the streams commands read from and write to
+type IO struct {
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// StdIO returns the process's standard streams
+func StdIO() IO {
+	return IO{Stdin: os.Stdin, Stdout: os.Stdout, Stderr: os.Stderr}
+}
+
+// App holds the opened database and repositories a command works with
+type App struct {
+	DB            *sql.DB
+	PostRepo      *repository.PostRepository
+	PageRepo      *repository.PageRepository
+	PortfolioRepo *repository.PortfolioRepository
+	SettingsRepo  *repository.SettingsRepository
+}
+
+// Open connects to the database at DB_PATH and migrates it, like the server does on start
+func Open() (*App, error) {
+	database, err := db.Connect(utils.GetDBPath())
+	if err != nil {
+		return nil, err
+	}
+	// Migrate logs every step, which would bury the command's own output
+	log.SetOutput(io.Discard)
+	err = db.Migrate(database)
+	log.SetOutput(os.Stderr)
+	if err != nil {
+		database.Close()
+		return nil, err
+	}
+
+	return &App{
+		DB:            database,
+		PostRepo:      repository.NewPostRepository(database),
+		PageRepo:      repository.NewPageRepository(database),
+		PortfolioRepo: repository.NewPortfolioRepository(database),
+		SettingsRepo:  repository.NewSettingsRepository(database),
+	}, nil
+}
+
+func (a *App) Close() error {
+	return a.DB.Close()
+}
+
+// ContentStore returns the export and import store for the app's repositories
+func (a *App) ContentStore() *content.Store {
+	return content.NewStore(a.PostRepo, a.PageRepo, a.PortfolioRepo)
+}
+
+// BackupManager returns the backup manager for the app's database and the configured paths
+func (a *App) BackupManager() *backup.Manager {
+	return backup.NewManager(a.DB, utils.GetBackupPath(), utils.GetOutputPath(), utils.GetTemplatePath())
+}
+
+// NewFlagSet returns a flag set for a command with the --db, --templates and --output overrides registered.
+// Call the returned function after parsing to apply the overrides.
+func NewFlagSet(name string, stdio IO) (*flag.FlagSet, func()) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stdio.Stderr)
+
+	dbPath := fs.String("db", "", "SQLite database path (overrides DB_PATH)")
+	templatePath := fs.String("templates", "", "template directory (overrides TEMPLATE_PATH)")
+	outputPath := fs.String("output", "", "generated site directory (overrides OUTPUT_PATH)")
+
+	// The overrides go through the environment because handlers and the generator read the paths from there
+	apply := func() {
+		if *dbPath != "" {
+			os.Setenv("DB_PATH", *dbPath)
+		}
+		if *templatePath != "" {
+			os.Setenv("TEMPLATE_PATH", *templatePath)
+		}
+		if *outputPath != "" {
+			os.Setenv("OUTPUT_PATH", *outputPath)
+		}
+	}
+	return fs, apply
+}
+
+// parse parses a command's flags, turning -h and bad flags into ErrUsage
+func parse(fs *flag.FlagSet, apply func(), args []string) error {
+	if err := fs.Parse(args); err != nil {
+		return ErrUsage
+	}
+	apply()
+	return nil
+}
+
+// Run executes a subcommand other than serve. args excludes the program name.
+func Run(args []string, stdio IO) error {
+	if len(args) == 0 {
+		fmt.Fprint(stdio.Stderr, usage)
+		return ErrUsage
+	}
+
+	switch args[0] {
+	case "publish":
+		return runPublish(args[1:], stdio)
+	case "deploy":
+		return runDeploy(args[1:], stdio)
+	case "posts":
+		return runPosts(args[1:], stdio)
+	case "migrate":
+		return runMigrate(args[1:], stdio)
+	case "export":
+		return runExport(args[1:], stdio)
+	case "import":
+		return runImport(args[1:], stdio)
+	case "import-wordpress":
+		return runImportWordPress(args[1:], stdio)
+	case "import-site":
+		return runImportSite(args[1:], stdio)
+	case "backup":
+		return runBackup(args[1:], stdio)
+	case "restore":
+		return runRestore(args[1:], stdio)
+	case "help", "-h", "--help":
+		fmt.Fprint(stdio.Stdout, usage)
+		return nil
+	}
+
+	fmt.Fprintf(stdio.Stderr, "Unknown command %q\n\n%s", args[0], usage)
+	return ErrUsage
+}
+
+func runMigrate(args []string, stdio IO) error {
+	fs, apply := NewFlagSet("migrate", stdio)
+	if err := parse(fs, apply, args); err != nil {
+		return err
+	}
+
+	app, err := Open()
+	if err != nil {
+		return err
+	}
+	defer app.Close()
+
+	fmt.Fprintf(stdio.Stdout, "Database %s is up to date\n", utils.GetDBPath())
+	return nil
+}
@@ -0,0 +1,282 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// runCommand runs a command with the given stdin and returns its stdout
+func runCommand(t *testing.T, stdin string, args ...string) string {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	err := Run(args, IO{Stdin: strings.NewReader(stdin), Stdout: &stdout, Stderr: &stderr})
+	if err != nil {
+		t.Fatalf("%s failed: %v\n%s", strings.Join(args, " "), err, stderr.String())
+	}
+	return stdout.String()
+}
+
+func clearPathEnv(t *testing.T) {
+	for _, name := range []string{"DB_PATH", "TEMPLATE_PATH", "OUTPUT_PATH", "BACKUP_PATH"} {
+		old, ok := os.LookupEnv(name)
+		name := name
+		t.Cleanup(func() {
+			if ok {
+				os.Setenv(name, old)
+			} else {
+				os.Unsetenv(name)
+			}
+		})
+	}
+}
+
+func TestPostsCommands(t *testing.T) {
+	clearPathEnv(t)
+	dbPath := filepath.Join(t.TempDir(), "blog.db")
+
+	out := runCommand(t, "# Hello\n\nFirst post.", "posts", "create", "--db", dbPath, "--title", "Hello World", "--tags", "go, cli", "--content-file", "-", "--published")
+	if !strings.Contains(out, "(hello-world)") {
+		t.Errorf("Expected the slug to be derived from the title, got %q", out)
+	}
+	runCommand(t, "", "posts", "create", "--db", dbPath, "--title", "Draft")
+
+	out = runCommand(t, "", "posts", "list", "--db", dbPath, "--status", "draft")
+	if !strings.Contains(out, "draft") || strings.Contains(out, "hello-world") {
+		t.Errorf("Expected only the draft to be listed, got:\n%s", out)
+	}
+
+	// Flags may follow the post reference
+	runCommand(t, "", "posts", "edit", "hello-world", "--db", dbPath, "--title", "Hello Again", "--publish-at", "2099-01-01 09:00")
+
+	out = runCommand(t, "", "posts", "list", "--db", dbPath, "--json")
+	var posts []struct {
+		Title     string `json:"title"`
+		Slug      string `json:"slug"`
+		Scheduled bool   `json:"scheduled"`
+	}
+	if err := json.Unmarshal([]byte(out), &posts); err != nil {
+		t.Fatalf("Failed to decode JSON: %v", err)
+	}
+	found := false
+	for _, post := range posts {
+		if post.Slug == "hello-world" {
+			found = true
+			if post.Title != "Hello Again" || !post.Scheduled {
+				t.Errorf("Expected edited, scheduled post, got %+v", post)
+			}
+		}
+	}
+	if !found {
+		t.Error("Expected edited post in the list")
+	}
+
+	var stderr bytes.Buffer
+	err := Run([]string{"posts", "edit", "missing", "--db", dbPath, "--title", "x"}, IO{Stdin: strings.NewReader(""), Stdout: &bytes.Buffer{}, Stderr: &stderr})
+	if err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+}
+
+func TestExportImport(t *testing.T) {
+	clearPathEnv(t)
+	dir := t.TempDir()
+	source := filepath.Join(dir, "source.db")
+	target := filepath.Join(dir, "target.db")
+	exportFile := filepath.Join(dir, "export.json")
+
+	runCommand(t, "Body", "posts", "create", "--db", source, "--title", "Exported", "--content-file", "-", "--published")
+	runCommand(t, "", "export", "--db", source, "--file", exportFile)
+
+	out := runCommand(t, "", "import", "--db", target, "--file", exportFile)
+	if !strings.Contains(out, "Posts: 1 created, 0 updated") {
+		t.Errorf("Unexpected first import result:\n%s", out)
+	}
+
+	// Importing again updates by slug instead of duplicating
+	out = runCommand(t, "", "import", "--db", target, "--file", exportFile)
+	if !strings.Contains(out, "Posts: 0 created, 1 updated") {
+		t.Errorf("Unexpected second import result:\n%s", out)
+	}
+}
+
+func TestMarkdownExportImport(t *testing.T) {
+	clearPathEnv(t)
+	dir := t.TempDir()
+	source := filepath.Join(dir, "source.db")
+	exportDir := filepath.Join(dir, "export")
+	exportZip := filepath.Join(dir, "export.zip")
+
+	runCommand(t, "Body", "posts", "create", "--db", source, "--title", "Exported", "--tags", "go", "--content-file", "-")
+	runCommand(t, "", "export", "--db", source, "--dir", exportDir)
+
+	data, err := os.ReadFile(filepath.Join(exportDir, "posts", "exported.md"))
+	if err != nil {
+		t.Fatalf("Expected exported Markdown file: %v", err)
+	}
+	if !strings.Contains(string(data), "published: false") || !strings.HasSuffix(string(data), "---\n\nBody") {
+		t.Errorf("Unexpected Markdown export:\n%s", data)
+	}
+
+	out := runCommand(t, "", "import", "--db", filepath.Join(dir, "from-dir.db"), "--dir", exportDir)
+	if !strings.Contains(out, "Posts: 1 created") {
+		t.Errorf("Unexpected directory import result:\n%s", out)
+	}
+
+	runCommand(t, "", "export", "--db", source, "--zip", exportZip)
+	out = runCommand(t, "", "import", "--db", filepath.Join(dir, "from-zip.db"), "--zip", exportZip)
+	if !strings.Contains(out, "Posts: 1 created") {
+		t.Errorf("Unexpected zip import result:\n%s", out)
+	}
+}
+
+func TestImportWordPressCommand(t *testing.T) {
+	clearPathEnv(t)
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "blog.db")
+	wxr := filepath.Join(dir, "export.xml")
+	err := os.WriteFile(wxr, []byte(`<?xml version="1.0"?>
+<rss xmlns:content="http://purl.org/rss/1.0/modules/content/" xmlns:wp="http://wordpress.org/export/1.2/"><channel><item>
+<title>From WordPress</title>
+<content:encoded><![CDATA[<p>Hello <img src="https://old.example.com/wp-content/uploads/2020/01/a.png"></p>]]></content:encoded>
+<wp:post_id>1</wp:post_id><wp:post_name>from-wordpress</wp:post_name>
+<wp:status>publish</wp:status><wp:post_type>post</wp:post_type>
+</item></channel></rss>`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := runCommand(t, "", "import-wordpress", wxr, "--db", dbPath, "--output", filepath.Join(dir, "out"), "--no-download", "--dry-run")
+	if !strings.Contains(out, "Dry run") || !strings.Contains(out, "Posts: 1 created") {
+		t.Errorf("Unexpected dry run output:\n%s", out)
+	}
+
+	out = runCommand(t, "", "import-wordpress", "--db", dbPath, "--output", filepath.Join(dir, "out"), "--no-download", wxr)
+	if !strings.Contains(out, "Media: 0 downloaded, 0 copied, 0 relinked, 1 failed") {
+		t.Errorf("Expected the image to be reported as not fetched:\n%s", out)
+	}
+
+	out = runCommand(t, "", "import-wordpress", wxr, "--db", dbPath, "--no-download")
+	if !strings.Contains(out, `Conflict: post "from-wordpress"`) {
+		t.Errorf("Expected a slug conflict on the second import:\n%s", out)
+	}
+}
+
+func TestImportSiteCommand(t *testing.T) {
+	clearPathEnv(t)
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "blog.db")
+	site := filepath.Join(dir, "site")
+	if err := os.MkdirAll(filepath.Join(site, "content", "posts"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	post := "+++\ntitle = \"Draft\"\ndraft = true\n+++\nNot yet.\n"
+	if err := os.WriteFile(filepath.Join(site, "content", "posts", "draft.md"), []byte(post), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out := runCommand(t, "", "import-site", site, "--db", dbPath, "--dry-run")
+	if !strings.Contains(out, "Dry run") || !strings.Contains(out, "Posts: 1 created, 0 updated (1 drafts)") {
+		t.Errorf("Unexpected dry run output:\n%s", out)
+	}
+
+	runCommand(t, "", "import-site", site, "--db", dbPath)
+	out = runCommand(t, "", "posts", "list", "--db", dbPath, "--status", "draft")
+	if !strings.Contains(out, "draft") {
+		t.Errorf("Expected the imported draft:\n%s", out)
+	}
+}
+
+func TestBackupAndRestore(t *testing.T) {
+	clearPathEnv(t)
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "blog.db")
+	backups := filepath.Join(dir, "backups")
+	paths := []string{"--db", dbPath, "--templates", filepath.Join(dir, "templates"), "--output", filepath.Join(dir, "out"), "--dir", backups}
+
+	runCommand(t, "", append([]string{"posts", "create", "--title", "Before"}, paths[:2]...)...)
+	out := runCommand(t, "", append([]string{"backup"}, paths...)...)
+	if !strings.HasPrefix(out, "Created "+backups+"/backup-") {
+		t.Fatalf("Unexpected backup output: %q", out)
+	}
+	name := strings.Fields(strings.TrimPrefix(out, "Created "+backups+"/"))[0]
+
+	out = runCommand(t, "", append([]string{"backup", "--list"}, paths...)...)
+	if !strings.HasPrefix(out, name) {
+		t.Errorf("Expected the backup in the list, got %q", out)
+	}
+
+	runCommand(t, "", append([]string{"posts", "create", "--title", "After"}, paths[:2]...)...)
+	out = runCommand(t, "", append([]string{"restore", name}, paths...)...)
+	if !strings.Contains(out, "The previous data was saved as backup-") {
+		t.Errorf("Unexpected restore output: %q", out)
+	}
+
+	out = runCommand(t, "", "posts", "list", "--db", dbPath)
+	if !strings.Contains(out, "before") || strings.Contains(out, "after") {
+		t.Errorf("Expected only the backed up post after restore:\n%s", out)
+	}
+}
+
+func TestPublishCommand(t *testing.T) {
+	clearPathEnv(t)
+	dir := t.TempDir()
+	templatePath, err := filepath.Abs("../../templates")
+	if err != nil {
+		t.Fatal(err)
+	}
+	outputPath := filepath.Join(dir, "html-outputs")
+	dbPath := filepath.Join(dir, "blog.db")
+
+	out := runCommand(t, "", "publish", "--db", dbPath, "--templates", templatePath, "--output", outputPath, "--dry-run")
+	if !strings.HasPrefix(out, "Dry run:") {
+		t.Errorf("Unexpected dry run output: %q", out)
+	}
+	if _, err := os.Stat(outputPath); !os.IsNotExist(err) {
+		t.Error("Dry run should not create the output")
+	}
+
+	runCommand(t, "", "publish", "--db", dbPath, "--templates", templatePath, "--output", outputPath)
+	if _, err := os.Stat(filepath.Join(outputPath, "index.html")); err != nil {
+		t.Error("Expected publish to generate index.html")
+	}
+}
+
+func TestDeployCommand(t *testing.T) {
+	clearPathEnv(t)
+	dir := t.TempDir()
+	templatePath, err := filepath.Abs("../../templates")
+	if err != nil {
+		t.Fatal(err)
+	}
+	outputPath := filepath.Join(dir, "html-outputs")
+	dbPath := filepath.Join(dir, "blog.db")
+	target := filepath.Join(dir, "www")
+	t.Setenv("DEPLOY_TARGET", "")
+	t.Setenv("DEPLOY_HOST", "")
+	t.Setenv("DEPLOY_PATH", target)
+
+	out := runCommand(t, "", "deploy", "--db", dbPath, "--templates", templatePath, "--output", outputPath)
+	if !strings.Contains(out, "Deployed to local:"+target) {
+		t.Errorf("Unexpected deploy output: %q", out)
+	}
+	if _, err := os.Stat(filepath.Join(target, "index.html")); err != nil {
+		t.Error("Expected deploy to upload index.html")
+	}
+
+	out = runCommand(t, "", "deploy", "--db", dbPath, "--templates", templatePath, "--output", outputPath, "--dry-run")
+	if !strings.HasPrefix(out, "Dry run for local:"+target+": 0 to upload, 0 to delete") {
+		t.Errorf("Unexpected dry run output: %q", out)
+	}
+}
+
+func TestUnknownCommand(t *testing.T) {
+	var stderr bytes.Buffer
+	err := Run([]string{"frobnicate"}, IO{Stdout: &bytes.Buffer{}, Stderr: &stderr})
+	if err != ErrUsage || !strings.Contains(stderr.String(), "Unknown command") {
+		t.Errorf("Expected usage error, got %v: %s", err, stderr.String())
+	}
+}
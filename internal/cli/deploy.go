@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/deploy"
+	"github.com/ariefbayu/personal-blog-generator/internal/generator"
+	"github.com/ariefbayu/personal-blog-generator/internal/publish"
+	"github.com/ariefbayu/personal-blog-generator/internal/repository"
+	"github.com/ariefbayu/personal-blog-generator/internal/utils"
+)
+
+func runDeploy(args []string, stdio IO) error {
+	fs, apply := NewFlagSet("deploy", stdio)
+	dryRun := fs.Bool("dry-run", false, "report what would be uploaded and deleted without publishing or changing the target")
+	if err := parse(fs, apply, args); err != nil {
+		return err
+	}
+
+	app, err := Open()
+	if err != nil {
+		return err
+	}
+	defer app.Close()
+
+	if !*dryRun {
+		site := publish.NewSite(app.PostRepo, app.PortfolioRepo, app.PageRepo, app.SettingsRepo, utils.GetTemplatePath(), utils.GetOutputPath())
+		build, err := site.Publish(generator.BuildOptions{})
+		if err != nil {
+			return fmt.Errorf("publish failed: %w", err)
+		}
+		fmt.Fprintf(stdio.Stdout, "Published %s: %d files written, %d unchanged, %d removed\n", utils.GetOutputPath(), build.Written, build.Skipped, build.Deleted)
+		// Like a publish job, a build with pages that failed to render is not deployed
+		for _, failure := range build.Failed {
+			fmt.Fprintf(stdio.Stderr, "  %s\n", failure)
+		}
+		if len(build.Failed) > 0 {
+			return fmt.Errorf("publish failed: %d pages failed to render", len(build.Failed))
+		}
+	}
+
+	result, err := deploy.Run(context.Background(), deploy.ConfigFromEnv(), utils.GetOutputPath(), deploy.Options{DryRun: *dryRun}, repository.NewDeploymentRepository(app.DB))
+	if err != nil {
+		return fmt.Errorf("deploy failed: %w", err)
+	}
+
+	verb, removed := "uploaded", "deleted"
+	if *dryRun {
+		verb, removed = "would upload", "would delete"
+		fmt.Fprintf(stdio.Stdout, "Dry run for %s: %d to upload, %d to delete, %d unchanged\n", result.Target, len(result.Uploaded), len(result.Deleted), result.Unchanged)
+	} else {
+		fmt.Fprintf(stdio.Stdout, "Deployed to %s: %d uploaded, %d deleted, %d unchanged\n", result.Target, len(result.Uploaded), len(result.Deleted), result.Unchanged)
+	}
+	for _, file := range result.Uploaded {
+		fmt.Fprintf(stdio.Stdout, "  %s %s\n", verb, file)
+	}
+	for _, file := range result.Deleted {
+		fmt.Fprintf(stdio.Stdout, "  %s %s\n", removed, file)
+	}
+	return nil
+}
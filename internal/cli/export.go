@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/content"
+)
+
+func runExport(args []string, stdio IO) error {
+	fs, apply := NewFlagSet("export", stdio)
+	dir := fs.String("dir", "", "write posts and pages as Markdown with front matter into this directory")
+	zipFile := fs.String("zip", "", "write the Markdown export as a zip archive")
+	file := fs.String("file", "-", `write a single JSON file instead, or "-" for standard output`)
+	if err := parse(fs, apply, args); err != nil {
+		return err
+	}
+
+	app, err := Open()
+	if err != nil {
+		return err
+	}
+	defer app.Close()
+
+	store := app.ContentStore()
+	switch {
+	case *dir != "":
+		count, err := store.ExportDir(*dir)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(stdio.Stdout, "Exported %d files to %s\n", count, *dir)
+		return nil
+
+	case *zipFile != "":
+		f, err := os.Create(*zipFile)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if err := store.ExportZip(f); err != nil {
+			return err
+		}
+		fmt.Fprintf(stdio.Stdout, "Exported content to %s\n", *zipFile)
+		return nil
+	}
+
+	out := stdio.Stdout
+	if *file != "-" {
+		f, err := os.Create(*file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+
+	if err := store.ExportJSON(out); err != nil {
+		return err
+	}
+	if *file != "-" {
+		fmt.Fprintf(stdio.Stderr, "Exported content to %s\n", *file)
+	}
+	return nil
+}
+
+func runImport(args []string, stdio IO) error {
+	fs, apply := NewFlagSet("import", stdio)
+	dir := fs.String("dir", "", "import a Markdown export directory")
+	zipFile := fs.String("zip", "", "import a Markdown export zip archive")
+	file := fs.String("file", "-", `import a JSON export, or "-" for standard input`)
+	if err := parse(fs, apply, args); err != nil {
+		return err
+	}
+
+	app, err := Open()
+	if err != nil {
+		return err
+	}
+	defer app.Close()
+
+	store := app.ContentStore()
+	var result *content.ImportResult
+	switch {
+	case *dir != "":
+		result, err = store.ImportMarkdown(os.DirFS(*dir))
+
+	case *zipFile != "":
+		var f *os.File
+		f, err = os.Open(*zipFile)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		var info os.FileInfo
+		info, err = f.Stat()
+		if err != nil {
+			return err
+		}
+		result, err = store.ImportZip(f, info.Size())
+
+	default:
+		var in io.Reader = stdio.Stdin
+		if *file != "-" {
+			f, err := os.Open(*file)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			in = f
+		}
+		result, err = store.ImportJSON(in)
+	}
+
+	if result != nil {
+		fmt.Fprint(stdio.Stdout, formatImportResult(result))
+	}
+	return err
+}
+
+func formatImportResult(result *content.ImportResult) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Posts: %d created, %d updated\n", result.PostsCreated, result.PostsUpdated)
+	fmt.Fprintf(&b, "Pages: %d created, %d updated\n", result.PagesCreated, result.PagesUpdated)
+	fmt.Fprintf(&b, "Portfolio: %d created, %d updated\n", result.PortfolioCreated, result.PortfolioUpdated)
+	return b.String()
+}
@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/importer"
+	"github.com/ariefbayu/personal-blog-generator/internal/utils"
+)
+
+func runImportWordPress(args []string, stdio IO) error {
+	// Accept the export file before or after the flags
+	var file string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		file, args = args[0], args[1:]
+	}
+
+	fs, apply := NewFlagSet("import-wordpress", stdio)
+	dryRun := fs.Bool("dry-run", false, "report what would be imported without writing anything")
+	overwrite := fs.Bool("overwrite", false, "update existing posts and pages with the same slug instead of skipping them")
+	uploads := fs.String("uploads", "", "local copy of wp-content/uploads to take images from before downloading")
+	noDownload := fs.Bool("no-download", false, "do not download images; only use --uploads and images already in OUTPUT_PATH/images")
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	if err := parse(fs, apply, args); err != nil {
+		return err
+	}
+	if file == "" {
+		file = fs.Arg(0)
+	}
+	if file == "" {
+		fmt.Fprintln(stdio.Stderr, "import-wordpress: a WordPress export (WXR) file is required")
+		return ErrUsage
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	app, err := Open()
+	if err != nil {
+		return err
+	}
+	defer app.Close()
+
+	opts := importer.WordPressOptions{
+		Options: importer.Options{DryRun: *dryRun, Overwrite: *overwrite},
+		Media: importer.MediaOptions{
+			ImagesDir:  filepath.Join(utils.GetOutputPath(), "images"),
+			UploadsDir: *uploads,
+			Download:   !*noDownload,
+		},
+	}
+	report, err := importer.NewImporter(app.PostRepo, app.PageRepo).ImportWordPress(f, opts)
+	if report != nil {
+		printReport(report, *asJSON, stdio)
+	}
+	return err
+}
+
+func runImportSite(args []string, stdio IO) error {
+	// Accept the site directory before or after the flags
+	var dir string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		dir, args = args[0], args[1:]
+	}
+
+	fs, apply := NewFlagSet("import-site", stdio)
+	dryRun := fs.Bool("dry-run", false, "report what would be imported without writing anything")
+	overwrite := fs.Bool("overwrite", false, "update existing posts and pages with the same slug instead of skipping them")
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	if err := parse(fs, apply, args); err != nil {
+		return err
+	}
+	if dir == "" {
+		dir = fs.Arg(0)
+	}
+	if dir == "" {
+		fmt.Fprintln(stdio.Stderr, "import-site: a Hugo or Jekyll site directory is required")
+		return ErrUsage
+	}
+	if info, err := os.Stat(dir); err != nil {
+		return err
+	} else if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+
+	app, err := Open()
+	if err != nil {
+		return err
+	}
+	defer app.Close()
+
+	opts := importer.Options{DryRun: *dryRun, Overwrite: *overwrite}
+	report, err := importer.NewImporter(app.PostRepo, app.PageRepo).ImportStaticSite(os.DirFS(dir), opts)
+	if report != nil {
+		printReport(report, *asJSON, stdio)
+	}
+	return err
+}
+
+func printReport(report *importer.Report, asJSON bool, stdio IO) {
+	if asJSON {
+		enc := json.NewEncoder(stdio.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(report)
+		return
+	}
+	report.Print(stdio.Stdout)
+}
@@ -0,0 +1,340 @@
+package cli
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/models"
+	"github.com/ariefbayu/personal-blog-generator/internal/utils"
+)
+
+const postsUsage = `Usage:
+  personal-blog-generator posts list [--status all|published|draft|scheduled] [--json]
+  personal-blog-generator posts create --title TITLE [--content-file FILE] [flags]
+  personal-blog-generator posts edit <id|slug> [flags]
+
+posts edit opens $EDITOR on the post's content when no field flags are given.
+`
+
+// publishAtLayouts are the accepted --publish-at formats, read in local time unless they carry a zone
+var publishAtLayouts = []string{time.RFC3339, "2006-01-02 15:04", "2006-01-02T15:04", "2006-01-02"}
+
+func runPosts(args []string, stdio IO) error {
+	if len(args) == 0 {
+		fmt.Fprint(stdio.Stderr, postsUsage)
+		return ErrUsage
+	}
+
+	switch args[0] {
+	case "list":
+		return runPostsList(args[1:], stdio)
+	case "create":
+		return runPostsCreate(args[1:], stdio)
+	case "edit":
+		return runPostsEdit(args[1:], stdio)
+	}
+
+	fmt.Fprintf(stdio.Stderr, "Unknown posts command %q\n\n%s", args[0], postsUsage)
+	return ErrUsage
+}
+
+func runPostsList(args []string, stdio IO) error {
+	fs, apply := NewFlagSet("posts list", stdio)
+	status := fs.String("status", "all", "only list posts with this status: all, published, draft or scheduled")
+	asJSON := fs.Bool("json", false, "print JSON instead of a table")
+	if err := parse(fs, apply, args); err != nil {
+		return err
+	}
+
+	app, err := Open()
+	if err != nil {
+		return err
+	}
+	defer app.Close()
+
+	posts, err := app.PostRepo.GetAllPosts()
+	if err != nil {
+		return err
+	}
+
+	filtered := []models.Post{}
+	for _, post := range posts {
+		if *status == "all" || postStatus(post) == *status {
+			filtered = append(filtered, post)
+		}
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(stdio.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(filtered)
+	}
+
+	tw := tabwriter.NewWriter(stdio.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tSTATUS\tDATE\tSLUG\tTITLE")
+	for _, post := range filtered {
+		date := post.CreatedAt
+		if post.PublishAt != nil {
+			date = *post.PublishAt
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\n", post.ID, postStatus(post), date.Local().Format("2006-01-02 15:04"), post.Slug, post.Title)
+	}
+	return tw.Flush()
+}
+
+func postStatus(post models.Post) string {
+	switch {
+	case post.Scheduled:
+		return "scheduled"
+	case post.Published:
+		return "published"
+	}
+	return "draft"
+}
+
+// postFields are the flags shared by posts create and posts edit
+type postFields struct {
+	title         *string
+	slug          *string
+	tags          *string
+	featuredImage *string
+	contentFile   *string
+	published     *bool
+	publishAt     *string
+}
+
+func registerPostFields(fs *flag.FlagSet) *postFields {
+	return &postFields{
+		title:         fs.String("title", "", "post title"),
+		slug:          fs.String("slug", "", "URL slug (defaults to the slugified title when creating)"),
+		tags:          fs.String("tags", "", "comma-separated tags"),
+		featuredImage: fs.String("featured-image", "", "featured image URL"),
+		contentFile:   fs.String("content-file", "", `Markdown file with the post content, or "-" for standard input`),
+		published:     fs.Bool("published", false, "publish the post"),
+		publishAt:     fs.String("publish-at", "", `schedule publication, e.g. "2025-06-01 09:00"; "none" clears it`),
+	}
+}
+
+// apply copies the flags that were set on the command line onto the post
+func (f *postFields) apply(fs *flag.FlagSet, post *models.Post, stdin io.Reader) error {
+	var err error
+	fs.Visit(func(fl *flag.Flag) {
+		if err != nil {
+			return
+		}
+		switch fl.Name {
+		case "title":
+			post.Title = *f.title
+		case "slug":
+			post.Slug = *f.slug
+		case "tags":
+			post.Tags = *f.tags
+		case "featured-image":
+			post.FeaturedImage = *f.featuredImage
+		case "published":
+			post.Published = *f.published
+		case "content-file":
+			post.Content, err = readContent(*f.contentFile, stdin)
+		case "publish-at":
+			post.PublishAt, err = parsePublishAt(*f.publishAt)
+		}
+	})
+	return err
+}
+
+func readContent(path string, stdin io.Reader) (string, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read content: %w", err)
+	}
+	return string(data), nil
+}
+
+func parsePublishAt(value string) (*time.Time, error) {
+	if value == "" || value == "none" {
+		return nil, nil
+	}
+	for _, layout := range publishAtLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid --publish-at %q, use a format like \"2025-06-01 09:00\"", value)
+}
+
+func runPostsCreate(args []string, stdio IO) error {
+	fs, apply := NewFlagSet("posts create", stdio)
+	fields := registerPostFields(fs)
+	if err := parse(fs, apply, args); err != nil {
+		return err
+	}
+	if strings.TrimSpace(*fields.title) == "" {
+		fmt.Fprintln(stdio.Stderr, "posts create: --title is required")
+		return ErrUsage
+	}
+
+	app, err := Open()
+	if err != nil {
+		return err
+	}
+	defer app.Close()
+
+	post := &models.Post{CreatedAt: time.Now()}
+	if err := fields.apply(fs, post, stdio.Stdin); err != nil {
+		return err
+	}
+	if post.Slug == "" {
+		post.Slug = utils.Slugify(post.Title)
+	}
+
+	if err := app.PostRepo.CreatePost(post); err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return fmt.Errorf("a post with slug %q already exists", post.Slug)
+		}
+		return err
+	}
+
+	fmt.Fprintf(stdio.Stdout, "Created post %d (%s)\n", post.ID, post.Slug)
+	return nil
+}
+
+func runPostsEdit(args []string, stdio IO) error {
+	// Accept the post before or after the flags
+	var ref string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		ref, args = args[0], args[1:]
+	}
+
+	fs, apply := NewFlagSet("posts edit", stdio)
+	fields := registerPostFields(fs)
+	if err := parse(fs, apply, args); err != nil {
+		return err
+	}
+	if ref == "" {
+		ref = fs.Arg(0)
+	}
+	if ref == "" {
+		fmt.Fprintln(stdio.Stderr, "posts edit: a post ID or slug is required")
+		return ErrUsage
+	}
+
+	app, err := Open()
+	if err != nil {
+		return err
+	}
+	defer app.Close()
+
+	post, err := findPost(app, ref)
+	if err != nil {
+		return err
+	}
+
+	if hasPostFieldFlags(fs) {
+		if err := fields.apply(fs, post, stdio.Stdin); err != nil {
+			return err
+		}
+	} else {
+		post.Content, err = editInEditor(post.Content, stdio)
+		if err != nil {
+			return err
+		}
+	}
+
+	post.UpdatedAt = time.Now()
+	if err := app.PostRepo.UpdatePost(post); err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return fmt.Errorf("a post with slug %q already exists", post.Slug)
+		}
+		return err
+	}
+
+	fmt.Fprintf(stdio.Stdout, "Updated post %d (%s)\n", post.ID, post.Slug)
+	return nil
+}
+
+// findPost looks a post up by ID or, failing that, by slug
+func findPost(app *App, ref string) (*models.Post, error) {
+	if id, err := strconv.ParseInt(ref, 10, 64); err == nil {
+		post, err := app.PostRepo.GetPostByID(id)
+		if err == nil {
+			return post, nil
+		}
+		if err != sql.ErrNoRows {
+			return nil, err
+		}
+	}
+
+	post, err := app.PostRepo.GetPostBySlug(ref)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("post %q not found", ref)
+	}
+	return post, err
+}
+
+// hasPostFieldFlags reports whether any post field was given, as opposed to only the path overrides
+func hasPostFieldFlags(fs *flag.FlagSet) bool {
+	found := false
+	fs.Visit(func(fl *flag.Flag) {
+		switch fl.Name {
+		case "db", "templates", "output":
+		default:
+			found = true
+		}
+	})
+	return found
+}
+
+// editInEditor opens $EDITOR (or vi) on the text and returns the saved result
+func editInEditor(text string, stdio IO) (string, error) {
+	f, err := os.CreateTemp("", "post-*.md")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.WriteString(text); err != nil {
+		f.Close()
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = "vi"
+	}
+	// EDITOR may carry arguments, such as "code --wait"
+	parts := strings.Fields(editor)
+	cmd := exec.Command(parts[0], append(parts[1:], f.Name())...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, stdio.Stdout, stdio.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("editor failed: %w", err)
+	}
+
+	data, err := os.ReadFile(f.Name())
+	if err != nil {
+		return "", err
+	}
+	if bytes.Equal(data, []byte(text)) {
+		return "", errors.New("content unchanged, post not updated")
+	}
+	return string(data), nil
+}
@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/generator"
+	"github.com/ariefbayu/personal-blog-generator/internal/publish"
+	"github.com/ariefbayu/personal-blog-generator/internal/utils"
+)
+
+func runPublish(args []string, stdio IO) error {
+	fs, apply := NewFlagSet("publish", stdio)
+	dryRun := fs.Bool("dry-run", false, "report what would change without writing anything")
+	if err := parse(fs, apply, args); err != nil {
+		return err
+	}
+
+	app, err := Open()
+	if err != nil {
+		return err
+	}
+	defer app.Close()
+
+	site := publish.NewSite(app.PostRepo, app.PortfolioRepo, app.PageRepo, app.SettingsRepo, utils.GetTemplatePath(), utils.GetOutputPath())
+	result, err := site.Publish(generator.BuildOptions{DryRun: *dryRun})
+	if err != nil {
+		return fmt.Errorf("publish failed: %w", err)
+	}
+
+	if *dryRun {
+		fmt.Fprintf(stdio.Stdout, "Dry run: %d files would be written, %d unchanged, %d removed\n", result.Written, result.Skipped, result.Deleted)
+	} else {
+		fmt.Fprintf(stdio.Stdout, "Published %s: %d files written, %d unchanged, %d removed\n", utils.GetOutputPath(), result.Written, result.Skipped, result.Deleted)
+	}
+	for _, file := range result.DeletedFiles {
+		fmt.Fprintf(stdio.Stdout, "  removed %s\n", file)
+	}
+	for _, failure := range result.Failed {
+		fmt.Fprintf(stdio.Stderr, "  %s\n", failure)
+	}
+	if len(result.Failed) > 0 {
+		return fmt.Errorf("%d pages failed to render", len(result.Failed))
+	}
+	return nil
+}
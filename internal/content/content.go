@@ -0,0 +1,214 @@
+package content
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/models"
+	"github.com/ariefbayu/personal-blog-generator/internal/repository"
+)
+
+// BundleVersion is written to every export so future formats can tell old exports apart
+const BundleVersion = 1
+
+// Store exports and imports all site content through the repositories
+type Store struct {
+	postRepo      *repository.PostRepository
+	pageRepo      *repository.PageRepository
+	portfolioRepo *repository.PortfolioRepository
+}
+
+func NewStore(postRepo *repository.PostRepository, pageRepo *repository.PageRepository, portfolioRepo *repository.PortfolioRepository) *Store {
+	return &Store{
+		postRepo:      postRepo,
+		pageRepo:      pageRepo,
+		portfolioRepo: portfolioRepo,
+	}
+}
+
+// Bundle holds every post, page and portfolio item of a site
+type Bundle struct {
+	Version    int                    `json:"version"`
+	ExportedAt time.Time              `json:"exported_at"`
+	Posts      []models.Post          `json:"posts"`
+	Pages      []models.Page          `json:"pages"`
+	Portfolio  []models.PortfolioItem `json:"portfolio"`
+}
+
+// ImportResult counts what an import created and updated
+type ImportResult struct {
+	PostsCreated     int `json:"posts_created"`
+	PostsUpdated     int `json:"posts_updated"`
+	PagesCreated     int `json:"pages_created"`
+	PagesUpdated     int `json:"pages_updated"`
+	PortfolioCreated int `json:"portfolio_created"`
+	PortfolioUpdated int `json:"portfolio_updated"`
+}
+
+// Bundle loads all content, posts with their full content
+func (s *Store) Bundle() (*Bundle, error) {
+	summaries, err := s.postRepo.GetAllPosts()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load posts: %w", err)
+	}
+	posts := []models.Post{}
+	for _, summary := range summaries {
+		post, err := s.postRepo.GetPostByID(summary.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load post %s: %w", summary.Slug, err)
+		}
+		posts = append(posts, *post)
+	}
+
+	pages, err := s.pageRepo.GetAllPages()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load pages: %w", err)
+	}
+	if pages == nil {
+		pages = []models.Page{}
+	}
+
+	portfolio, err := s.portfolioRepo.GetAllPortfolioItems()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load portfolio: %w", err)
+	}
+	if portfolio == nil {
+		portfolio = []models.PortfolioItem{}
+	}
+
+	return &Bundle{
+		Version:    BundleVersion,
+		ExportedAt: time.Now().UTC(),
+		Posts:      posts,
+		Pages:      pages,
+		Portfolio:  portfolio,
+	}, nil
+}
+
+// ExportJSON writes all content as a single JSON document
+func (s *Store) ExportJSON(w io.Writer) error {
+	bundle, err := s.Bundle()
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(bundle)
+}
+
+// ImportJSON reads a document written by ExportJSON and upserts its content
+func (s *Store) ImportJSON(r io.Reader) (*ImportResult, error) {
+	var bundle Bundle
+	if err := json.NewDecoder(r).Decode(&bundle); err != nil {
+		return nil, fmt.Errorf("invalid export file: %w", err)
+	}
+	if bundle.Version > BundleVersion {
+		return nil, fmt.Errorf("export version %d is newer than supported version %d", bundle.Version, BundleVersion)
+	}
+	return s.Import(&bundle)
+}
+
+// Import upserts the bundle's content. Posts and pages are matched by slug, portfolio items by title.
+// Existing content that is not in the bundle is left alone.
+func (s *Store) Import(bundle *Bundle) (*ImportResult, error) {
+	result := &ImportResult{}
+
+	for i := range bundle.Posts {
+		created, err := s.UpsertPost(&bundle.Posts[i])
+		if err != nil {
+			return result, fmt.Errorf("failed to import post %s: %w", bundle.Posts[i].Slug, err)
+		}
+		if created {
+			result.PostsCreated++
+		} else {
+			result.PostsUpdated++
+		}
+	}
+
+	for i := range bundle.Pages {
+		created, err := s.UpsertPage(&bundle.Pages[i])
+		if err != nil {
+			return result, fmt.Errorf("failed to import page %s: %w", bundle.Pages[i].Slug, err)
+		}
+		if created {
+			result.PagesCreated++
+		} else {
+			result.PagesUpdated++
+		}
+	}
+
+	for i := range bundle.Portfolio {
+		created, err := s.UpsertPortfolioItem(&bundle.Portfolio[i])
+		if err != nil {
+			return result, fmt.Errorf("failed to import portfolio item %s: %w", bundle.Portfolio[i].Title, err)
+		}
+		if created {
+			result.PortfolioCreated++
+		} else {
+			result.PortfolioUpdated++
+		}
+	}
+
+	return result, nil
+}
+
+// UpsertPost creates the post or updates the existing post with the same slug, reporting whether it was created
+func (s *Store) UpsertPost(post *models.Post) (bool, error) {
+	if post.Slug == "" {
+		return false, fmt.Errorf("post %q has no slug", post.Title)
+	}
+
+	now := time.Now()
+	existing, err := s.postRepo.GetPostBySlug(post.Slug)
+	if err == sql.ErrNoRows {
+		if post.CreatedAt.IsZero() {
+			post.CreatedAt = now
+		}
+		return true, s.postRepo.CreatePost(post)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	post.ID = existing.ID
+	if post.UpdatedAt.IsZero() {
+		post.UpdatedAt = now
+	}
+	return false, s.postRepo.UpdatePost(post)
+}
+
+// UpsertPage creates the page or updates the existing page with the same slug, reporting whether it was created
+func (s *Store) UpsertPage(page *models.Page) (bool, error) {
+	if page.Slug == "" {
+		return false, fmt.Errorf("page %q has no slug", page.Title)
+	}
+
+	existing, err := s.pageRepo.GetPageBySlug(page.Slug)
+	if err == sql.ErrNoRows {
+		return true, s.pageRepo.CreatePage(page)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	page.ID = existing.ID
+	return false, s.pageRepo.UpdatePage(page)
+}
+
+// UpsertPortfolioItem creates the item or updates the existing item with the same title, reporting whether it was created
+func (s *Store) UpsertPortfolioItem(item *models.PortfolioItem) (bool, error) {
+	items, err := s.portfolioRepo.GetAllPortfolioItems()
+	if err != nil {
+		return false, err
+	}
+	for _, existing := range items {
+		if existing.Title == item.Title {
+			item.ID = existing.ID
+			return false, s.portfolioRepo.UpdatePortfolioItem(item)
+		}
+	}
+	return true, s.portfolioRepo.CreatePortfolioItem(item)
+}
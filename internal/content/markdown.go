@@ -0,0 +1,365 @@
+package content
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/models"
+	"github.com/ariefbayu/personal-blog-generator/internal/utils"
+)
+
+// Layout of a Markdown export
+const (
+	PostsDir      = "posts"
+	PagesDir      = "pages"
+	PortfolioFile = "portfolio.yaml"
+)
+
+// frontMatterDelimiter opens and closes the YAML front matter of a Markdown file
+const frontMatterDelimiter = "---"
+
+// PostFrontMatter is the YAML front matter of an exported post
+type PostFrontMatter struct {
+	Title         string     `yaml:"title"`
+	Slug          string     `yaml:"slug"`
+	Tags          []string   `yaml:"tags,omitempty"`
+	FeaturedImage string     `yaml:"featured_image,omitempty"`
+	Published     bool       `yaml:"published"`
+	PublishAt     *time.Time `yaml:"publish_at,omitempty"`
+	CreatedAt     time.Time  `yaml:"created_at,omitempty"`
+	UpdatedAt     time.Time  `yaml:"updated_at,omitempty"`
+}
+
+// PageFrontMatter is the YAML front matter of an exported page
+type PageFrontMatter struct {
+	Title     string    `yaml:"title"`
+	Slug      string    `yaml:"slug"`
+	ShowInNav bool      `yaml:"show_in_nav"`
+	SortOrder int       `yaml:"sort_order"`
+	CreatedAt time.Time `yaml:"created_at,omitempty"`
+	UpdatedAt time.Time `yaml:"updated_at,omitempty"`
+}
+
+// PortfolioEntry is one item of the exported portfolio.yaml
+type PortfolioEntry struct {
+	Title            string `yaml:"title"`
+	ShortDescription string `yaml:"short_description,omitempty"`
+	ProjectURL       string `yaml:"project_url,omitempty"`
+	GithubURL        string `yaml:"github_url,omitempty"`
+	ShowcaseImage    string `yaml:"showcase_image,omitempty"`
+	SortOrder        int    `yaml:"sort_order"`
+}
+
+// MarkdownFiles renders all content as files keyed by slash-separated path:
+// posts/<slug>.md and pages/<slug>.md with YAML front matter, and portfolio.yaml
+func (s *Store) MarkdownFiles() (map[string][]byte, error) {
+	bundle, err := s.Bundle()
+	if err != nil {
+		return nil, err
+	}
+
+	files := map[string][]byte{}
+	for _, post := range bundle.Posts {
+		data, err := PostMarkdown(post)
+		if err != nil {
+			return nil, fmt.Errorf("failed to export post %s: %w", post.Slug, err)
+		}
+		files[path.Join(PostsDir, post.Slug+".md")] = data
+	}
+
+	for _, page := range bundle.Pages {
+		data, err := marshalMarkdown(PageFrontMatter{
+			Title:     page.Title,
+			Slug:      page.Slug,
+			ShowInNav: page.ShowInNav,
+			SortOrder: page.SortOrder,
+			CreatedAt: page.CreatedAt.UTC(),
+			UpdatedAt: page.UpdatedAt.UTC(),
+		}, page.Content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to export page %s: %w", page.Slug, err)
+		}
+		files[path.Join(PagesDir, page.Slug+".md")] = data
+	}
+
+	entries := []PortfolioEntry{}
+	for _, item := range bundle.Portfolio {
+		entries = append(entries, PortfolioEntry{
+			Title:            item.Title,
+			ShortDescription: item.ShortDescription,
+			ProjectURL:       item.ProjectURL,
+			GithubURL:        item.GithubURL,
+			ShowcaseImage:    item.ShowcaseImage,
+			SortOrder:        item.SortOrder,
+		})
+	}
+	data, err := yaml.Marshal(entries)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export portfolio: %w", err)
+	}
+	files[PortfolioFile] = data
+
+	return files, nil
+}
+
+// PostMarkdown renders a post as Markdown with YAML front matter
+func PostMarkdown(post models.Post) ([]byte, error) {
+	var publishAt *time.Time
+	if post.PublishAt != nil {
+		t := post.PublishAt.UTC()
+		publishAt = &t
+	}
+	return marshalMarkdown(PostFrontMatter{
+		Title:         post.Title,
+		Slug:          post.Slug,
+		Tags:          utils.ParseTags(post.Tags),
+		FeaturedImage: post.FeaturedImage,
+		Published:     post.Published,
+		PublishAt:     publishAt,
+		CreatedAt:     post.CreatedAt.UTC(),
+		UpdatedAt:     post.UpdatedAt.UTC(),
+	}, post.Content)
+}
+
+// ExportDir writes a Markdown export into dir, creating it if needed
+func (s *Store) ExportDir(dir string) (int, error) {
+	files, err := s.MarkdownFiles()
+	if err != nil {
+		return 0, err
+	}
+	for name, data := range files {
+		target := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return 0, err
+		}
+		if err := os.WriteFile(target, data, 0644); err != nil {
+			return 0, err
+		}
+	}
+	return len(files), nil
+}
+
+// ExportZip writes a Markdown export as a zip archive
+func (s *Store) ExportZip(w io.Writer) error {
+	files, err := s.MarkdownFiles()
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	zw := zip.NewWriter(w)
+	for _, name := range names {
+		f, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(files[name]); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// ImportMarkdown reads a Markdown export from fsys and upserts its content.
+// Files without a slug in their front matter use the file name as slug.
+func (s *Store) ImportMarkdown(fsys fs.FS) (*ImportResult, error) {
+	bundle, err := ReadMarkdown(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return s.Import(bundle)
+}
+
+// ImportZip reads a Markdown export from a zip archive and upserts its content
+func (s *Store) ImportZip(r io.ReaderAt, size int64) (*ImportResult, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("invalid zip archive: %w", err)
+	}
+	return s.ImportMarkdown(exportRoot(zr))
+}
+
+// ReadMarkdown parses a Markdown export into a bundle without touching the database
+func ReadMarkdown(fsys fs.FS) (*Bundle, error) {
+	bundle := &Bundle{Version: BundleVersion}
+
+	postFiles, err := fs.Glob(fsys, PostsDir+"/*.md")
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range postFiles {
+		var fm PostFrontMatter
+		body, err := readMarkdownFile(fsys, name, &fm)
+		if err != nil {
+			return nil, err
+		}
+		post := models.Post{
+			Title:         fm.Title,
+			Slug:          slugOrFileName(fm.Slug, name),
+			Content:       body,
+			Tags:          strings.Join(fm.Tags, ", "),
+			FeaturedImage: fm.FeaturedImage,
+			Published:     fm.Published,
+			PublishAt:     fm.PublishAt,
+			CreatedAt:     fm.CreatedAt,
+			UpdatedAt:     fm.UpdatedAt,
+		}
+		bundle.Posts = append(bundle.Posts, post)
+	}
+
+	pageFiles, err := fs.Glob(fsys, PagesDir+"/*.md")
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range pageFiles {
+		var fm PageFrontMatter
+		body, err := readMarkdownFile(fsys, name, &fm)
+		if err != nil {
+			return nil, err
+		}
+		bundle.Pages = append(bundle.Pages, models.Page{
+			Title:     fm.Title,
+			Slug:      slugOrFileName(fm.Slug, name),
+			Content:   body,
+			ShowInNav: fm.ShowInNav,
+			SortOrder: fm.SortOrder,
+			CreatedAt: fm.CreatedAt,
+			UpdatedAt: fm.UpdatedAt,
+		})
+	}
+
+	data, err := fs.ReadFile(fsys, PortfolioFile)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	if err == nil {
+		var entries []PortfolioEntry
+		if err := yaml.Unmarshal(data, &entries); err != nil {
+			return nil, fmt.Errorf("%s: %w", PortfolioFile, err)
+		}
+		for _, entry := range entries {
+			if entry.Title == "" {
+				return nil, fmt.Errorf("%s: every item needs a title", PortfolioFile)
+			}
+			bundle.Portfolio = append(bundle.Portfolio, models.PortfolioItem{
+				Title:            entry.Title,
+				ShortDescription: entry.ShortDescription,
+				ProjectURL:       entry.ProjectURL,
+				GithubURL:        entry.GithubURL,
+				ShowcaseImage:    entry.ShowcaseImage,
+				SortOrder:        entry.SortOrder,
+			})
+		}
+	}
+
+	return bundle, nil
+}
+
+// SplitFrontMatter separates YAML front matter from the Markdown body.
+// The body is returned exactly as written, whatever its line endings.
+// Documents without front matter return an empty front matter and the whole text as body.
+func SplitFrontMatter(text string) (string, string) {
+	text = strings.TrimPrefix(text, "\ufeff")
+	lines := strings.SplitAfter(text, "\n")
+	if !isFrontMatterDelimiter(lines[0]) {
+		return "", text
+	}
+
+	for i := 1; i < len(lines); i++ {
+		if isFrontMatterDelimiter(lines[i]) {
+			header := strings.ReplaceAll(strings.Join(lines[1:i], ""), "\r\n", "\n")
+			return header, strings.Join(lines[i+1:], "")
+		}
+	}
+	return "", text
+}
+
+func isFrontMatterDelimiter(line string) bool {
+	return strings.TrimRight(line, "\r\n") == frontMatterDelimiter
+}
+
+func readMarkdownFile(fsys fs.FS, name string, frontMatter interface{}) (string, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return "", err
+	}
+	header, body := SplitFrontMatter(string(data))
+	if err := yaml.Unmarshal([]byte(header), frontMatter); err != nil {
+		return "", fmt.Errorf("%s: invalid front matter: %w", name, err)
+	}
+
+	// Drop the blank line separating the front matter from the body, and nothing else,
+	// so content survives an export and import unchanged
+	if rest, ok := strings.CutPrefix(body, "\n"); ok {
+		return rest, nil
+	}
+	if rest, ok := strings.CutPrefix(body, "\r\n"); ok {
+		return rest, nil
+	}
+	return body, nil
+}
+
+// marshalMarkdown writes the front matter, a blank line and the body as it is
+func marshalMarkdown(frontMatter interface{}, body string) ([]byte, error) {
+	header, err := yaml.Marshal(frontMatter)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(frontMatterDelimiter + "\n")
+	buf.Write(header)
+	buf.WriteString(frontMatterDelimiter + "\n\n")
+	buf.WriteString(body)
+	return buf.Bytes(), nil
+}
+
+func slugOrFileName(slug, name string) string {
+	if slug != "" {
+		return slug
+	}
+	return strings.TrimSuffix(path.Base(name), path.Ext(name))
+}
+
+// exportRoot returns the directory of an archive that holds the export, so archives
+// of a folder (export/posts/...) import the same as archives of its contents (posts/...)
+func exportRoot(fsys fs.FS) fs.FS {
+	if isExportRoot(fsys) {
+		return fsys
+	}
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil || len(entries) != 1 || !entries[0].IsDir() {
+		return fsys
+	}
+	sub, err := fs.Sub(fsys, entries[0].Name())
+	if err != nil || !isExportRoot(sub) {
+		return fsys
+	}
+	return sub
+}
+
+func isExportRoot(fsys fs.FS) bool {
+	for _, name := range []string{PostsDir, PagesDir, PortfolioFile} {
+		if _, err := fs.Stat(fsys, name); err == nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,123 @@
+package content
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/db"
+	"github.com/ariefbayu/personal-blog-generator/internal/models"
+	"github.com/ariefbayu/personal-blog-generator/internal/repository"
+)
+
+func setupStore(t *testing.T) (*Store, *repository.PostRepository) {
+	t.Helper()
+	testDB, err := db.Connect(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to connect to test DB: %v", err)
+	}
+	t.Cleanup(func() { testDB.Close() })
+	if err := db.Migrate(testDB); err != nil {
+		t.Fatalf("Failed to migrate test DB: %v", err)
+	}
+	postRepo := repository.NewPostRepository(testDB)
+	return NewStore(postRepo, repository.NewPageRepository(testDB), repository.NewPortfolioRepository(testDB)), postRepo
+}
+
+func TestSplitFrontMatter(t *testing.T) {
+	header, body := SplitFrontMatter("\ufeff---\r\ntitle: Hello\r\n---\r\n# Body\r\n")
+	if header != "title: Hello\n" || body != "# Body\r\n" {
+		t.Errorf("Unexpected split: %q / %q", header, body)
+	}
+
+	header, body = SplitFrontMatter("# No front matter\n")
+	if header != "" || body != "# No front matter\n" {
+		t.Errorf("Expected body only, got %q / %q", header, body)
+	}
+}
+
+func TestMarkdownRoundTrip(t *testing.T) {
+	source, postRepo := setupStore(t)
+	created := time.Date(2023, 4, 5, 6, 7, 8, 0, time.UTC)
+	err := postRepo.CreatePost(&models.Post{
+		Title:     "Hello World",
+		Slug:      "hello-world",
+		Content:   "# Hello\n\nBody text.\n",
+		Tags:      "go, blog",
+		Published: true,
+		CreatedAt: created,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create post: %v", err)
+	}
+
+	files, err := source.MarkdownFiles()
+	if err != nil {
+		t.Fatalf("MarkdownFiles failed: %v", err)
+	}
+	post := string(files["posts/hello-world.md"])
+	if !strings.HasPrefix(post, "---\ntitle: Hello World\n") || !strings.Contains(post, "- go\n") {
+		t.Errorf("Unexpected post file:\n%s", post)
+	}
+
+	fsys := fstest.MapFS{}
+	for name, data := range files {
+		fsys[name] = &fstest.MapFile{Data: data}
+	}
+	// A file without a slug takes it from the file name
+	fsys["pages/about.md"] = &fstest.MapFile{Data: []byte("---\ntitle: About\nshow_in_nav: true\n---\nAbout me.\n")}
+
+	target, targetPosts := setupStore(t)
+	result, err := target.ImportMarkdown(fsys)
+	if err != nil {
+		t.Fatalf("ImportMarkdown failed: %v", err)
+	}
+	if result.PostsCreated != 1 || result.PagesCreated != 1 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	imported, err := targetPosts.GetPostBySlug("hello-world")
+	if err != nil {
+		t.Fatalf("Imported post not found: %v", err)
+	}
+	if imported.Content != "# Hello\n\nBody text.\n" || imported.Tags != "go,blog" || !imported.Published {
+		t.Errorf("Unexpected imported post: %+v", imported)
+	}
+	if !imported.CreatedAt.Equal(created) {
+		t.Errorf("Expected created_at %v, got %v", created, imported.CreatedAt)
+	}
+
+	result, err = target.ImportMarkdown(fsys)
+	if err != nil {
+		t.Fatalf("Second import failed: %v", err)
+	}
+	if result.PostsUpdated != 1 || result.PagesUpdated != 1 || result.PostsCreated != 0 {
+		t.Errorf("Expected updates by slug, got %+v", result)
+	}
+}
+
+func TestMarkdownRoundTripKeepsContent(t *testing.T) {
+	contents := []string{
+		"",
+		"No trailing newline",
+		"Two trailing newlines\n\n",
+		"\nStarts with a blank line\n",
+		"\n\n",
+		"Windows line endings\r\n\r\nand trailing spaces  \r\n",
+		"---\nA body that looks like front matter\n---\n",
+	}
+	for _, content := range contents {
+		data, err := PostMarkdown(models.Post{Title: "Post", Slug: "post", Content: content})
+		if err != nil {
+			t.Fatal(err)
+		}
+		bundle, err := ReadMarkdown(fstest.MapFS{"posts/post.md": &fstest.MapFile{Data: data}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(bundle.Posts) != 1 || bundle.Posts[0].Content != content {
+			t.Errorf("Expected content %q to survive the round trip, got %+v", content, bundle.Posts)
+		}
+	}
+}
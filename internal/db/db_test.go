@@ -3,6 +3,7 @@ package db
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -40,4 +41,87 @@ func TestConnect(t *testing.T) {
 	if _, err := os.Stat(dbPath); os.IsNotExist(err) {
 		t.Error("Database file was not created")
 	}
-}
\ No newline at end of file
+}
+
+func TestBackfillPostTags(t *testing.T) {
+	db, err := Connect(":memory:")
+	if err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer db.Close()
+
+	if err := Migrate(db); err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+
+	// Simulate posts written before the tags tables existed
+	_, err = db.Exec(`INSERT INTO posts (title, slug, tags) VALUES
+		('One', 'one', 'Go, Web'),
+		('Two', 'two', ' go ,go,web'),
+		('Three', 'three', ''),
+		('Four', 'four', 'C++, C#, 日本語, Café, 🎉')`)
+	if err != nil {
+		t.Fatalf("Insert failed: %v", err)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := backfillPostTags(tx); err != nil {
+		tx.Rollback()
+		t.Fatalf("backfillPostTags failed: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	var tagCount int
+	if err := db.QueryRow("SELECT COUNT(*) FROM tags").Scan(&tagCount); err != nil {
+		t.Fatal(err)
+	}
+	if tagCount != 7 {
+		t.Errorf("Expected 7 tags, got %d", tagCount)
+	}
+
+	var linkCount int
+	if err := db.QueryRow("SELECT COUNT(*) FROM post_tags").Scan(&linkCount); err != nil {
+		t.Fatal(err)
+	}
+	if linkCount != 9 {
+		t.Errorf("Expected 9 post_tags rows, got %d", linkCount)
+	}
+
+	var tags string
+	if err := db.QueryRow("SELECT tags FROM posts WHERE slug = 'two'").Scan(&tags); err != nil {
+		t.Fatal(err)
+	}
+	if tags != "Go,Web" {
+		t.Errorf("Expected canonical tags 'Go,Web', got '%s'", tags)
+	}
+
+	// No tag is dropped, whatever its script or symbols
+	if err := db.QueryRow("SELECT tags FROM posts WHERE slug = 'four'").Scan(&tags); err != nil {
+		t.Fatal(err)
+	}
+	if tags != "C++,C#,日本語,Café,🎉" {
+		t.Errorf("Expected every tag to be kept, got '%s'", tags)
+	}
+
+	var slugs []string
+	rows, err := db.Query("SELECT slug FROM tags WHERE name IN ('C++', 'C#', '日本語', 'Café') ORDER BY id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var slug string
+		if err := rows.Scan(&slug); err != nil {
+			t.Fatal(err)
+		}
+		slugs = append(slugs, slug)
+	}
+	if strings.Join(slugs, " ") != "c-plus-plus c-sharp 日本語 café" {
+		t.Errorf("Unexpected slugs %v", slugs)
+	}
+}
@@ -0,0 +1,47 @@
+package db
+
+import (
+	"database/sql"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/repository"
+)
+
+// MigrationHooks holds Go data migrations keyed by migration ID. A hook runs in the
+// same transaction, right after the migration SQL with the same ID.
+var MigrationHooks = map[string]func(tx *sql.Tx) error{
+	"007_create_tags_tables": backfillPostTags,
+}
+
+// backfillPostTags populates tags and post_tags from the comma-separated posts.tags column.
+// Tags differing only in case or punctuation collapse into one tag named after the first spelling
+// seen, and posts.tags is rewritten with the canonical names.
+func backfillPostTags(tx *sql.Tx) error {
+	rows, err := tx.Query("SELECT id, tags FROM posts WHERE tags IS NOT NULL AND tags != '' ORDER BY id")
+	if err != nil {
+		return err
+	}
+	type postTags struct {
+		id   int64
+		tags string
+	}
+	var posts []postTags
+	for rows.Next() {
+		var p postTags
+		if err := rows.Scan(&p.id, &p.tags); err != nil {
+			rows.Close()
+			return err
+		}
+		posts = append(posts, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, post := range posts {
+		if _, err := repository.SyncPostTags(tx, post.id, post.tags); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -58,6 +58,15 @@ func Migrate(db *sql.DB) error {
 			return fmt.Errorf("failed to execute migration %s: %w", id, err)
 		}
 
+		// Run data backfill for migrations that cannot be expressed in SQL alone
+		if hook, exists := MigrationHooks[id]; exists {
+			log.Printf("Running data hook for migration %s...", id)
+			if err := hook(tx); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("failed to run data hook for migration %s: %w", id, err)
+			}
+		}
+
 		_, err = tx.Exec("INSERT INTO schema_migrations (id) VALUES (?)", id)
 		if err != nil {
 			tx.Rollback()
@@ -68,4 +68,132 @@ CREATE TABLE IF NOT EXISTS pages (
 
 INSERT OR IGNORE INTO settings (id, site_name, show_portfolio_menu, show_posts_menu, menu_order) VALUES (1, 'My Blog', 1, 1, '["posts","portfolio"]');`,
 	"004_add_featured_image_to_posts": `ALTER TABLE posts ADD COLUMN featured_image TEXT DEFAULT '';`,
+	"005_add_base_url_to_settings":    `ALTER TABLE settings ADD COLUMN base_url TEXT DEFAULT '';`,
+	"006_add_robots_txt_to_settings":  `ALTER TABLE settings ADD COLUMN robots_txt TEXT DEFAULT '';`,
+	"007_create_tags_tables": `CREATE TABLE IF NOT EXISTS tags (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    slug TEXT NOT NULL UNIQUE,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS post_tags (
+    post_id INTEGER NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    tag_id INTEGER NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    position INTEGER DEFAULT 0,
+    PRIMARY KEY (post_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_post_tags_tag_id ON post_tags(tag_id);`,
+	"008_add_posts_per_page_to_settings": `ALTER TABLE settings ADD COLUMN posts_per_page INTEGER DEFAULT 10;`,
+	"009_add_publish_at_to_posts":        `ALTER TABLE posts ADD COLUMN publish_at DATETIME DEFAULT NULL;`,
+	"010_create_revisions_tables": `CREATE TABLE IF NOT EXISTS post_revisions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    post_id INTEGER NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    revision INTEGER NOT NULL,
+    title TEXT NOT NULL,
+    slug TEXT NOT NULL,
+    content TEXT NOT NULL,
+    tags TEXT,
+    featured_image TEXT DEFAULT '',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (post_id, revision)
+);
+
+CREATE TABLE IF NOT EXISTS page_revisions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    page_id INTEGER NOT NULL REFERENCES pages(id) ON DELETE CASCADE,
+    revision INTEGER NOT NULL,
+    title TEXT NOT NULL,
+    slug TEXT NOT NULL,
+    content TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (page_id, revision)
+);
+
+INSERT INTO post_revisions (post_id, revision, title, slug, content, tags, featured_image, created_at)
+SELECT id, 1, title, slug, COALESCE(content, ''), tags, featured_image, COALESCE(updated_at, created_at) FROM posts;
+
+INSERT INTO page_revisions (page_id, revision, title, slug, content, created_at)
+SELECT id, 1, title, slug, COALESCE(content, ''), COALESCE(updated_at, created_at) FROM pages;`,
+	"011_create_users_and_sessions": `CREATE TABLE IF NOT EXISTS users (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    username TEXT NOT NULL UNIQUE,
+    password_hash TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS sessions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    token_hash TEXT NOT NULL UNIQUE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    csrf_token TEXT NOT NULL,
+    expires_at DATETIME NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions(user_id);`,
+	"012_create_api_tokens_table": `CREATE TABLE IF NOT EXISTS api_tokens (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,
+    token_prefix TEXT NOT NULL,
+    scopes TEXT NOT NULL DEFAULT '',
+    expires_at DATETIME DEFAULT NULL,
+    last_used_at DATETIME DEFAULT NULL,
+    revoked_at DATETIME DEFAULT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_tokens_user_id ON api_tokens(user_id);`,
+	"013_create_posts_fts": `CREATE VIRTUAL TABLE IF NOT EXISTS posts_fts USING fts5(
+    title,
+    content,
+    tags,
+    content='posts',
+    content_rowid='id',
+    tokenize='unicode61 remove_diacritics 2'
+);
+
+CREATE TRIGGER IF NOT EXISTS posts_fts_insert AFTER INSERT ON posts BEGIN
+    INSERT INTO posts_fts (rowid, title, content, tags) VALUES (new.id, new.title, new.content, new.tags);
+END;
+
+CREATE TRIGGER IF NOT EXISTS posts_fts_delete AFTER DELETE ON posts BEGIN
+    INSERT INTO posts_fts (posts_fts, rowid, title, content, tags) VALUES ('delete', old.id, old.title, old.content, old.tags);
+END;
+
+CREATE TRIGGER IF NOT EXISTS posts_fts_update AFTER UPDATE OF title, content, tags ON posts BEGIN
+    INSERT INTO posts_fts (posts_fts, rowid, title, content, tags) VALUES ('delete', old.id, old.title, old.content, old.tags);
+    INSERT INTO posts_fts (rowid, title, content, tags) VALUES (new.id, new.title, new.content, new.tags);
+END;
+
+INSERT INTO posts_fts (posts_fts) VALUES ('rebuild');`,
+	"014_create_deployments_table": `CREATE TABLE IF NOT EXISTS deployments (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    target TEXT NOT NULL,
+    status TEXT NOT NULL,
+    uploaded INTEGER NOT NULL DEFAULT 0,
+    deleted INTEGER NOT NULL DEFAULT 0,
+    unchanged INTEGER NOT NULL DEFAULT 0,
+    bytes INTEGER NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    started_at DATETIME NOT NULL,
+    finished_at DATETIME NOT NULL
+);`,
+	"015_create_publish_jobs_table": `CREATE TABLE IF NOT EXISTS publish_jobs (
+    id TEXT PRIMARY KEY,
+    source TEXT NOT NULL,
+    dry_run BOOLEAN NOT NULL DEFAULT 0,
+    status TEXT NOT NULL,
+    written INTEGER NOT NULL DEFAULT 0,
+    skipped INTEGER NOT NULL DEFAULT 0,
+    deleted INTEGER NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    started_at DATETIME NOT NULL,
+    finished_at DATETIME NOT NULL
+);`,
 }
@@ -0,0 +1,388 @@
+// Package deploy uploads the generated site to the server that serves it.
+package deploy
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/models"
+	"github.com/ariefbayu/personal-blog-generator/internal/repository"
+	"github.com/ariefbayu/personal-blog-generator/internal/utils"
+)
+
+// Target names accepted in DEPLOY_TARGET
+const (
+	TargetSFTP  = "sftp"
+	TargetS3    = "s3"
+	TargetGit   = "git"
+	TargetLocal = "local"
+)
+
+// ErrNotConfigured is returned when no deployment target is configured
+var ErrNotConfigured = errors.New("deployment is not configured, set DEPLOY_HOST, DEPLOY_S3_BUCKET, DEPLOY_GIT_REMOTE or DEPLOY_PATH")
+
+// ErrInProgress is returned when a deployment is started while another one is running
+var ErrInProgress = errors.New("a deployment is already in progress")
+
+// mu allows one deployment at a time, as two would race on the target's files
+var mu sync.Mutex
+
+// Deployer is a place the generated site can be deployed to.
+// Deploy compares fingerprints to decide which files to upload, so each Deployer
+// chooses how files are fingerprinted and where the fingerprints of deployed files come from.
+type Deployer interface {
+	// Name describes the target in logs and deployment records
+	Name() string
+	// Files returns the fingerprint of each file a previous deployment put on the target,
+	// keyed by slash-separated path. Files outside that set are never deleted.
+	Files(ctx context.Context) (map[string]string, error)
+	// Fingerprint returns the fingerprint Files reports for a file with this content
+	Fingerprint(r io.Reader) (string, error)
+	// Upload writes a file to the target, replacing any existing file
+	Upload(ctx context.Context, path string, r io.Reader, size int64) error
+	// Delete removes a file from the target
+	Delete(ctx context.Context, path string) error
+	// Finish completes a deployment once every upload and delete has succeeded.
+	// files holds the fingerprints of everything now deployed.
+	Finish(ctx context.Context, files map[string]string) error
+	// Close releases the connection to the target
+	Close() error
+}
+
+// Config selects and configures the deployment target
+type Config struct {
+	// Target is TargetSFTP, TargetS3, TargetGit or TargetLocal. When empty it is sftp if Host is set,
+	// s3 if Bucket is set, git if GitRemote is set and local otherwise.
+	Target string
+	Host   string
+	Port   int
+	User   string
+	// Path is the remote directory for sftp, the key prefix for s3, the worktree for git
+	// and the destination directory for local
+	Path string
+	// KeyFile is an SSH private key. When empty the usual keys in ~/.ssh and the SSH agent are tried.
+	KeyFile  string
+	Password string
+	// KnownHosts is the known_hosts file used to verify the server, ~/.ssh/known_hosts by default
+	KnownHosts string
+
+	Bucket string
+	Region string
+	// Endpoint is the URL of an S3-compatible service such as MinIO. When empty AWS is used.
+	Endpoint     string
+	AccessKey    string
+	SecretKey    string
+	SessionToken string
+	// PageCacheControl and AssetCacheControl override the Cache-Control set on S3 objects
+	PageCacheControl  string
+	AssetCacheControl string
+
+	// GitRemote is the repository URL or path the site is pushed to
+	GitRemote string
+	GitBranch string
+	// GitAuthor is the "Name <email>" of commits. When empty git's own configuration is used.
+	GitAuthor string
+}
+
+// ConfigFromEnv reads the deployment configuration from the DEPLOY_* environment variables
+func ConfigFromEnv() Config {
+	cfg := Config{
+		Target:     strings.ToLower(strings.TrimSpace(os.Getenv("DEPLOY_TARGET"))),
+		Host:       os.Getenv("DEPLOY_HOST"),
+		Port:       22,
+		User:       os.Getenv("DEPLOY_USER"),
+		Path:       os.Getenv("DEPLOY_PATH"),
+		KeyFile:    os.Getenv("DEPLOY_KEY"),
+		Password:   os.Getenv("DEPLOY_PASSWORD"),
+		KnownHosts: os.Getenv("DEPLOY_KNOWN_HOSTS"),
+
+		Bucket:            os.Getenv("DEPLOY_S3_BUCKET"),
+		Region:            firstEnv("DEPLOY_S3_REGION", "AWS_REGION"),
+		Endpoint:          os.Getenv("DEPLOY_S3_ENDPOINT"),
+		AccessKey:         firstEnv("DEPLOY_S3_ACCESS_KEY", "AWS_ACCESS_KEY_ID"),
+		SecretKey:         firstEnv("DEPLOY_S3_SECRET_KEY", "AWS_SECRET_ACCESS_KEY"),
+		SessionToken:      os.Getenv("AWS_SESSION_TOKEN"),
+		PageCacheControl:  os.Getenv("DEPLOY_CACHE_CONTROL_PAGES"),
+		AssetCacheControl: os.Getenv("DEPLOY_CACHE_CONTROL_ASSETS"),
+
+		GitRemote: os.Getenv("DEPLOY_GIT_REMOTE"),
+		GitBranch: os.Getenv("DEPLOY_GIT_BRANCH"),
+		GitAuthor: os.Getenv("DEPLOY_GIT_AUTHOR"),
+	}
+	if port, err := strconv.Atoi(os.Getenv("DEPLOY_PORT")); err == nil && port > 0 {
+		cfg.Port = port
+	}
+	// The output path is swapped to a new build on every publish, so git needs a worktree of its own
+	if cfg.target() == TargetGit && cfg.Path == "" {
+		cfg.Path = utils.GetOutputPath() + ".repo"
+	}
+	return cfg
+}
+
+// firstEnv returns the first of the environment variables that is set
+func firstEnv(names ...string) string {
+	for _, name := range names {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// target returns the configured target, inferring it from the other settings when not set
+func (c Config) target() string {
+	if c.Target != "" {
+		return c.Target
+	}
+	if c.Host != "" {
+		return TargetSFTP
+	}
+	if c.Bucket != "" {
+		return TargetS3
+	}
+	if c.GitRemote != "" {
+		return TargetGit
+	}
+	if c.Path != "" {
+		return TargetLocal
+	}
+	return ""
+}
+
+// Configured reports whether a deployment target is configured
+func (c Config) Configured() bool {
+	return c.target() != ""
+}
+
+// New connects to the configured target
+func New(cfg Config) (Deployer, error) {
+	switch cfg.target() {
+	case TargetSFTP:
+		return NewSFTP(cfg)
+	case TargetS3:
+		return NewS3(cfg)
+	case TargetGit:
+		return NewGit(cfg)
+	case TargetLocal:
+		return NewLocal(cfg.Path)
+	case "":
+		return nil, ErrNotConfigured
+	default:
+		return nil, fmt.Errorf("unknown DEPLOY_TARGET %q", cfg.Target)
+	}
+}
+
+// Options controls a deployment
+type Options struct {
+	// DryRun reports what would be uploaded and deleted without changing the target
+	DryRun bool
+}
+
+// Result summarizes a deployment
+type Result struct {
+	Target    string   `json:"target"`
+	DryRun    bool     `json:"dry_run"`
+	Uploaded  []string `json:"uploaded"`
+	Deleted   []string `json:"deleted"`
+	Unchanged int      `json:"unchanged"`
+	// Bytes is the total size of the uploaded files
+	Bytes int64 `json:"bytes"`
+}
+
+// Deploy uploads the files of sourceDir that differ from the target and deletes files
+// that an earlier deployment uploaded but sourceDir no longer has. Hidden files are not deployed.
+// Other files go up before HTML pages, and deletes come last, so pages never link to missing files.
+// On failure the result lists what was done before the error.
+func Deploy(ctx context.Context, d Deployer, sourceDir string, opts Options) (*Result, error) {
+	result := &Result{Target: d.Name(), DryRun: opts.DryRun, Uploaded: []string{}, Deleted: []string{}}
+
+	// The output path is usually a symlink to the live build
+	root, err := filepath.EvalSymlinks(sourceDir)
+	if err != nil {
+		return result, fmt.Errorf("failed to resolve %s: %w", sourceDir, err)
+	}
+
+	local, err := fingerprintTree(d, root)
+	if err != nil {
+		return result, err
+	}
+	remote, err := d.Files(ctx)
+	if err != nil {
+		return result, fmt.Errorf("failed to list deployed files: %w", err)
+	}
+
+	var uploads, deletes []string
+	for path, sum := range local {
+		if remote[path] == sum {
+			result.Unchanged++
+		} else {
+			uploads = append(uploads, path)
+		}
+	}
+	for path := range remote {
+		if _, ok := local[path]; !ok {
+			deletes = append(deletes, path)
+		}
+	}
+	sort.Slice(uploads, func(i, j int) bool {
+		iHTML, jHTML := filepath.Ext(uploads[i]) == ".html", filepath.Ext(uploads[j]) == ".html"
+		if iHTML != jHTML {
+			return jHTML
+		}
+		return uploads[i] < uploads[j]
+	})
+	sort.Strings(deletes)
+
+	for _, path := range uploads {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		size, err := uploadFile(ctx, d, root, path, opts.DryRun)
+		if err != nil {
+			return result, fmt.Errorf("failed to upload %s: %w", path, err)
+		}
+		result.Uploaded = append(result.Uploaded, path)
+		result.Bytes += size
+	}
+	for _, path := range deletes {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		if !opts.DryRun {
+			if err := d.Delete(ctx, path); err != nil {
+				return result, fmt.Errorf("failed to delete %s: %w", path, err)
+			}
+		}
+		result.Deleted = append(result.Deleted, path)
+	}
+
+	if opts.DryRun {
+		return result, nil
+	}
+	if err := d.Finish(ctx, local); err != nil {
+		return result, fmt.Errorf("failed to finish deployment: %w", err)
+	}
+	return result, nil
+}
+
+// Run connects to the configured target, deploys sourceDir and records the deployment.
+// Dry runs are not recorded. Only one deployment runs at a time.
+func Run(ctx context.Context, cfg Config, sourceDir string, opts Options, deployments *repository.DeploymentRepository) (*Result, error) {
+	if !mu.TryLock() {
+		return nil, ErrInProgress
+	}
+	defer mu.Unlock()
+
+	started := time.Now()
+	var result *Result
+	d, err := New(cfg)
+	if err == nil {
+		result, err = Deploy(ctx, d, sourceDir, opts)
+		d.Close()
+	}
+	if errors.Is(err, ErrNotConfigured) || opts.DryRun || deployments == nil {
+		return result, err
+	}
+
+	record := &models.Deployment{
+		Target:     cfg.target(),
+		Status:     models.DeploymentSucceeded,
+		StartedAt:  started,
+		FinishedAt: time.Now(),
+	}
+	if result != nil {
+		record.Target = result.Target
+		record.Uploaded = len(result.Uploaded)
+		record.Deleted = len(result.Deleted)
+		record.Unchanged = result.Unchanged
+		record.Bytes = result.Bytes
+	}
+	if err != nil {
+		record.Status = models.DeploymentFailed
+		record.Error = err.Error()
+	}
+	if recordErr := deployments.CreateDeployment(record); recordErr != nil && err == nil {
+		return result, fmt.Errorf("deployed, but failed to record the deployment: %w", recordErr)
+	}
+	return result, err
+}
+
+// fingerprintTree fingerprints every file below root, skipping hidden files and directories
+func fingerprintTree(d Deployer, root string) (map[string]string, error) {
+	files := make(map[string]string)
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == root {
+			return nil
+		}
+		if strings.HasPrefix(entry.Name(), ".") {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		sum, err := d.Fingerprint(f)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = sum
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", root, err)
+	}
+	return files, nil
+}
+
+// uploadFile uploads one file of the source tree, returning its size
+func uploadFile(ctx context.Context, d Deployer, root, path string, dryRun bool) (int64, error) {
+	f, err := os.Open(filepath.Join(root, filepath.FromSlash(path)))
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	if dryRun {
+		return info.Size(), nil
+	}
+	return info.Size(), d.Upload(ctx, path, f, info.Size())
+}
+
+// sha256Fingerprint is the fingerprint used by targets that record their own manifest
+func sha256Fingerprint(r io.Reader) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
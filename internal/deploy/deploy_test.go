@@ -0,0 +1,152 @@
+package deploy
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/db"
+	"github.com/ariefbayu/personal-blog-generator/internal/models"
+	"github.com/ariefbayu/personal-blog-generator/internal/repository"
+)
+
+// writeTree writes files, keyed by slash-separated path, below dir
+func writeTree(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", path, err)
+	}
+	return string(data)
+}
+
+func TestDeployLocal(t *testing.T) {
+	source := t.TempDir()
+	target := t.TempDir()
+	writeTree(t, source, map[string]string{
+		"index.html":           "home",
+		"old.html":             "old",
+		"css/styles.css":       "body {}",
+		"tags/go.html":         "go",
+		".build-manifest.json": "{}",
+	})
+	// A file the blog never deployed must survive deployments
+	writeTree(t, target, map[string]string{"robots-extra.txt": "keep"})
+
+	d, err := NewLocal(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	result, err := Deploy(ctx, d, source, Options{})
+	if err != nil {
+		t.Fatalf("First deploy failed: %v", err)
+	}
+	if strings.Join(result.Uploaded, ",") != "css/styles.css,index.html,old.html,tags/go.html" {
+		t.Errorf("Expected every file uploaded, assets first, got %v", result.Uploaded)
+	}
+	if _, err := os.Stat(filepath.Join(target, ".build-manifest.json")); !os.IsNotExist(err) {
+		t.Error("Hidden files should not be deployed")
+	}
+
+	// Change one file, remove two, and edit one on the target behind the blog's back
+	writeTree(t, source, map[string]string{"index.html": "home v2"})
+	os.Remove(filepath.Join(source, "old.html"))
+	os.RemoveAll(filepath.Join(source, "tags"))
+	writeTree(t, target, map[string]string{"css/styles.css": "tampered"})
+
+	result, err = Deploy(ctx, d, source, Options{DryRun: true})
+	if err != nil {
+		t.Fatalf("Dry run failed: %v", err)
+	}
+	if strings.Join(result.Uploaded, ",") != "css/styles.css,index.html" || strings.Join(result.Deleted, ",") != "old.html,tags/go.html" {
+		t.Errorf("Unexpected dry run plan: uploaded %v, deleted %v", result.Uploaded, result.Deleted)
+	}
+	if readFile(t, filepath.Join(target, "index.html")) != "home" {
+		t.Error("A dry run must not change the target")
+	}
+
+	result, err = Deploy(ctx, d, source, Options{})
+	if err != nil {
+		t.Fatalf("Second deploy failed: %v", err)
+	}
+	if len(result.Uploaded) != 2 || len(result.Deleted) != 2 || result.Unchanged != 0 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if readFile(t, filepath.Join(target, "index.html")) != "home v2" || readFile(t, filepath.Join(target, "css", "styles.css")) != "body {}" {
+		t.Error("Changed files were not uploaded")
+	}
+	if _, err := os.Stat(filepath.Join(target, "tags")); !os.IsNotExist(err) {
+		t.Error("Deleting the last file of a directory should remove the directory")
+	}
+	if readFile(t, filepath.Join(target, "robots-extra.txt")) != "keep" {
+		t.Error("Files the blog did not deploy must not be deleted")
+	}
+
+	result, err = Deploy(ctx, d, source, Options{})
+	if err != nil {
+		t.Fatalf("Third deploy failed: %v", err)
+	}
+	if len(result.Uploaded) != 0 || len(result.Deleted) != 0 || result.Unchanged != 2 {
+		t.Errorf("Expected nothing to do, got %+v", result)
+	}
+}
+
+func TestRunRecordsDeployments(t *testing.T) {
+	database, err := db.Connect(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	if err := db.Migrate(database); err != nil {
+		t.Fatal(err)
+	}
+	deployments := repository.NewDeploymentRepository(database)
+
+	source := t.TempDir()
+	writeTree(t, source, map[string]string{"index.html": "home"})
+	ctx := context.Background()
+
+	if _, err := Run(ctx, Config{}, source, Options{}, deployments); err != ErrNotConfigured {
+		t.Errorf("Expected ErrNotConfigured, got %v", err)
+	}
+	if _, err := Run(ctx, Config{Path: t.TempDir()}, source, Options{DryRun: true}, deployments); err != nil {
+		t.Fatalf("Dry run failed: %v", err)
+	}
+	if _, err := Run(ctx, Config{Path: t.TempDir()}, source, Options{}, deployments); err != nil {
+		t.Fatalf("Deploy failed: %v", err)
+	}
+	if _, err := Run(ctx, Config{Path: t.TempDir()}, filepath.Join(source, "missing"), Options{}, deployments); err == nil {
+		t.Fatal("Deploying a missing directory should fail")
+	}
+
+	records, err := deployments.GetDeployments(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 recorded deployments, dry runs and unconfigured runs excluded, got %d", len(records))
+	}
+	if records[0].Status != models.DeploymentFailed || records[0].Error == "" {
+		t.Errorf("Expected the newest record to be the failure, got %+v", records[0])
+	}
+	if records[1].Status != models.DeploymentSucceeded || records[1].Uploaded != 1 || !strings.HasPrefix(records[1].Target, "local:") {
+		t.Errorf("Unexpected success record: %+v", records[1])
+	}
+}
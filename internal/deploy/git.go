@@ -0,0 +1,234 @@
+package deploy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"sort"
+	"strings"
+)
+
+// DefaultGitBranch is the branch the site is pushed to when DEPLOY_GIT_BRANCH is not set
+const DefaultGitBranch = "gh-pages"
+
+// listingPages are generated pages that change with every post, left out of commit message summaries
+var listingPages = map[string]bool{"index.html": true, "posts.html": true, "portfolio.html": true, "search/index.html": true}
+
+// Git deploys by committing the site to a branch of a git repository and pushing it,
+// for hosts that serve a repository, such as GitHub Pages.
+// The files are written to a worktree the deployer manages, which is reset to the remote branch
+// before each deployment, so anything committed to the branch by hand is kept.
+type Git struct {
+	name   string
+	dir    string
+	remote string
+	branch string
+	env    []string
+	files  *Local
+	// previous holds the files of the last deployment, to tell added files from updated ones
+	previous map[string]string
+	added    []string
+	updated  []string
+	removed  []string
+}
+
+// NewGit prepares the worktree at cfg.Path for pushing to cfg.GitRemote
+func NewGit(cfg Config) (*Git, error) {
+	if cfg.GitRemote == "" || cfg.Path == "" {
+		return nil, errors.New("DEPLOY_GIT_REMOTE and DEPLOY_PATH are required for git deployment")
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, errors.New("git deployment needs git installed")
+	}
+	files, err := NewLocal(cfg.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	branch := cfg.GitBranch
+	if branch == "" {
+		branch = DefaultGitBranch
+	}
+	// Never wait for a password prompt nobody will answer
+	env := []string{"GIT_TERMINAL_PROMPT=0"}
+	if cfg.GitAuthor != "" {
+		author, err := mail.ParseAddress(cfg.GitAuthor)
+		if err != nil {
+			return nil, fmt.Errorf("invalid DEPLOY_GIT_AUTHOR %q, expected \"Name <email>\"", cfg.GitAuthor)
+		}
+		env = append(env,
+			"GIT_AUTHOR_NAME="+author.Name, "GIT_AUTHOR_EMAIL="+author.Address,
+			"GIT_COMMITTER_NAME="+author.Name, "GIT_COMMITTER_EMAIL="+author.Address)
+	}
+
+	g := &Git{
+		name:   "git:" + redactRemote(cfg.GitRemote) + "#" + branch,
+		dir:    cfg.Path,
+		remote: cfg.GitRemote,
+		branch: branch,
+		env:    env,
+		files:  files,
+	}
+	if _, err := g.git(context.Background(), "init", "-q"); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// redactRemote hides the password or token in an HTTPS remote, as the name is stored and shown
+func redactRemote(remote string) string {
+	if u, err := url.Parse(remote); err == nil && u.User != nil {
+		if _, ok := u.User.Password(); ok {
+			return u.Redacted()
+		}
+		if u.Scheme == "https" || u.Scheme == "http" {
+			u.User = url.User("xxxxx")
+			return u.String()
+		}
+	}
+	return remote
+}
+
+func (g *Git) Name() string {
+	return g.name
+}
+
+// Files resets the worktree to the remote branch, then reports the files of the last deployment
+func (g *Git) Files(ctx context.Context) (map[string]string, error) {
+	heads, err := g.git(ctx, "ls-remote", "--heads", g.remote, "refs/heads/"+g.branch)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := g.git(ctx, "symbolic-ref", "HEAD", "refs/heads/"+g.branch); err != nil {
+		return nil, err
+	}
+	if heads != "" {
+		if _, err := g.git(ctx, "fetch", "-q", g.remote, "refs/heads/"+g.branch); err != nil {
+			return nil, err
+		}
+		if _, err := g.git(ctx, "reset", "-q", "--hard", "FETCH_HEAD"); err != nil {
+			return nil, err
+		}
+	} else if _, err := g.git(ctx, "rev-parse", "-q", "--verify", "HEAD"); err == nil {
+		// The branch is new on the remote, undo whatever a failed deployment left behind
+		if _, err := g.git(ctx, "reset", "-q", "--hard", "HEAD"); err != nil {
+			return nil, err
+		}
+	} else if _, err := g.git(ctx, "read-tree", "--empty"); err != nil {
+		return nil, err
+	}
+	if _, err := g.git(ctx, "clean", "-q", "-f", "-d"); err != nil {
+		return nil, err
+	}
+
+	g.previous, err = g.files.Files(ctx)
+	return g.previous, err
+}
+
+func (g *Git) Fingerprint(r io.Reader) (string, error) {
+	return g.files.Fingerprint(r)
+}
+
+func (g *Git) Upload(ctx context.Context, name string, r io.Reader, size int64) error {
+	if err := g.files.Upload(ctx, name, r, size); err != nil {
+		return err
+	}
+	if _, ok := g.previous[name]; ok {
+		g.updated = append(g.updated, name)
+	} else {
+		g.added = append(g.added, name)
+	}
+	return nil
+}
+
+func (g *Git) Delete(ctx context.Context, name string) error {
+	if err := g.files.Delete(ctx, name); err != nil {
+		return err
+	}
+	g.removed = append(g.removed, name)
+	return nil
+}
+
+// Finish commits the changes with a message naming the changed posts and pages, and pushes the branch.
+// Nothing is committed when nothing changed.
+func (g *Git) Finish(ctx context.Context, files map[string]string) error {
+	if len(g.added)+len(g.updated)+len(g.removed) == 0 {
+		return nil
+	}
+	if err := g.files.Finish(ctx, files); err != nil {
+		return err
+	}
+	if _, err := g.git(ctx, "add", "-A"); err != nil {
+		return err
+	}
+	if _, err := g.git(ctx, "commit", "-q", "-m", g.commitMessage()); err != nil {
+		return err
+	}
+	_, err := g.git(ctx, "push", "-q", g.remote, "HEAD:refs/heads/"+g.branch)
+	return err
+}
+
+func (g *Git) Close() error {
+	return nil
+}
+
+// commitMessage summarizes the deployment, naming posts and pages by slug in the subject
+func (g *Git) commitMessage() string {
+	var changes []string
+	for _, group := range []struct {
+		verb  string
+		paths []string
+	}{{"Add", g.added}, {"Update", g.updated}, {"Remove", g.removed}} {
+		slugs := pageSlugs(group.paths)
+		if len(slugs) == 0 {
+			continue
+		}
+		if len(slugs) > 3 {
+			slugs = append(slugs[:3], fmt.Sprintf("%d more", len(slugs)-3))
+		}
+		changes = append(changes, group.verb+" "+strings.Join(slugs, ", "))
+	}
+
+	subject := "Publish site"
+	if len(changes) > 0 {
+		subject = strings.Join(changes, "; ")
+	}
+	return fmt.Sprintf("%s\n\n%d files added, %d updated, %d removed.\n", subject, len(g.added), len(g.updated), len(g.removed))
+}
+
+// pageSlugs returns the slugs of the post and page files among paths, sorted
+func pageSlugs(paths []string) []string {
+	var slugs []string
+	for _, p := range paths {
+		if strings.Contains(p, "/") || path.Ext(p) != ".html" || listingPages[p] {
+			continue
+		}
+		slugs = append(slugs, strings.TrimSuffix(p, ".html"))
+	}
+	sort.Strings(slugs)
+	return slugs
+}
+
+// git runs a git command in the worktree, returning its trimmed output
+func (g *Git) git(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = g.dir
+	cmd.Env = append(os.Environ(), g.env...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s failed: %s", args[0], msg)
+		}
+		return "", fmt.Errorf("git %s failed: %w", args[0], err)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
@@ -0,0 +1,140 @@
+package deploy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// Local deploys into a directory on this machine, such as a web server's document root
+// or a mounted network share. Like rsync, it compares the content of the files already there.
+type Local struct {
+	dir string
+}
+
+// NewLocal creates a deployer for the given directory, creating it if needed
+func NewLocal(dir string) (*Local, error) {
+	if dir == "" {
+		return nil, errors.New("DEPLOY_PATH is required for local deployment")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	return &Local{dir: dir}, nil
+}
+
+func (l *Local) Name() string {
+	return "local:" + l.dir
+}
+
+// Files hashes the files listed in the manifest of the previous deployment.
+// Files changed or removed since then are reported as such and uploaded again.
+func (l *Local) Files(ctx context.Context) (map[string]string, error) {
+	f, err := os.Open(filepath.Join(l.dir, ManifestFile))
+	if os.IsNotExist(err) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	deployed, err := decodeManifest(f)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]string, len(deployed))
+	for name := range deployed {
+		sum, err := l.hash(name)
+		if os.IsNotExist(err) {
+			// An empty fingerprint never matches, so the file is uploaded again
+			files[name] = ""
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		files[name] = sum
+	}
+	return files, nil
+}
+
+func (l *Local) hash(name string) (string, error) {
+	f, err := os.Open(l.path(name))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	return sha256Fingerprint(f)
+}
+
+func (l *Local) Fingerprint(r io.Reader) (string, error) {
+	return sha256Fingerprint(r)
+}
+
+// Upload writes the file next to its destination and renames it into place
+func (l *Local) Upload(ctx context.Context, name string, r io.Reader, size int64) error {
+	dest := l.path(name)
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(dest), ".tmp-deploy-*")
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), dest); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// Delete removes the file and any directories it leaves empty
+func (l *Local) Delete(ctx context.Context, name string) error {
+	if err := os.Remove(l.path(name)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for dir := path.Dir(name); dir != "."; dir = path.Dir(dir) {
+		// os.Remove fails on non-empty directories, which ends the walk up
+		if err := os.Remove(l.path(dir)); err != nil {
+			break
+		}
+	}
+	return nil
+}
+
+// Finish saves the manifest for the next deployment
+func (l *Local) Finish(ctx context.Context, files map[string]string) error {
+	data, err := encodeManifest(files)
+	if err != nil {
+		return err
+	}
+	return l.Upload(ctx, ManifestFile, bytes.NewReader(data), int64(len(data)))
+}
+
+func (l *Local) Close() error {
+	return nil
+}
+
+func (l *Local) path(name string) string {
+	return filepath.Join(l.dir, filepath.FromSlash(name))
+}
@@ -0,0 +1,42 @@
+package deploy
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+// ManifestFile records the fingerprints of the deployed files at targets without their own change detection
+const ManifestFile = ".deploy-manifest.json"
+
+type manifest struct {
+	DeployedAt time.Time         `json:"deployed_at"`
+	Files      map[string]string `json:"files"`
+}
+
+// decodeManifest reads a manifest, dropping entries whose paths would leave the target directory
+func decodeManifest(r io.Reader) (map[string]string, error) {
+	var m manifest
+	if err := json.NewDecoder(r).Decode(&m); err != nil {
+		return nil, fmt.Errorf("invalid deploy manifest: %w", err)
+	}
+	files := make(map[string]string, len(m.Files))
+	for name, sum := range m.Files {
+		if validPath(name) {
+			files[name] = sum
+		}
+	}
+	return files, nil
+}
+
+func encodeManifest(files map[string]string) ([]byte, error) {
+	return json.MarshalIndent(manifest{DeployedAt: time.Now().UTC(), Files: files}, "", "  ")
+}
+
+// validPath reports whether a slash-separated path stays inside the target directory
+func validPath(name string) bool {
+	return name != "" && !path.IsAbs(name) && path.Clean(name) == name && name != ".." && !strings.HasPrefix(name, "../")
+}
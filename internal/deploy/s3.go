@@ -0,0 +1,386 @@
+package deploy
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Default Cache-Control headers for S3. File names do not change with their content,
+// so nothing is cached for long, and pages, feeds and the search index only briefly.
+const (
+	DefaultPageCacheControl  = "public, max-age=300"
+	DefaultAssetCacheControl = "public, max-age=86400"
+)
+
+// s3RequestTimeout bounds a single request, including uploading the largest file
+const s3RequestTimeout = 5 * time.Minute
+
+// emptySHA256 is the payload hash of requests without a body
+const emptySHA256 = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// contentTypes covers the files a generated site contains, so the result does not depend on
+// the MIME tables installed on the machine. Other extensions fall back to mime.TypeByExtension.
+var contentTypes = map[string]string{
+	".html":  "text/html; charset=utf-8",
+	".htm":   "text/html; charset=utf-8",
+	".css":   "text/css; charset=utf-8",
+	".js":    "text/javascript; charset=utf-8",
+	".json":  "application/json",
+	".xml":   "application/xml",
+	".txt":   "text/plain; charset=utf-8",
+	".svg":   "image/svg+xml",
+	".png":   "image/png",
+	".jpg":   "image/jpeg",
+	".jpeg":  "image/jpeg",
+	".gif":   "image/gif",
+	".webp":  "image/webp",
+	".avif":  "image/avif",
+	".ico":   "image/x-icon",
+	".woff":  "font/woff",
+	".woff2": "font/woff2",
+	".pdf":   "application/pdf",
+}
+
+// pageExts are the files served with the page Cache-Control, as they change whenever content does
+var pageExts = map[string]bool{".html": true, ".htm": true, ".xml": true, ".json": true, ".txt": true, "": true}
+
+// S3 deploys to a bucket on Amazon S3 or a compatible service such as MinIO.
+// The MD5s of the deployed files are kept in a manifest object below the key prefix,
+// so only objects a previous deployment uploaded are ever deleted.
+type S3 struct {
+	name      string
+	endpoint  *url.URL
+	pathStyle bool
+	bucket    string
+	prefix    string
+	region    string
+	// accessKey, secretKey and sessionToken sign requests with AWS Signature Version 4
+	accessKey    string
+	secretKey    string
+	sessionToken string
+	pageCache    string
+	assetCache   string
+	client       *http.Client
+}
+
+// NewS3 creates a deployer for the bucket described by cfg. Path is the key prefix.
+// Requests go to Endpoint with the bucket in the path, or to AWS with the bucket in the host name.
+func NewS3(cfg Config) (*S3, error) {
+	if cfg.Bucket == "" || cfg.AccessKey == "" || cfg.SecretKey == "" {
+		return nil, errors.New("DEPLOY_S3_BUCKET, DEPLOY_S3_ACCESS_KEY and DEPLOY_S3_SECRET_KEY are required for S3 deployment")
+	}
+	region := cfg.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	s := &S3{
+		bucket:       cfg.Bucket,
+		region:       region,
+		accessKey:    cfg.AccessKey,
+		secretKey:    cfg.SecretKey,
+		sessionToken: cfg.SessionToken,
+		pageCache:    cfg.PageCacheControl,
+		assetCache:   cfg.AssetCacheControl,
+		client:       &http.Client{Timeout: s3RequestTimeout},
+	}
+	if s.pageCache == "" {
+		s.pageCache = DefaultPageCacheControl
+	}
+	if s.assetCache == "" {
+		s.assetCache = DefaultAssetCacheControl
+	}
+
+	if cfg.Endpoint == "" {
+		s.endpoint = &url.URL{Scheme: "https", Host: "s3." + region + ".amazonaws.com"}
+	} else {
+		endpoint, err := url.Parse(cfg.Endpoint)
+		if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+			return nil, fmt.Errorf("invalid DEPLOY_S3_ENDPOINT %q, expected a URL such as http://localhost:9000", cfg.Endpoint)
+		}
+		s.endpoint = endpoint
+		s.pathStyle = true
+	}
+
+	if prefix := strings.Trim(cfg.Path, "/"); prefix != "" {
+		s.prefix = prefix + "/"
+	}
+	s.name = "s3://" + path.Join(s.bucket, s.prefix)
+	return s, nil
+}
+
+func (s *S3) Name() string {
+	return s.name
+}
+
+// Files reads the manifest written by the previous deployment. Objects it does not list,
+// including everything in the bucket before the first deployment, are never deleted.
+func (s *S3) Files(ctx context.Context) (map[string]string, error) {
+	resp, err := s.do(ctx, http.MethodGet, s.objectURL(s.prefix+ManifestFile, nil), nil, 0, emptySHA256, nil)
+	var s3Err *S3Error
+	if errors.As(err, &s3Err) && s3Err.StatusCode == http.StatusNotFound {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return decodeManifest(resp.Body)
+}
+
+// Fingerprint returns the MD5 of the content, which is also what S3 reports as the ETag
+func (s *S3) Fingerprint(r io.Reader) (string, error) {
+	h := md5.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Upload puts the object with a Content-Type and Cache-Control for its extension.
+// Seekable content is hashed first, so S3 verifies the upload arrived intact.
+func (s *S3) Upload(ctx context.Context, name string, r io.Reader, size int64) error {
+	header := http.Header{}
+	header.Set("Content-Type", contentType(name))
+	header.Set("Cache-Control", s.cacheControl(name))
+
+	payloadHash := "UNSIGNED-PAYLOAD"
+	if rs, ok := r.(io.ReadSeeker); ok {
+		start, err := rs.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return err
+		}
+		sha, sum := sha256.New(), md5.New()
+		if _, err := io.Copy(io.MultiWriter(sha, sum), rs); err != nil {
+			return err
+		}
+		if _, err := rs.Seek(start, io.SeekStart); err != nil {
+			return err
+		}
+		payloadHash = hex.EncodeToString(sha.Sum(nil))
+		header.Set("Content-MD5", base64.StdEncoding.EncodeToString(sum.Sum(nil)))
+	}
+
+	resp, err := s.do(ctx, http.MethodPut, s.objectURL(s.prefix+name, nil), r, size, payloadHash, header)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (s *S3) Delete(ctx context.Context, name string) error {
+	resp, err := s.do(ctx, http.MethodDelete, s.objectURL(s.prefix+name, nil), nil, 0, emptySHA256, nil)
+	var s3Err *S3Error
+	if errors.As(err, &s3Err) && s3Err.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Finish saves the manifest for the next deployment
+func (s *S3) Finish(ctx context.Context, files map[string]string) error {
+	data, err := encodeManifest(files)
+	if err != nil {
+		return err
+	}
+	return s.Upload(ctx, ManifestFile, bytes.NewReader(data), int64(len(data)))
+}
+
+func (s *S3) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
+
+func (s *S3) cacheControl(name string) string {
+	if pageExts[strings.ToLower(path.Ext(name))] {
+		return s.pageCache
+	}
+	return s.assetCache
+}
+
+// objectURL returns the URL of an object, or of the bucket when key is empty
+func (s *S3) objectURL(key string, query url.Values) *url.URL {
+	u := *s.endpoint
+	p := "/" + key
+	if s.pathStyle {
+		p = "/" + s.bucket + p
+	} else {
+		u.Host = s.bucket + "." + u.Host
+	}
+	u.Path = strings.TrimSuffix(s.endpoint.Path, "/") + p
+	// Send the path exactly as it is signed
+	u.RawPath = awsEscape(u.Path, false)
+	u.RawQuery = canonicalQuery(query)
+	return &u
+}
+
+// do sends a signed request, turning error responses into an *S3Error
+func (s *S3) do(ctx context.Context, method string, u *url.URL, body io.Reader, size int64, payloadHash string, header http.Header) (*http.Response, error) {
+	if body == nil || size == 0 {
+		// A zero length with a body would be sent chunked, which S3 does not accept
+		body = http.NoBody
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = size
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	if s.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.sessionToken)
+	}
+	signV4(req, s.accessKey, s.secretKey, s.region, payloadHash, time.Now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	s3Err := &S3Error{StatusCode: resp.StatusCode}
+	// HEAD responses and some proxies have no XML body, the status code is still reported
+	xml.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(s3Err)
+	return nil, s3Err
+}
+
+// S3Error is an error response from the object store
+type S3Error struct {
+	StatusCode int
+	Code       string
+	Message    string
+}
+
+func (e *S3Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("S3 request failed with status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("S3 request failed with status %d: %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+// signV4 signs the request with AWS Signature Version 4, covering the host and every header already set
+func signV4(req *http.Request, accessKey, secretKey, region, payloadHash string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	values := map[string]string{"host": host}
+	for name, vals := range req.Header {
+		trimmed := make([]string, len(vals))
+		for i, v := range vals {
+			trimmed[i] = strings.Join(strings.Fields(v), " ")
+		}
+		values[strings.ToLower(name)] = strings.Join(trimmed, ",")
+	}
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + values[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	uri := req.URL.EscapedPath()
+	if uri == "" {
+		uri = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		uri,
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := amzDate[:8] + "/" + region + "/s3/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), amzDate[:8])
+	for _, part := range []string{region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKey, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// canonicalQuery encodes the query sorted by name, as Signature Version 4 requires
+func canonicalQuery(query url.Values) string {
+	pairs := make([]string, 0, len(query))
+	for name, vals := range query {
+		for _, v := range vals {
+			pairs = append(pairs, awsEscape(name, true)+"="+awsEscape(v, true))
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+// awsEscape percent-encodes everything but unreserved characters, and slashes unless escapeSlash is set
+func awsEscape(s string, escapeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !escapeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// contentType returns the Content-Type for a file name
+func contentType(name string) string {
+	ext := strings.ToLower(path.Ext(name))
+	if t, ok := contentTypes[ext]; ok {
+		return t
+	}
+	if t := mime.TypeByExtension(ext); t != "" {
+		return t
+	}
+	return "application/octet-stream"
+}
@@ -0,0 +1,246 @@
+package deploy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// dialTimeout bounds connecting to the SSH server and the SSH handshake
+const dialTimeout = 30 * time.Second
+
+// defaultKeyFiles are the private keys tried, in ~/.ssh, when DEPLOY_KEY is not set
+var defaultKeyFiles = []string{"id_ed25519", "id_ecdsa", "id_rsa"}
+
+// SFTP deploys to a directory on a server over SSH.
+// The server's host key must be in the known_hosts file, and the fingerprints of the deployed
+// files are kept in a manifest in the remote directory, so nothing is downloaded to compare.
+type SFTP struct {
+	name   string
+	root   string
+	conn   *ssh.Client
+	client *sftp.Client
+	// agent is the connection to the SSH agent, if one was used
+	agent io.Closer
+}
+
+// NewSFTP connects to the server described by cfg
+func NewSFTP(cfg Config) (*SFTP, error) {
+	if cfg.Host == "" || cfg.User == "" || cfg.Path == "" {
+		return nil, errors.New("DEPLOY_HOST, DEPLOY_USER and DEPLOY_PATH are required for SFTP deployment")
+	}
+
+	hostKeyCallback, err := knownHostsCallback(cfg.KnownHosts)
+	if err != nil {
+		return nil, err
+	}
+	auth, agentConn, err := authMethods(cfg)
+	if err != nil {
+		return nil, err
+	}
+	closeAgent := func() {
+		if agentConn != nil {
+			agentConn.Close()
+		}
+	}
+
+	port := cfg.Port
+	if port == 0 {
+		port = 22
+	}
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(port))
+	conn, err := ssh.Dial("tcp", addr, &ssh.ClientConfig{
+		User:            cfg.User,
+		Auth:            auth,
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         dialTimeout,
+	})
+	if err != nil {
+		closeAgent()
+		return nil, fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+	client, err := sftp.NewClient(conn)
+	if err != nil {
+		conn.Close()
+		closeAgent()
+		return nil, fmt.Errorf("failed to start SFTP on %s: %w", addr, err)
+	}
+
+	return &SFTP{
+		name:   fmt.Sprintf("sftp://%s@%s%s", cfg.User, addr, path.Clean("/"+cfg.Path)),
+		root:   cfg.Path,
+		conn:   conn,
+		client: client,
+		agent:  agentConn,
+	}, nil
+}
+
+// knownHostsCallback verifies host keys against the known_hosts file
+func knownHostsCallback(file string) (ssh.HostKeyCallback, error) {
+	if file == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to find known_hosts, set DEPLOY_KNOWN_HOSTS: %w", err)
+		}
+		file = filepath.Join(home, ".ssh", "known_hosts")
+	}
+	callback, err := knownhosts.New(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read known hosts from %s, add the server with ssh-keyscan: %w", file, err)
+	}
+	return callback, nil
+}
+
+// authMethods collects the configured key, or the default keys and the SSH agent, and the password.
+// The returned connection to the agent, if any, must be closed once the SSH connection is done.
+func authMethods(cfg Config) ([]ssh.AuthMethod, net.Conn, error) {
+	var signers []ssh.Signer
+	if cfg.KeyFile != "" {
+		signer, err := readKey(cfg.KeyFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read DEPLOY_KEY %s: %w", cfg.KeyFile, err)
+		}
+		signers = append(signers, signer)
+	} else if home, err := os.UserHomeDir(); err == nil {
+		for _, name := range defaultKeyFiles {
+			// Missing keys and keys protected by a passphrase are skipped, the agent may hold them
+			if signer, err := readKey(filepath.Join(home, ".ssh", name)); err == nil {
+				signers = append(signers, signer)
+			}
+		}
+	}
+
+	var methods []ssh.AuthMethod
+	if len(signers) > 0 {
+		methods = append(methods, ssh.PublicKeys(signers...))
+	}
+	var agentConn net.Conn
+	if socket := os.Getenv("SSH_AUTH_SOCK"); socket != "" && cfg.KeyFile == "" {
+		if conn, err := net.Dial("unix", socket); err == nil {
+			agentConn = conn
+			methods = append(methods, ssh.PublicKeysCallback(agent.NewClient(conn).Signers))
+		}
+	}
+	if cfg.Password != "" {
+		methods = append(methods, ssh.Password(cfg.Password))
+	}
+	if len(methods) == 0 {
+		return nil, nil, errors.New("no SSH key, SSH agent or DEPLOY_PASSWORD available")
+	}
+	return methods, agentConn, nil
+}
+
+func readKey(file string) (ssh.Signer, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return ssh.ParsePrivateKey(data)
+}
+
+func (s *SFTP) Name() string {
+	return s.name
+}
+
+// Files reads the manifest written by the previous deployment
+func (s *SFTP) Files(ctx context.Context) (map[string]string, error) {
+	f, err := s.client.Open(s.path(ManifestFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return decodeManifest(f)
+}
+
+func (s *SFTP) Fingerprint(r io.Reader) (string, error) {
+	return sha256Fingerprint(r)
+}
+
+// Upload writes the file next to its destination and renames it into place,
+// so the web server never serves a partially uploaded file
+func (s *SFTP) Upload(ctx context.Context, name string, r io.Reader, size int64) error {
+	dest := s.path(name)
+	if err := s.client.MkdirAll(path.Dir(dest)); err != nil {
+		return err
+	}
+
+	tmp := path.Join(path.Dir(dest), fmt.Sprintf(".tmp-deploy-%d-%s", time.Now().UnixNano(), path.Base(dest)))
+	f, err := s.client.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if _, err := f.ReadFrom(r); err != nil {
+		f.Close()
+		s.client.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		s.client.Remove(tmp)
+		return err
+	}
+	if err := s.client.Chmod(tmp, 0644); err != nil {
+		s.client.Remove(tmp)
+		return err
+	}
+
+	if err := s.client.PosixRename(tmp, dest); err != nil {
+		// Servers without the posix-rename extension cannot rename over an existing file
+		s.client.Remove(dest)
+		if err := s.client.Rename(tmp, dest); err != nil {
+			s.client.Remove(tmp)
+			return err
+		}
+	}
+	return nil
+}
+
+// Delete removes the file and any directories it leaves empty
+func (s *SFTP) Delete(ctx context.Context, name string) error {
+	if err := s.client.Remove(s.path(name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	for dir := path.Dir(name); dir != "."; dir = path.Dir(dir) {
+		// Removing a non-empty directory fails, which ends the walk up
+		if err := s.client.RemoveDirectory(s.path(dir)); err != nil {
+			break
+		}
+	}
+	return nil
+}
+
+// Finish saves the manifest for the next deployment
+func (s *SFTP) Finish(ctx context.Context, files map[string]string) error {
+	data, err := encodeManifest(files)
+	if err != nil {
+		return err
+	}
+	return s.Upload(ctx, ManifestFile, bytes.NewReader(data), int64(len(data)))
+}
+
+func (s *SFTP) Close() error {
+	s.client.Close()
+	if s.agent != nil {
+		s.agent.Close()
+	}
+	return s.conn.Close()
+}
+
+func (s *SFTP) path(name string) string {
+	return path.Join(s.root, name)
+}
@@ -0,0 +1,199 @@
+package deploy
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// sshServer is an in-process SSH server with the SFTP subsystem, standing in for a real host
+type sshServer struct {
+	host       string
+	port       int
+	clientKey  string
+	knownHosts string
+}
+
+// startSSHServer starts a server that accepts only the client key it generates
+func startSSHServer(t *testing.T) *sshServer {
+	t.Helper()
+	dir := t.TempDir()
+
+	_, hostPriv, _ := ed25519.GenerateKey(rand.Reader)
+	hostSigner, err := ssh.NewSignerFromKey(hostPriv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientPub, clientPriv, _ := ed25519.GenerateKey(rand.Reader)
+	authorized, err := ssh.NewPublicKey(clientPub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := ssh.MarshalPrivateKey(clientPriv, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientKey := filepath.Join(dir, "id_ed25519")
+	if err := os.WriteFile(clientKey, pem.EncodeToMemory(block), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if conn.User() == "deploy" && string(key.Marshal()) == string(authorized.Marshal()) {
+				return nil, nil
+			}
+			return nil, os.ErrPermission
+		},
+	}
+	config.AddHostKey(hostSigner)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveSSH(conn, config)
+		}
+	}()
+
+	addr := listener.Addr().(*net.TCPAddr)
+	knownHosts := filepath.Join(dir, "known_hosts")
+	line := knownhosts.Line([]string{knownhosts.Normalize(addr.String())}, hostSigner.PublicKey())
+	if err := os.WriteFile(knownHosts, []byte(line+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	return &sshServer{
+		host:       addr.IP.String(),
+		port:       addr.Port,
+		clientKey:  clientKey,
+		knownHosts: knownHosts,
+	}
+}
+
+func serveSSH(conn net.Conn, config *ssh.ServerConfig) {
+	_, channels, requests, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		conn.Close()
+		return
+	}
+	go ssh.DiscardRequests(requests)
+	for newChannel := range channels {
+		if newChannel.ChannelType() != "session" {
+			newChannel.Reject(ssh.UnknownChannelType, "unsupported channel type")
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			continue
+		}
+		go func() {
+			for req := range requests {
+				// The payload of a subsystem request is the length-prefixed subsystem name
+				ok := req.Type == "subsystem" && len(req.Payload) > 4 && string(req.Payload[4:]) == "sftp"
+				req.Reply(ok, nil)
+				if ok {
+					server, err := sftp.NewServer(channel)
+					if err == nil {
+						server.Serve()
+					}
+					channel.Close()
+				}
+			}
+		}()
+	}
+}
+
+func (s *sshServer) config(path string) Config {
+	return Config{Target: TargetSFTP, Host: s.host, Port: s.port, User: "deploy", Path: path, KeyFile: s.clientKey, KnownHosts: s.knownHosts}
+}
+
+func TestDeploySFTP(t *testing.T) {
+	t.Setenv("SSH_AUTH_SOCK", "")
+	server := startSSHServer(t)
+	source := t.TempDir()
+	remote := filepath.Join(t.TempDir(), "www")
+	writeTree(t, source, map[string]string{
+		"index.html":     "home",
+		"old.html":       "old",
+		"css/styles.css": "body {}",
+	})
+
+	ctx := context.Background()
+	d, err := New(server.config(remote))
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	wantName := "sftp://deploy@" + net.JoinHostPort(server.host, strconv.Itoa(server.port)) + remote
+	if d.Name() != wantName {
+		t.Errorf("Expected name %s, got %s", wantName, d.Name())
+	}
+	result, err := Deploy(ctx, d, source, Options{})
+	d.Close()
+	if err != nil {
+		t.Fatalf("First deploy failed: %v", err)
+	}
+	if len(result.Uploaded) != 3 || readFile(t, filepath.Join(remote, "css", "styles.css")) != "body {}" {
+		t.Errorf("Expected every file uploaded, got %+v", result)
+	}
+
+	writeTree(t, source, map[string]string{"index.html": "home v2"})
+	os.Remove(filepath.Join(source, "old.html"))
+
+	d, err = New(server.config(remote))
+	if err != nil {
+		t.Fatalf("Failed to reconnect: %v", err)
+	}
+	result, err = Deploy(ctx, d, source, Options{})
+	d.Close()
+	if err != nil {
+		t.Fatalf("Second deploy failed: %v", err)
+	}
+	if strings.Join(result.Uploaded, ",") != "index.html" || strings.Join(result.Deleted, ",") != "old.html" || result.Unchanged != 1 {
+		t.Errorf("Expected only the changes to be deployed, got %+v", result)
+	}
+	if readFile(t, filepath.Join(remote, "index.html")) != "home v2" {
+		t.Error("Changed file was not uploaded")
+	}
+	if _, err := os.Stat(filepath.Join(remote, "old.html")); !os.IsNotExist(err) {
+		t.Error("Removed file was not deleted")
+	}
+	entries, _ := os.ReadDir(remote)
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".tmp-deploy-") {
+			t.Errorf("Temporary upload %s was left behind", entry.Name())
+		}
+	}
+}
+
+func TestSFTPRejectsUnknownHost(t *testing.T) {
+	t.Setenv("SSH_AUTH_SOCK", "")
+	server := startSSHServer(t)
+
+	cfg := server.config(t.TempDir())
+	cfg.KnownHosts = filepath.Join(t.TempDir(), "known_hosts")
+	if err := os.WriteFile(cfg.KnownHosts, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New(cfg); err == nil || !strings.Contains(err.Error(), "key is unknown") {
+		t.Errorf("Expected an unknown host key error, got %v", err)
+	}
+}
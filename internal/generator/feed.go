@@ -0,0 +1,285 @@
+package generator
+
+import (
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/models"
+	"github.com/ariefbayu/personal-blog-generator/internal/repository"
+	"github.com/ariefbayu/personal-blog-generator/internal/utils"
+)
+
+// feedItemLimit is the maximum number of posts included in a single feed
+const feedItemLimit = 20
+
+// feedSummaryLimit is the maximum number of characters in a feed item's summary
+const feedSummaryLimit = 200
+
+// rssFeed represents the root element of an RSS 2.0 document
+type rssFeed struct {
+	XMLName   xml.Name   `xml:"rss"`
+	Version   string     `xml:"version,attr"`
+	ContentNS string     `xml:"xmlns:content,attr"`
+	AtomNS    string     `xml:"xmlns:atom,attr"`
+	Channel   rssChannel `xml:"channel"`
+}
+
+// rssChannel represents the channel element of an RSS 2.0 document
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	AtomLink      atomLink  `xml:"atom:link"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+// rssItem represents a single post in an RSS 2.0 feed
+type rssItem struct {
+	Title          string     `xml:"title"`
+	Link           string     `xml:"link"`
+	GUID           rssGUID    `xml:"guid"`
+	PubDate        string     `xml:"pubDate"`
+	Categories     []string   `xml:"category"`
+	Description    string     `xml:"description"`
+	ContentEncoded cdataValue `xml:"content:encoded"`
+}
+
+// rssGUID represents the guid element of an RSS item
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// cdataValue wraps text that should be written as a CDATA section
+type cdataValue struct {
+	Value string `xml:",cdata"`
+}
+
+// atomFeed represents the root element of an Atom document
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+// atomLink represents a link element used by both Atom and RSS feeds
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+// atomEntry represents a single post in an Atom feed
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	ID         string         `xml:"id"`
+	Link       atomLink       `xml:"link"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Categories []atomCategory `xml:"category"`
+	Summary    string         `xml:"summary,omitempty"`
+	Content    atomContent    `xml:"content"`
+}
+
+// atomCategory represents a category element of an Atom entry
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+// atomContent represents the content element of an Atom entry
+type atomContent struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+var rootRelativeAttr = regexp.MustCompile(`(src|href)="/([^/"][^"]*)?"`)
+
+// absoluteURL joins the site base URL with a root-relative path
+func absoluteURL(baseURL, path string) string {
+	return strings.TrimRight(baseURL, "/") + "/" + strings.TrimLeft(path, "/")
+}
+
+// absolutizeHTML rewrites root-relative src and href attributes to absolute URLs
+func absolutizeHTML(html, baseURL string) string {
+	base := strings.TrimRight(baseURL, "/")
+	return rootRelativeAttr.ReplaceAllString(html, `$1="`+base+`/$2"`)
+}
+
+// postUpdatedAt returns the last modification time of a post, falling back to its creation time
+func postUpdatedAt(post models.Post) time.Time {
+	if post.UpdatedAt.After(post.CreatedAt) {
+		return post.UpdatedAt
+	}
+	return post.CreatedAt
+}
+
+// latestPostUpdate returns when the most recently changed of the posts changed,
+// which dates listing pages as they change whenever one of their entries does
+func latestPostUpdate(posts []models.Post) time.Time {
+	var latest time.Time
+	for _, post := range posts {
+		if updated := postUpdatedAt(post); updated.After(latest) {
+			latest = updated
+		}
+	}
+	return latest
+}
+
+// feedSummary returns the plain text of markdown content, cut to feedSummaryLimit characters
+func feedSummary(content string) string {
+	runes := []rune(plainText(content))
+	if len(runes) <= feedSummaryLimit {
+		return string(runes)
+	}
+	return strings.TrimSpace(string(runes[:feedSummaryLimit])) + "..."
+}
+
+// feedsEnabled reports whether feeds are generated. Feeds require absolute URLs,
+// so they are only written when the site base URL is configured.
+func feedsEnabled(settings *repository.Settings) bool {
+	return strings.TrimSpace(settings.BaseURL) != ""
+}
+
+// generateFeeds writes feed.xml (RSS 2.0) and atom.xml for all posts, plus per-tag feeds under tags/.
+// Nothing is written unless feeds are enabled.
+func generateFeeds(w *siteWriter, posts []models.Post, settings *repository.Settings) error {
+	if !feedsEnabled(settings) {
+		return nil
+	}
+
+	if err := writeFeedPair(w, posts, settings, settings.SiteName, "feed.xml", "atom.xml"); err != nil {
+		return err
+	}
+
+	groups := groupPostsByTag(posts)
+	if len(groups) == 0 {
+		return nil
+	}
+
+	for _, group := range groups {
+		title := fmt.Sprintf("%s - %s", settings.SiteName, group.name)
+		rssPath := "tags/" + group.slug + ".xml"
+		atomPath := "tags/" + group.slug + ".atom.xml"
+		if err := writeFeedPair(w, group.posts, settings, title, rssPath, atomPath); err != nil {
+			return fmt.Errorf("failed to generate feeds for tag %s: %w", group.name, err)
+		}
+	}
+
+	return nil
+}
+
+// writeFeedPair renders the given posts as both an RSS 2.0 and an Atom feed
+func writeFeedPair(w *siteWriter, posts []models.Post, settings *repository.Settings, title, rssPath, atomPath string) error {
+	if len(posts) > feedItemLimit {
+		posts = posts[:feedItemLimit]
+	}
+
+	rssData, err := buildRSSFeed(posts, settings.BaseURL, title, rssPath)
+	if err != nil {
+		return fmt.Errorf("failed to build RSS feed: %w", err)
+	}
+	if err := w.writeFile(rssPath, rssData); err != nil {
+		return err
+	}
+
+	atomData, err := buildAtomFeed(posts, settings.BaseURL, title, atomPath)
+	if err != nil {
+		return fmt.Errorf("failed to build Atom feed: %w", err)
+	}
+	if err := w.writeFile(atomPath, atomData); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// buildRSSFeed renders posts as an RSS 2.0 document
+func buildRSSFeed(posts []models.Post, baseURL, title, feedPath string) ([]byte, error) {
+	channel := rssChannel{
+		Title:       title,
+		Link:        absoluteURL(baseURL, "/"),
+		Description: title,
+		AtomLink: atomLink{
+			Href: absoluteURL(baseURL, feedPath),
+			Rel:  "self",
+			Type: "application/rss+xml",
+		},
+	}
+
+	for i, post := range posts {
+		if i == 0 {
+			channel.LastBuildDate = postUpdatedAt(post).Format(time.RFC1123Z)
+		}
+		link := absoluteURL(baseURL, post.Slug+".html")
+		channel.Items = append(channel.Items, rssItem{
+			Title:          post.Title,
+			Link:           link,
+			GUID:           rssGUID{IsPermaLink: true, Value: link},
+			PubDate:        post.CreatedAt.Format(time.RFC1123Z),
+			Categories:     utils.ParseTags(post.Tags),
+			Description:    feedSummary(post.Content),
+			ContentEncoded: cdataValue{Value: absolutizeHTML(string(mdToHTML(post.Content)), baseURL)},
+		})
+	}
+
+	feed := rssFeed{
+		Version:   "2.0",
+		ContentNS: "http://purl.org/rss/1.0/modules/content/",
+		AtomNS:    "http://www.w3.org/2005/Atom",
+		Channel:   channel,
+	}
+
+	return marshalFeed(feed)
+}
+
+// buildAtomFeed renders posts as an Atom document
+func buildAtomFeed(posts []models.Post, baseURL, title, feedPath string) ([]byte, error) {
+	feed := atomFeed{
+		Title: title,
+		ID:    absoluteURL(baseURL, feedPath),
+		Links: []atomLink{
+			{Href: absoluteURL(baseURL, feedPath), Rel: "self", Type: "application/atom+xml"},
+			{Href: absoluteURL(baseURL, "/"), Rel: "alternate", Type: "text/html"},
+		},
+		Updated: time.Now().UTC().Format(time.RFC3339),
+	}
+	if len(posts) > 0 {
+		feed.Updated = postUpdatedAt(posts[0]).UTC().Format(time.RFC3339)
+	}
+
+	for _, post := range posts {
+		link := absoluteURL(baseURL, post.Slug+".html")
+		var categories []atomCategory
+		for _, tag := range utils.ParseTags(post.Tags) {
+			categories = append(categories, atomCategory{Term: tag})
+		}
+		feed.Entries = append(feed.Entries, atomEntry{
+			Title:      post.Title,
+			ID:         link,
+			Link:       atomLink{Href: link, Rel: "alternate", Type: "text/html"},
+			Published:  post.CreatedAt.UTC().Format(time.RFC3339),
+			Updated:    postUpdatedAt(post).UTC().Format(time.RFC3339),
+			Categories: categories,
+			Summary:    feedSummary(post.Content),
+			Content:    atomContent{Type: "html", Value: absolutizeHTML(string(mdToHTML(post.Content)), baseURL)},
+		})
+	}
+
+	return marshalFeed(feed)
+}
+
+// marshalFeed encodes a feed document with an XML declaration
+func marshalFeed(feed interface{}) ([]byte, error) {
+	data, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
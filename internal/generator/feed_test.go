@@ -0,0 +1,136 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/xml"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/models"
+	"github.com/ariefbayu/personal-blog-generator/internal/repository"
+)
+
+func TestGenerateFeeds(t *testing.T) {
+	outputPath := t.TempDir()
+
+	posts := []models.Post{
+		{
+			Title:     "Second Post",
+			Slug:      "second-post",
+			Content:   "Hello **world** ![img](/images/a.png)",
+			Tags:      "Go, Web Dev",
+			CreatedAt: time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC),
+			UpdatedAt: time.Date(2023, 1, 3, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			Title:     "First Post",
+			Slug:      "first-post",
+			Content:   "First content",
+			Tags:      "go",
+			CreatedAt: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	settings := &repository.Settings{SiteName: "My Blog", BaseURL: "https://example.com/"}
+
+	if err := generateFeeds(newTestWriter(t, outputPath), posts, settings); err != nil {
+		t.Fatalf("generateFeeds failed: %v", err)
+	}
+
+	rssContent, err := os.ReadFile(filepath.Join(outputPath, "feed.xml"))
+	if err != nil {
+		t.Fatalf("feed.xml was not created: %v", err)
+	}
+	var rss rssFeed
+	if err := xml.Unmarshal(rssContent, &rss); err != nil {
+		t.Fatalf("feed.xml is not valid XML: %v", err)
+	}
+	if len(rss.Channel.Items) != 2 {
+		t.Fatalf("Expected 2 RSS items, got %d", len(rss.Channel.Items))
+	}
+	if rss.Channel.Items[0].Link != "https://example.com/second-post.html" {
+		t.Errorf("Expected absolute post link, got %s", rss.Channel.Items[0].Link)
+	}
+	if !strings.Contains(string(rssContent), "<strong>world</strong>") {
+		t.Error("RSS feed does not contain rendered HTML content")
+	}
+	if !strings.Contains(string(rssContent), `src="https://example.com/images/a.png"`) {
+		t.Error("RSS feed does not contain absolute image URL")
+	}
+	if got := rss.Channel.Items[0].Description; got != "Hello world" {
+		t.Errorf("Expected a plain text description, got %q", got)
+	}
+
+	atomContent, err := os.ReadFile(filepath.Join(outputPath, "atom.xml"))
+	if err != nil {
+		t.Fatalf("atom.xml was not created: %v", err)
+	}
+	var atom atomFeed
+	if err := xml.Unmarshal(atomContent, &atom); err != nil {
+		t.Fatalf("atom.xml is not valid XML: %v", err)
+	}
+	if len(atom.Entries) != 2 {
+		t.Fatalf("Expected 2 Atom entries, got %d", len(atom.Entries))
+	}
+	if atom.Updated != "2023-01-03T00:00:00Z" {
+		t.Errorf("Expected feed updated time from newest post, got %s", atom.Updated)
+	}
+
+	// "Go" and "go" share a tag feed
+	tagContent, err := os.ReadFile(filepath.Join(outputPath, "tags", "go.xml"))
+	if err != nil {
+		t.Fatalf("tags/go.xml was not created: %v", err)
+	}
+	var tagRSS rssFeed
+	if err := xml.Unmarshal(tagContent, &tagRSS); err != nil {
+		t.Fatalf("tags/go.xml is not valid XML: %v", err)
+	}
+	if len(tagRSS.Channel.Items) != 2 {
+		t.Errorf("Expected 2 items in go tag feed, got %d", len(tagRSS.Channel.Items))
+	}
+	if _, err := os.Stat(filepath.Join(outputPath, "tags", "web-dev.atom.xml")); err != nil {
+		t.Errorf("tags/web-dev.atom.xml was not created: %v", err)
+	}
+}
+
+func TestGenerateFeedsWithoutBaseURL(t *testing.T) {
+	outputPath := t.TempDir()
+
+	posts := []models.Post{{Title: "Post", Slug: "post", CreatedAt: time.Now()}}
+	settings := &repository.Settings{SiteName: "My Blog"}
+
+	if err := generateFeeds(newTestWriter(t, outputPath), posts, settings); err != nil {
+		t.Fatalf("generateFeeds failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(outputPath, "feed.xml")); !os.IsNotExist(err) {
+		t.Error("feed.xml should not be generated without a base URL")
+	}
+}
+
+func TestFeedSummary(t *testing.T) {
+	if got := feedSummary("# Title\n\nSome **bold** and [linked](/a.html) text"); got != "Title Some bold and linked text" {
+		t.Errorf("Expected markdown to be stripped, got %q", got)
+	}
+	got := feedSummary(strings.Repeat("é", feedSummaryLimit+10))
+	if !utf8.ValidString(got) || got != strings.Repeat("é", feedSummaryLimit)+"..." {
+		t.Errorf("Expected the summary cut to %d characters, got %q", feedSummaryLimit, got)
+	}
+}
+
+func TestHeaderFeedLinks(t *testing.T) {
+	tmpl := template.Must(template.ParseFiles("../../templates/header.html"))
+	for _, enabled := range []bool{true, false} {
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, PageData{Title: "About", NavigationData: NavigationData{SiteName: "My Blog", FeedsEnabled: enabled}}); err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.Contains(buf.String(), `href="/feed.xml"`); got != enabled {
+			t.Errorf("Expected feed links only when feeds are enabled, got %v with FeedsEnabled %v", got, enabled)
+		}
+	}
+}
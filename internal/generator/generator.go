@@ -6,11 +6,13 @@ import (
 	"html/template"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/ariefbayu/personal-blog-generator/internal/models"
 	"github.com/ariefbayu/personal-blog-generator/internal/repository"
+	"github.com/ariefbayu/personal-blog-generator/internal/utils"
 	"github.com/gomarkdown/markdown"
 )
 
@@ -20,6 +22,7 @@ type Post struct {
 	Slug               string
 	Content            template.HTML
 	Tags               []string
+	TagLinks           []TagLink
 	FeaturedImage      string
 	CreatedAt          time.Time
 	CreatedAtFormatted string
@@ -46,11 +49,23 @@ type IndexData struct {
 
 // PostsData represents data for the posts listing page template
 type PostsData struct {
-	Title string
-	Posts []PostItem
+	Title       string
+	Posts       []PostItem
+	CurrentPage int
+	TotalPages  int
+	PrevURL     string
+	NextURL     string
+	PageLinks   []PageLink
 	NavigationData
 }
 
+// PageLink represents a numbered link in the posts listing pagination
+type PageLink struct {
+	Number  int
+	URL     string
+	Current bool
+}
+
 // PostItem represents a post item for the posts listing page
 type PostItem struct {
 	Title              string
@@ -58,6 +73,7 @@ type PostItem struct {
 	CreatedAt          time.Time
 	CreatedAtFormatted string
 	Tags               []string
+	TagLinks           []TagLink
 	Excerpt            string
 	FeaturedImage      string
 }
@@ -104,6 +120,8 @@ type NavLink struct {
 type NavigationData struct {
 	NavLinks []NavLink
 	SiteName string
+	// FeedsEnabled is set when the build writes feeds, which needs the site base URL
+	FeedsEnabled bool
 }
 
 // buildNavigationData builds navigation links from pages and standard links
@@ -170,120 +188,132 @@ func buildNavigationData(pageRepo *repository.PageRepository, settings *reposito
 	return navLinks, nil
 }
 
-// GenerateStaticSite generates static HTML files for all published posts, portfolio, and pages
-func GenerateStaticSite(postRepo *repository.PostRepository, portfolioRepo *repository.PortfolioRepository, pageRepo *repository.PageRepository, settingsRepo *repository.SettingsRepository, templatePath, outputPath string) error {
+// GenerateStaticSite generates static HTML files for all published posts, portfolio, and pages.
+// Files whose content is unchanged since the previous build are not rewritten, and files
+// the previous build produced that are no longer generated are removed.
+// Pages are rendered in parallel; when some fail, the rest of the site is still built and BuildResult.Failed lists every failure.
+func GenerateStaticSite(postRepo *repository.PostRepository, portfolioRepo *repository.PortfolioRepository, pageRepo *repository.PageRepository, settingsRepo *repository.SettingsRepository, templatePath, outputPath string) (*BuildResult, error) {
+	return GenerateStaticSiteWithOptions(postRepo, portfolioRepo, pageRepo, settingsRepo, templatePath, outputPath, BuildOptions{})
+}
+
+// GenerateStaticSiteWithOptions generates the static site like GenerateStaticSite, with options such as a dry run
+func GenerateStaticSiteWithOptions(postRepo *repository.PostRepository, portfolioRepo *repository.PortfolioRepository, pageRepo *repository.PageRepository, settingsRepo *repository.SettingsRepository, templatePath, outputPath string, opts BuildOptions) (*BuildResult, error) {
+	// The manifest records when content was loaded, so the scheduler knows which posts this build includes
+	started := time.Now()
+	opts.progress("Loading posts and settings")
 	// Get settings
 	settings, err := settingsRepo.GetSettings()
 	if err != nil {
-		return fmt.Errorf("failed to get settings: %w", err)
+		return nil, fmt.Errorf("failed to get settings: %w", err)
 	}
 
 	// Query all published posts
 	posts, err := postRepo.GetPublishedPosts()
 	if err != nil {
-		return fmt.Errorf("failed to query posts: %w", err)
+		return nil, fmt.Errorf("failed to query posts: %w", err)
 	}
 
 	// Build navigation data
 	navLinks, err := buildNavigationData(pageRepo, settings)
 	if err != nil {
-		return fmt.Errorf("failed to build navigation data: %w", err)
+		return nil, fmt.Errorf("failed to build navigation data: %w", err)
 	}
-	navData := NavigationData{NavLinks: navLinks, SiteName: settings.SiteName}
+	navData := NavigationData{NavLinks: navLinks, SiteName: settings.SiteName, FeedsEnabled: feedsEnabled(settings)}
 
-	// Parse the header, post content, and footer templates
-	tmpl, err := template.ParseFiles(
-		filepath.Join(templatePath, "header.html"),
-		filepath.Join(templatePath, "post.html"),
-		filepath.Join(templatePath, "footer.html"),
-	)
+	// Ensure output directory exists and load the previous build manifest
+	w, err := newSiteWriter(outputPath, opts)
 	if err != nil {
-		return fmt.Errorf("failed to parse post templates: %w", err)
+		return nil, err
 	}
+	w.startedAt = started
 
-	// Ensure output directory exists
-	if err := os.MkdirAll(outputPath, 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %w", err)
+	// Queue every templated page, then render them in parallel from templates parsed once
+	r := newRenderer(w, newTemplateSet(templatePath))
+	if err := generatePosts(r, posts, navData); err != nil {
+		return nil, fmt.Errorf("failed to generate posts: %w", err)
 	}
 
-	postCount := 0
-	for _, post := range posts {
-		// Convert markdown to HTML
-		contentHTML := mdToHTML(post.Content)
-
-		// Parse tags
-		var tags []string
-		if post.Tags != "" {
-			tags = strings.Split(post.Tags, ",")
-			for i, tag := range tags {
-				tags[i] = strings.TrimSpace(tag)
-			}
-		}
+	// Generate index page
+	err = generateIndexPage(r, posts, portfolioRepo, navData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate index page: %w", err)
+	}
 
-		// Create post struct for template
-		templatePost := Post{
-			Title:              post.Title,
-			Slug:               post.Slug,
-			Content:            contentHTML,
-			Tags:               tags,
-			FeaturedImage:      post.FeaturedImage,
-			CreatedAt:          post.CreatedAt,
-			CreatedAtFormatted: post.CreatedAt.Format("January 2, 2006"),
-			NavigationData:     navData,
-		}
+	// Generate posts listing page
+	err = generatePostsPage(r, posts, navData, settings.PostsPerPage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate posts page: %w", err)
+	}
 
-		// Create output file
-		filename := filepath.Join(outputPath, post.Slug+".html")
-		file, err := os.Create(filename)
-		if err != nil {
-			return fmt.Errorf("failed to create file %s: %w", filename, err)
-		}
+	// Generate portfolio page
+	err = generatePortfolioPage(r, portfolioRepo, navData, settings.ShowPortfolioMenu)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate portfolio page: %w", err)
+	}
 
-		// Execute templates in sequence: header, post content, footer
-		if err := tmpl.ExecuteTemplate(file, "header.html", templatePost); err != nil {
-			return fmt.Errorf("failed to execute header template for post %s: %w", post.Slug, err)
-		}
-		if err := tmpl.ExecuteTemplate(file, "post.html", templatePost); err != nil {
-			return fmt.Errorf("failed to execute post template for post %s: %w", post.Slug, err)
-		}
-		if err := tmpl.ExecuteTemplate(file, "footer.html", templatePost); err != nil {
-			return fmt.Errorf("failed to execute footer template for post %s: %w", post.Slug, err)
-		}
+	// Generate static pages
+	err = generatePages(r, pageRepo, navData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate pages: %w", err)
+	}
 
-		postCount++
+	// Generate tag archive pages
+	err = generateTagPages(r, posts, navData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate tag pages: %w", err)
 	}
 
-	// Generate index page
-	err = generateIndexPage(posts, portfolioRepo, outputPath, templatePath, navData)
+	err = generateSearchPage(r, navData)
 	if err != nil {
-		return fmt.Errorf("failed to generate index page: %w", err)
+		return nil, fmt.Errorf("failed to generate search page: %w", err)
 	}
 
-	// Generate posts listing page
-	err = generatePostsPage(posts, outputPath, templatePath, navData)
+	opts.progress("Rendering %d posts and %d other pages", len(posts), r.pending()-len(posts))
+	if err := r.run(opts.Workers); err != nil {
+		// The failed pages are listed in the build result; the rest of the site is still built
+		opts.progress("%v", err)
+	}
+
+	// Generate the search index
+	opts.progress("Writing the search index")
+	err = generateSearchIndex(w, posts, pageRepo)
 	if err != nil {
-		return fmt.Errorf("failed to generate posts page: %w", err)
+		return nil, fmt.Errorf("failed to generate search index: %w", err)
 	}
 
-	// Generate portfolio page
-	err = generatePortfolioPage(portfolioRepo, outputPath, templatePath, navData)
+	// Generate RSS and Atom feeds
+	opts.progress("Writing feeds and the sitemap")
+	err = generateFeeds(w, posts, settings)
 	if err != nil {
-		return fmt.Errorf("failed to generate portfolio page: %w", err)
+		return nil, fmt.Errorf("failed to generate feeds: %w", err)
 	}
 
-	// Generate static pages
-	err = generatePages(pageRepo, outputPath, templatePath, navData)
+	// Generate sitemap and robots.txt
+	err = generateSitemap(w, r.listed, settings)
 	if err != nil {
-		return fmt.Errorf("failed to generate pages: %w", err)
+		return nil, fmt.Errorf("failed to generate sitemap: %w", err)
+	}
+	err = generateRobotsTxt(w, settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate robots.txt: %w", err)
 	}
 
 	// Copy static assets (CSS) to output directory
-	err = copyStaticAssets(templatePath, outputPath)
+	opts.progress("Copying static assets")
+	err = copyStaticAssets(w, templatePath)
 	if err != nil {
-		return fmt.Errorf("failed to copy static assets: %w", err)
+		return nil, fmt.Errorf("failed to copy static assets: %w", err)
 	}
 
-	return nil
+	// Remove outputs of the previous build that are no longer generated
+	opts.progress("Removing stale files")
+	return w.finish()
+}
+
+// hasTemplate reports whether the named template file exists in the template directory
+func hasTemplate(templatePath, name string) bool {
+	_, err := os.Stat(filepath.Join(templatePath, name))
+	return err == nil
 }
 
 // mdToHTML converts markdown content to HTML
@@ -293,14 +323,34 @@ func mdToHTML(content string) template.HTML {
 	return template.HTML(htmlBytes)
 }
 
-// generateIndexPage creates the index.html file with recent posts
-func generateIndexPage(posts []models.Post, portfolioRepo *repository.PortfolioRepository, outputPath, templatePath string, navData NavigationData) error {
-	// Parse the header, index content, and footer templates
-	tmpl, err := template.ParseFiles(
-		filepath.Join(templatePath, "header.html"),
-		filepath.Join(templatePath, "index.html"),
-		filepath.Join(templatePath, "footer.html"),
-	)
+// postExcerpt creates an excerpt from post content (first 200 characters)
+func postExcerpt(content string) string {
+	content = strings.ReplaceAll(content, "\n", " ")
+	if len(content) > 200 {
+		return content[:200] + "..."
+	}
+	return content
+}
+
+// generatePosts queues an HTML file for every post
+func generatePosts(r *renderer, posts []models.Post, navData NavigationData) error {
+	tmpl, err := r.templates.get("post.html")
+	if err != nil {
+		return fmt.Errorf("failed to parse post templates: %w", err)
+	}
+
+	for _, post := range posts {
+		r.add("post "+post.Slug, post.Slug+".html", tmpl, "post.html", func() interface{} {
+			return buildPostData(post, navData)
+		})
+		r.list(post.Slug+".html", postUpdatedAt(post))
+	}
+	return nil
+}
+
+// generateIndexPage queues the index.html file with recent posts
+func generateIndexPage(r *renderer, posts []models.Post, portfolioRepo *repository.PortfolioRepository, navData NavigationData) error {
+	tmpl, err := r.templates.get("index.html")
 	if err != nil {
 		return fmt.Errorf("failed to parse index templates: %w", err)
 	}
@@ -351,117 +401,129 @@ func generateIndexPage(posts []models.Post, portfolioRepo *repository.PortfolioR
 		PortfolioItems: templateItems,
 	}
 
-	// Create index.html file
-	indexPath := filepath.Join(outputPath, "index.html")
-	file, err := os.Create(indexPath)
+	r.add("index page", "index.html", tmpl, "index.html", func() interface{} { return indexData })
+	r.list("index.html", latestPostUpdate(posts))
+	return nil
+}
+
+// generatePostsPage queues posts.html and, when there are more posts than fit on one page,
+// posts/page/<n>.html for the following pages. A perPage of zero or less puts every post on posts.html.
+func generatePostsPage(r *renderer, posts []models.Post, navData NavigationData, perPage int) error {
+	// Sort a copy by created date descending (newest first), the caller's posts are shared with other pages
+	posts = append([]models.Post(nil), posts...)
+	sort.SliceStable(posts, func(i, j int) bool {
+		return posts[i].CreatedAt.After(posts[j].CreatedAt)
+	})
+
+	tmpl, err := r.templates.get("posts.html")
 	if err != nil {
-		return fmt.Errorf("failed to create index.html: %w", err)
+		return fmt.Errorf("failed to parse posts templates: %w", err)
 	}
-	defer file.Close()
 
-	// Execute templates in sequence: header, index content, footer
-	if err := tmpl.ExecuteTemplate(file, "header.html", indexData); err != nil {
-		return fmt.Errorf("failed to execute header template: %w", err)
+	if perPage <= 0 || perPage > len(posts) {
+		perPage = len(posts)
 	}
-	if err := tmpl.ExecuteTemplate(file, "index.html", indexData); err != nil {
-		return fmt.Errorf("failed to execute index template: %w", err)
+	totalPages := 1
+	if perPage > 0 {
+		totalPages = (len(posts) + perPage - 1) / perPage
 	}
-	if err := tmpl.ExecuteTemplate(file, "footer.html", indexData); err != nil {
-		return fmt.Errorf("failed to execute footer template: %w", err)
+
+	pageLinks := make([]PageLink, totalPages)
+	for i := range pageLinks {
+		pageLinks[i] = PageLink{Number: i + 1, URL: postsPageURL(i + 1)}
+	}
+
+	for page := 1; page <= totalPages; page++ {
+		start := (page - 1) * perPage
+		end := start + perPage
+		if end > len(posts) {
+			end = len(posts)
+		}
+
+		links := make([]PageLink, len(pageLinks))
+		copy(links, pageLinks)
+		links[page-1].Current = true
+
+		postsData := PostsData{
+			Title:          "",
+			Posts:          buildPostItems(posts[start:end]),
+			CurrentPage:    page,
+			TotalPages:     totalPages,
+			PageLinks:      links,
+			NavigationData: navData,
+		}
+		if page > 1 {
+			postsData.PrevURL = postsPageURL(page - 1)
+		}
+		if page < totalPages {
+			postsData.NextURL = postsPageURL(page + 1)
+		}
+
+		relPath := strings.TrimPrefix(postsPageURL(page), "/")
+		r.add(fmt.Sprintf("posts page %d", page), relPath, tmpl, "posts.html", func() interface{} {
+			return postsData
+		})
+		r.list(relPath, latestPostUpdate(posts[start:end]))
 	}
 
 	return nil
 }
 
-// generatePostsPage creates the posts.html file with all posts
-func generatePostsPage(posts []models.Post, outputPath, templatePath string, navData NavigationData) error {
-	// Sort posts by created date descending (newest first)
-	for i := 0; i < len(posts)-1; i++ {
-		for j := i + 1; j < len(posts); j++ {
-			if posts[i].CreatedAt.Before(posts[j].CreatedAt) {
-				posts[i], posts[j] = posts[j], posts[i]
-			}
-		}
+// postsPageURL returns the URL of a posts listing page; the first page is posts.html
+func postsPageURL(page int) string {
+	if page <= 1 {
+		return "/posts.html"
 	}
+	return fmt.Sprintf("/posts/page/%d.html", page)
+}
 
-	// Parse the header, posts content, and footer templates
-	tmpl, err := template.ParseFiles(
-		filepath.Join(templatePath, "header.html"),
-		filepath.Join(templatePath, "posts.html"),
-		filepath.Join(templatePath, "footer.html"),
-	)
+// buildPostData prepares a post for the post template, converting its markdown content to HTML
+func buildPostData(post models.Post, navData NavigationData) Post {
+	return Post{
+		Title:              post.Title,
+		Slug:               post.Slug,
+		Content:            mdToHTML(post.Content),
+		Tags:               utils.ParseTags(post.Tags),
+		TagLinks:           buildTagLinks(post.Tags),
+		FeaturedImage:      post.FeaturedImage,
+		CreatedAt:          post.CreatedAt,
+		CreatedAtFormatted: post.CreatedAt.Format("January 2, 2006"),
+		NavigationData:     navData,
+	}
+}
 
-	if err != nil {
-		return fmt.Errorf("failed to parse posts templates: %w", err)
+// buildPageData prepares a static page for the page template, converting its markdown content to HTML
+func buildPageData(page models.Page, navData NavigationData) PageData {
+	return PageData{
+		Title:          page.Title,
+		Slug:           page.Slug,
+		Content:        mdToHTML(page.Content),
+		NavigationData: navData,
 	}
+}
 
-	// Prepare posts data
+// buildPostItems prepares posts for listing templates
+func buildPostItems(posts []models.Post) []PostItem {
 	postItems := make([]PostItem, len(posts))
 	for i, post := range posts {
-		// Parse tags
-		var tags []string
-		if post.Tags != "" {
-			tags = strings.Split(post.Tags, ",")
-			for j, tag := range tags {
-				tags[j] = strings.TrimSpace(tag)
-			}
-		}
-
-		// Create excerpt from content (first 200 characters)
-		content := strings.ReplaceAll(post.Content, "\n", " ")
-		excerpt := content
-		if len(content) > 200 {
-			excerpt = content[:200] + "..."
-		}
-
 		postItems[i] = PostItem{
 			Title:              post.Title,
 			Slug:               post.Slug,
 			CreatedAt:          post.CreatedAt,
 			CreatedAtFormatted: post.CreatedAt.Format("2006-01-02"),
-			Tags:               tags,
-			Excerpt:            excerpt,
+			Tags:               utils.ParseTags(post.Tags),
+			TagLinks:           buildTagLinks(post.Tags),
+			Excerpt:            postExcerpt(post.Content),
 			FeaturedImage:      post.FeaturedImage,
 		}
 	}
-
-	postsData := PostsData{
-		Title:          "",
-		Posts:          postItems,
-		NavigationData: navData,
-	}
-
-	// Create posts.html file
-	postsPath := filepath.Join(outputPath, "posts.html")
-	file, err := os.Create(postsPath)
-	if err != nil {
-		return fmt.Errorf("failed to create posts.html: %w", err)
-	}
-	defer file.Close()
-
-	// Execute templates in sequence: header, posts content, footer
-	if err := tmpl.ExecuteTemplate(file, "header.html", postsData); err != nil {
-		return fmt.Errorf("failed to execute header template: %w", err)
-	}
-	if err := tmpl.ExecuteTemplate(file, "posts.html", postsData); err != nil {
-		return fmt.Errorf("failed to execute posts template: %w", err)
-	}
-	if err := tmpl.ExecuteTemplate(file, "footer.html", postsData); err != nil {
-		return fmt.Errorf("failed to execute footer template: %w", err)
-	}
-
-	return nil
+	return postItems
 }
 
-// generatePortfolioPage creates the portfolio.html file with all portfolio items
-func generatePortfolioPage(portfolioRepo *repository.PortfolioRepository, outputPath, templatePath string, navData NavigationData) error {
-	// Parse the portfolio template
-	tmpl, err := template.ParseFiles(
-		filepath.Join(templatePath, "header.html"),
-		filepath.Join(templatePath, "portfolio.html"),
-		filepath.Join(templatePath, "footer.html"),
-	)
-
+// generatePortfolioPage queues the portfolio.html file with all portfolio items.
+// The page is only listed in the sitemap when the portfolio menu is shown.
+func generatePortfolioPage(r *renderer, portfolioRepo *repository.PortfolioRepository, navData NavigationData, listed bool) error {
+	tmpl, err := r.templates.get("portfolio.html")
 	if err != nil {
 		return fmt.Errorf("failed to parse portfolio template: %w", err)
 	}
@@ -494,38 +556,22 @@ func generatePortfolioPage(portfolioRepo *repository.PortfolioRepository, output
 		NavigationData: navData,
 	}
 
-	// Create portfolio.html file
-	portfolioPath := filepath.Join(outputPath, "portfolio.html")
-	file, err := os.Create(portfolioPath)
-	if err != nil {
-		return fmt.Errorf("failed to create portfolio.html: %w", err)
-	}
-	defer file.Close()
-
-	// Execute templates in sequence: header, portfolio content, footer
-	if err := tmpl.ExecuteTemplate(file, "header.html", portfolioData); err != nil {
-		return fmt.Errorf("failed to execute header template: %w", err)
-	}
-	if err := tmpl.ExecuteTemplate(file, "portfolio.html", portfolioData); err != nil {
-		return fmt.Errorf("failed to execute portfolio template: %w", err)
-	}
-	if err := tmpl.ExecuteTemplate(file, "footer.html", portfolioData); err != nil {
-		return fmt.Errorf("failed to execute footer template: %w", err)
+	r.add("portfolio page", "portfolio.html", tmpl, "portfolio.html", func() interface{} { return portfolioData })
+	if listed {
+		var latest time.Time
+		for _, item := range portfolioItems {
+			if item.UpdatedAt.After(latest) {
+				latest = item.UpdatedAt
+			}
+		}
+		r.list("portfolio.html", latest)
 	}
-
 	return nil
 }
 
-// generatePages creates HTML files for all static pages
-func generatePages(pageRepo *repository.PageRepository, outputPath, templatePath string, navData NavigationData) error {
-
-	// Parse the header, page content, and footer templates
-	tmpl, err := template.ParseFiles(
-		filepath.Join(templatePath, "header.html"),
-		filepath.Join(templatePath, "page.html"),
-		filepath.Join(templatePath, "footer.html"),
-	)
-
+// generatePages queues HTML files for all static pages
+func generatePages(r *renderer, pageRepo *repository.PageRepository, navData NavigationData) error {
+	tmpl, err := r.templates.get("page.html")
 	if err != nil {
 		return fmt.Errorf("failed to parse page template: %w", err)
 	}
@@ -536,91 +582,59 @@ func generatePages(pageRepo *repository.PageRepository, outputPath, templatePath
 		return fmt.Errorf("failed to query pages: %w", err)
 	}
 
-	// Generate HTML for each page
 	for _, page := range pages {
-		// Convert markdown content to HTML
-		contentHTML := mdToHTML(page.Content)
-
-		// Create page data for template
-		pageData := PageData{
-			Title:          page.Title,
-			Slug:           page.Slug,
-			Content:        contentHTML,
-			NavigationData: navData,
-		}
-
-		// Create output file
-		filename := filepath.Join(outputPath, page.Slug+".html")
-		file, err := os.Create(filename)
-		if err != nil {
-			return fmt.Errorf("failed to create file %s: %w", filename, err)
-		}
-
-		// // Execute template
-		// err = tmpl.Execute(file, pageData)
-		// file.Close() // Close regardless of error
-		// if err != nil {
-		// 	return fmt.Errorf("failed to execute page template for %s: %w", page.Slug, err)
-		// }
-
-		// Execute templates in sequence: header, post content, footer
-		if err := tmpl.ExecuteTemplate(file, "header.html", pageData); err != nil {
-			return fmt.Errorf("failed to execute header template for page %s: %w", page.Slug, err)
-		}
-		if err := tmpl.ExecuteTemplate(file, "page.html", pageData); err != nil {
-			return fmt.Errorf("failed to execute page template for page %s: %w", page.Slug, err)
-		}
-		if err := tmpl.ExecuteTemplate(file, "footer.html", pageData); err != nil {
-			return fmt.Errorf("failed to execute footer template for page %s: %w", page.Slug, err)
-		}
+		r.add("page "+page.Slug, page.Slug+".html", tmpl, "page.html", func() interface{} {
+			return buildPageData(page, navData)
+		})
+		r.list(page.Slug+".html", page.UpdatedAt)
 	}
 
 	return nil
 }
 
+// staticAssetDirs maps the static asset directories copied to the output directory to the file extension they hold
+var staticAssetDirs = []struct{ dir, ext string }{
+	{"css", ".css"},
+	{"js", ".js"},
+}
+
 // copyStaticAssets copies static assets (CSS, JS, etc.) to the output directory
-func copyStaticAssets(templatePath, outputPath string) error {
+func copyStaticAssets(w *siteWriter, templatePath string) error {
 	// Determine the static source directory (inside templates)
 	staticPath := filepath.Join(templatePath, "static")
 
-	// Copy CSS files
-	cssSourceDir := filepath.Join(staticPath, "css")
-	cssDestDir := filepath.Join(outputPath, "css")
-
-	// Check if CSS source directory exists
-	if _, err := os.Stat(cssSourceDir); os.IsNotExist(err) {
-		// No CSS directory, skip
-		return nil
-	}
-
-	// Create CSS output directory
-	if err := os.MkdirAll(cssDestDir, 0755); err != nil {
-		return fmt.Errorf("failed to create CSS output directory: %w", err)
-	}
-
-	// Read CSS files from source directory
-	entries, err := os.ReadDir(cssSourceDir)
-	if err != nil {
-		return fmt.Errorf("failed to read CSS source directory: %w", err)
-	}
+	for _, assets := range staticAssetDirs {
+		sourceDir := filepath.Join(staticPath, assets.dir)
 
-	for _, entry := range entries {
-		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".css") {
+		// Check if the source directory exists
+		if _, err := os.Stat(sourceDir); os.IsNotExist(err) {
+			// No directory for this kind of asset, skip
 			continue
 		}
 
-		srcFile := filepath.Join(cssSourceDir, entry.Name())
-		destFile := filepath.Join(cssDestDir, entry.Name())
-
-		// Read source file
-		content, err := os.ReadFile(srcFile)
+		// Read asset files from source directory
+		entries, err := os.ReadDir(sourceDir)
 		if err != nil {
-			return fmt.Errorf("failed to read CSS file %s: %w", srcFile, err)
+			return fmt.Errorf("failed to read %s source directory: %w", assets.dir, err)
 		}
 
-		// Write to destination
-		if err := os.WriteFile(destFile, content, 0644); err != nil {
-			return fmt.Errorf("failed to write CSS file %s: %w", destFile, err)
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.HasSuffix(entry.Name(), assets.ext) {
+				continue
+			}
+
+			srcFile := filepath.Join(sourceDir, entry.Name())
+
+			// Read source file
+			content, err := os.ReadFile(srcFile)
+			if err != nil {
+				return fmt.Errorf("failed to read asset file %s: %w", srcFile, err)
+			}
+
+			// Write to destination
+			if err := w.writeFile(filepath.Join(assets.dir, entry.Name()), content); err != nil {
+				return fmt.Errorf("failed to write asset file %s: %w", entry.Name(), err)
+			}
 		}
 	}
 
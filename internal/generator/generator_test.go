@@ -28,8 +28,58 @@ func TestGenerateStaticSite(t *testing.T) {
 			slug TEXT UNIQUE NOT NULL,
 			content TEXT NOT NULL,
 			tags TEXT,
+			featured_image TEXT DEFAULT '',
 			published BOOLEAN DEFAULT FALSE,
-			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+			publish_at DATETIME DEFAULT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE tags (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL,
+			slug TEXT NOT NULL UNIQUE,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE TABLE post_tags (
+			post_id INTEGER NOT NULL,
+			tag_id INTEGER NOT NULL,
+			position INTEGER DEFAULT 0,
+			PRIMARY KEY (post_id, tag_id)
+		)
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE post_revisions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			post_id INTEGER NOT NULL,
+			revision INTEGER NOT NULL,
+			title TEXT NOT NULL,
+			slug TEXT NOT NULL,
+			content TEXT NOT NULL,
+			tags TEXT,
+			featured_image TEXT DEFAULT '',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (post_id, revision)
+		);
+		CREATE TABLE page_revisions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			page_id INTEGER NOT NULL,
+			revision INTEGER NOT NULL,
+			title TEXT NOT NULL,
+			slug TEXT NOT NULL,
+			content TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (page_id, revision)
 		)
 	`)
 	if err != nil {
@@ -76,6 +126,9 @@ func TestGenerateStaticSite(t *testing.T) {
 			show_portfolio_menu BOOLEAN DEFAULT TRUE,
 			show_posts_menu BOOLEAN DEFAULT TRUE,
 			menu_order TEXT DEFAULT '["posts", "portfolio", "pages"]',
+			base_url TEXT DEFAULT '',
+			robots_txt TEXT DEFAULT '',
+			posts_per_page INTEGER DEFAULT 10,
 			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
 		)
@@ -274,7 +327,7 @@ Tags: {{range .Tags}}<span>{{.}}</span> {{end}}
 	}
 
 	// Generate static site
-	err = GenerateStaticSite(postRepo, portfolioRepo, pageRepo, settingsRepo, "./templates", "./html-outputs")
+	_, err = GenerateStaticSite(postRepo, portfolioRepo, pageRepo, settingsRepo, "./templates", "./html-outputs")
 	if err != nil {
 		t.Fatalf("GenerateStaticSite failed: %v", err)
 	}
@@ -473,7 +526,7 @@ func TestCopyStaticAssets(t *testing.T) {
 	}
 
 	// Run copyStaticAssets
-	err := copyStaticAssets(templatePath, outputPath)
+	err := copyStaticAssets(newTestWriter(t, outputPath), templatePath)
 	if err != nil {
 		t.Fatalf("copyStaticAssets failed: %v", err)
 	}
@@ -490,6 +543,32 @@ func TestCopyStaticAssets(t *testing.T) {
 	}
 }
 
+func TestCopyStaticAssetsJS(t *testing.T) {
+	templatePath := t.TempDir()
+	outputPath := t.TempDir()
+
+	jsPath := filepath.Join(templatePath, "static", "js")
+	if err := os.MkdirAll(jsPath, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(jsPath, "search.js"), []byte("// search"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(jsPath, "notes.txt"), []byte("skip"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := copyStaticAssets(newTestWriter(t, outputPath), templatePath); err != nil {
+		t.Fatalf("copyStaticAssets failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(outputPath, "js", "search.js")); err != nil {
+		t.Errorf("JS file was not copied: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(outputPath, "js", "notes.txt")); !os.IsNotExist(err) {
+		t.Error("Only .js files should be copied from the js directory")
+	}
+}
+
 func TestCopyStaticAssetsNoCSS(t *testing.T) {
 	// Create temporary directories without CSS
 	tempDir := t.TempDir()
@@ -505,7 +584,7 @@ func TestCopyStaticAssetsNoCSS(t *testing.T) {
 	}
 
 	// Run copyStaticAssets - should succeed even if no CSS directory exists
-	err := copyStaticAssets(templatePath, outputPath)
+	err := copyStaticAssets(newTestWriter(t, outputPath), templatePath)
 	if err != nil {
 		t.Fatalf("copyStaticAssets should succeed when no CSS directory exists: %v", err)
 	}
@@ -516,3 +595,78 @@ func TestCopyStaticAssetsNoCSS(t *testing.T) {
 		t.Error("CSS directory should not be created when source doesn't exist")
 	}
 }
+
+func TestGeneratePostsPagePagination(t *testing.T) {
+	templatePath := t.TempDir()
+	outputPath := t.TempDir()
+
+	templates := map[string]string{
+		"header.html": `<html><body>`,
+		"footer.html": `</body></html>`,
+		"posts.html": `<p>Page {{.CurrentPage}} of {{.TotalPages}}</p>` +
+			`{{range .Posts}}<a href="/{{.Slug}}.html">{{.Title}}</a>{{end}}` +
+			`{{if .PrevURL}}<a rel="prev" href="{{.PrevURL}}">Previous</a>{{end}}` +
+			`{{if .NextURL}}<a rel="next" href="{{.NextURL}}">Next</a>{{end}}`,
+	}
+	for name, content := range templates {
+		if err := os.WriteFile(filepath.Join(templatePath, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var posts []models.Post
+	for i := 1; i <= 5; i++ {
+		posts = append(posts, models.Post{
+			Title:     "Post " + string(rune('0'+i)),
+			Slug:      "post-" + string(rune('0'+i)),
+			CreatedAt: time.Date(2023, 1, i, 0, 0, 0, 0, time.UTC),
+		})
+	}
+
+	r := newTestRenderer(t, templatePath, outputPath)
+	if err := generatePostsPage(r, posts, NavigationData{}, 2); err != nil {
+		t.Fatalf("generatePostsPage failed: %v", err)
+	}
+	if err := r.run(0); err != nil {
+		t.Fatalf("Rendering the posts pages failed: %v", err)
+	}
+
+	first, err := os.ReadFile(filepath.Join(outputPath, "posts.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	firstStr := string(first)
+	if !contains(firstStr, "Page 1 of 3") || !contains(firstStr, "/post-5.html") || contains(firstStr, "/post-3.html") {
+		t.Errorf("Unexpected first page content: %s", firstStr)
+	}
+	if !contains(firstStr, `href="/posts/page/2.html">Next`) || contains(firstStr, "Previous") {
+		t.Errorf("Unexpected first page navigation: %s", firstStr)
+	}
+
+	last, err := os.ReadFile(filepath.Join(outputPath, "posts", "page", "3.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lastStr := string(last)
+	if !contains(lastStr, "Page 3 of 3") || !contains(lastStr, "/post-1.html") {
+		t.Errorf("Unexpected last page content: %s", lastStr)
+	}
+	if !contains(lastStr, `href="/posts/page/2.html">Previous`) || contains(lastStr, "Next") {
+		t.Errorf("Unexpected last page navigation: %s", lastStr)
+	}
+
+	second, err := os.ReadFile(filepath.Join(outputPath, "posts", "page", "2.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !contains(string(second), `href="/posts.html">Previous`) {
+		t.Errorf("Second page should link back to posts.html: %s", second)
+	}
+
+	// The caller's posts are shared with other pages and keep their order
+	for i, post := range posts {
+		if post.Slug != "post-"+string(rune('1'+i)) {
+			t.Errorf("Expected the posts to be left in their order, got %s at %d", post.Slug, i)
+		}
+	}
+}
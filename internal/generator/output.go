@@ -0,0 +1,287 @@
+package generator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ManifestFile is the name of the build manifest kept in the output directory
+const ManifestFile = ".build-manifest.json"
+
+// protectedDirs are output subdirectories holding files the generator does not produce, such as uploads.
+// Nothing inside them is ever removed as stale.
+var protectedDirs = []string{"images"}
+
+// BuildOptions controls how a site build writes its output
+type BuildOptions struct {
+	// DryRun renders the site and reports what would change without writing or removing anything
+	DryRun bool
+	// Progress, if set, is called with a description of each step as the build reaches it
+	Progress func(step string)
+	// Workers is the number of pages rendered at once, runtime.GOMAXPROCS(0) when zero or less
+	Workers int
+}
+
+// progress reports a build step to the Progress callback, if one is set
+func (o BuildOptions) progress(format string, args ...interface{}) {
+	if o.Progress != nil {
+		o.Progress(fmt.Sprintf(format, args...))
+	}
+}
+
+// BuildResult summarizes the files touched by a site build.
+// For a dry run the counts describe what a real build would do.
+type BuildResult struct {
+	DryRun       bool     `json:"dry_run"`
+	Written      int      `json:"written"`
+	Skipped      int      `json:"skipped"`
+	Deleted      int      `json:"deleted"`
+	DeletedFiles []string `json:"deleted_files,omitempty"`
+	// Failed lists the pages that failed to render, with their errors. The rest of the site is still built,
+	// and a failed page keeps the version the previous build produced, if any.
+	Failed []string `json:"failed,omitempty"`
+}
+
+// buildManifest records the content hash of every file produced by a build,
+// keyed by slash-separated path relative to the output directory.
+// GeneratedAt is when the build started loading content, so every post due by then is in it.
+type buildManifest struct {
+	GeneratedAt time.Time         `json:"generated_at"`
+	Files       map[string]string `json:"files"`
+}
+
+// siteWriter writes generated files into the output directory, skipping files whose content
+// is unchanged since the previous build and removing files the previous build produced but this one did not
+type siteWriter struct {
+	outputPath string
+	dryRun     bool
+	previous   map[string]string
+	// startedAt is recorded as the manifest's GeneratedAt
+	startedAt time.Time
+
+	// mu guards current and result, as pages are written by several render workers at once
+	mu      sync.Mutex
+	current map[string]string
+	result  BuildResult
+}
+
+// newSiteWriter creates a writer for the output directory, loading the manifest of the previous build if present
+func newSiteWriter(outputPath string, opts BuildOptions) (*siteWriter, error) {
+	if !opts.DryRun {
+		if err := os.MkdirAll(outputPath, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create output directory: %w", err)
+		}
+	}
+
+	w := &siteWriter{
+		outputPath: outputPath,
+		dryRun:     opts.DryRun,
+		previous:   make(map[string]string),
+		startedAt:  time.Now(),
+		current:    make(map[string]string),
+		result:     BuildResult{DryRun: opts.DryRun},
+	}
+
+	data, err := os.ReadFile(filepath.Join(outputPath, ManifestFile))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read build manifest: %w", err)
+		}
+		return w, nil
+	}
+
+	var manifest buildManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		// A corrupt manifest only costs a full rebuild
+		log.Printf("Ignoring unreadable build manifest: %v", err)
+		return w, nil
+	}
+	if manifest.Files != nil {
+		w.previous = manifest.Files
+	}
+	return w, nil
+}
+
+// LastBuildTime returns when the build that produced the output directory started,
+// or the zero time if the directory has no readable build manifest
+func LastBuildTime(outputPath string) (time.Time, error) {
+	data, err := os.ReadFile(filepath.Join(outputPath, ManifestFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return time.Time{}, nil
+		}
+		return time.Time{}, fmt.Errorf("failed to read build manifest: %w", err)
+	}
+
+	var manifest buildManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		log.Printf("Ignoring unreadable build manifest: %v", err)
+		return time.Time{}, nil
+	}
+	return manifest.GeneratedAt, nil
+}
+
+// writeFile writes a generated file unless the previous build produced identical content.
+// The file is recorded in the manifest only once it is on disk.
+func (w *siteWriter) writeFile(relPath string, data []byte) error {
+	key := filepath.ToSlash(relPath)
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+
+	fullPath := filepath.Join(w.outputPath, filepath.FromSlash(key))
+	if w.previous[key] == hash {
+		if _, err := os.Stat(fullPath); err == nil {
+			w.mu.Lock()
+			w.current[key] = hash
+			w.result.Skipped++
+			w.mu.Unlock()
+			return nil
+		}
+	}
+
+	if !w.dryRun {
+		if err := writeFileAtomic(fullPath, data); err != nil {
+			return fmt.Errorf("failed to write %s: %w", key, err)
+		}
+	}
+	w.mu.Lock()
+	w.current[key] = hash
+	w.result.Written++
+	w.mu.Unlock()
+	return nil
+}
+
+// fail records a page that failed to render. The version the previous build produced is kept,
+// so a failing page is neither removed as stale nor taken for up to date in the next build.
+func (w *siteWriter) fail(relPath string, err error) {
+	key := filepath.ToSlash(relPath)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if hash, ok := w.previous[key]; ok {
+		if _, statErr := os.Stat(filepath.Join(w.outputPath, filepath.FromSlash(key))); statErr == nil {
+			w.current[key] = hash
+		}
+	}
+	w.result.Failed = append(w.result.Failed, err.Error())
+}
+
+// written reports whether the build has produced the file, written or unchanged
+func (w *siteWriter) written(relPath string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, ok := w.current[filepath.ToSlash(relPath)]
+	return ok
+}
+
+// finish removes files left over from earlier builds and saves the manifest for the next build.
+// In a dry run the files that would be removed are only reported.
+func (w *siteWriter) finish() (*BuildResult, error) {
+	stale := w.staleFiles()
+
+	for _, path := range stale {
+		if !w.dryRun {
+			err := os.Remove(filepath.Join(w.outputPath, filepath.FromSlash(path)))
+			if err != nil && !os.IsNotExist(err) {
+				return nil, fmt.Errorf("failed to remove stale file %s: %w", path, err)
+			}
+			w.removeEmptyDirs(path)
+		}
+		w.result.Deleted++
+		w.result.DeletedFiles = append(w.result.DeletedFiles, path)
+	}
+
+	if w.dryRun {
+		result := w.result
+		return &result, nil
+	}
+
+	manifest := buildManifest{GeneratedAt: w.startedAt.UTC(), Files: w.current}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode build manifest: %w", err)
+	}
+	if err := writeFileAtomic(filepath.Join(w.outputPath, ManifestFile), data); err != nil {
+		return nil, fmt.Errorf("failed to write build manifest: %w", err)
+	}
+
+	result := w.result
+	return &result, nil
+}
+
+// staleFiles lists files the previous build produced that this build did not, according to the
+// previous manifest. Files no manifest recorded, such as pages placed in the output directory by hand,
+// are never included, nor are files in protected directories.
+func (w *siteWriter) staleFiles() []string {
+	var paths []string
+	for path := range w.previous {
+		if _, ok := w.current[path]; !ok && !isProtectedPath(path) {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// removeEmptyDirs removes the directories containing a deleted file if they are now empty,
+// stopping at the output directory
+func (w *siteWriter) removeEmptyDirs(path string) {
+	for dir := filepath.Dir(filepath.FromSlash(path)); dir != "." && dir != string(filepath.Separator); dir = filepath.Dir(dir) {
+		// os.Remove fails on non-empty directories, which ends the walk up
+		if err := os.Remove(filepath.Join(w.outputPath, dir)); err != nil {
+			return
+		}
+	}
+}
+
+// isProtectedPath reports whether a slash-separated output path lies inside a protected directory
+func isProtectedPath(path string) bool {
+	for _, dir := range protectedDirs {
+		if path == dir || strings.HasPrefix(path, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames it into place,
+// so readers never observe a partially written file
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpName)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	if err := os.Chmod(tmpName, 0644); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	return nil
+}
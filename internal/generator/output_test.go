@@ -0,0 +1,188 @@
+package generator
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newTestWriter creates a site writer for the output directory, failing the test on error
+func newTestWriter(t *testing.T, outputPath string) *siteWriter {
+	t.Helper()
+	w, err := newSiteWriter(outputPath, BuildOptions{})
+	if err != nil {
+		t.Fatalf("newSiteWriter failed: %v", err)
+	}
+	return w
+}
+
+func TestSiteWriterIncremental(t *testing.T) {
+	outputPath := t.TempDir()
+
+	// First build writes everything
+	w := newTestWriter(t, outputPath)
+	for path, content := range map[string]string{"a.html": "A", "b.html": "B", "tags/go.html": "Go"} {
+		if err := w.writeFile(path, []byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	result, err := w.finish()
+	if err != nil {
+		t.Fatalf("finish failed: %v", err)
+	}
+	if result.Written != 3 || result.Skipped != 0 || result.Deleted != 0 {
+		t.Errorf("Unexpected first build result: %+v", result)
+	}
+
+	info, err := os.Stat(filepath.Join(outputPath, "a.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	modTime := info.ModTime()
+
+	// Second build changes one file, keeps one and drops one
+	w = newTestWriter(t, outputPath)
+	if err := w.writeFile("a.html", []byte("A")); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.writeFile("b.html", []byte("B2")); err != nil {
+		t.Fatal(err)
+	}
+	result, err = w.finish()
+	if err != nil {
+		t.Fatalf("finish failed: %v", err)
+	}
+	if result.Written != 1 || result.Skipped != 1 || result.Deleted != 1 {
+		t.Errorf("Unexpected second build result: %+v", result)
+	}
+	if len(result.DeletedFiles) != 1 || result.DeletedFiles[0] != "tags/go.html" {
+		t.Errorf("Expected tags/go.html to be deleted, got %v", result.DeletedFiles)
+	}
+
+	info, err = os.Stat(filepath.Join(outputPath, "a.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.ModTime().Equal(modTime) {
+		t.Error("Unchanged file should not be rewritten")
+	}
+	content, _ := os.ReadFile(filepath.Join(outputPath, "b.html"))
+	if string(content) != "B2" {
+		t.Errorf("Expected changed content 'B2', got '%s'", content)
+	}
+	if _, err := os.Stat(filepath.Join(outputPath, "tags", "go.html")); !os.IsNotExist(err) {
+		t.Error("Stale file should be removed")
+	}
+
+	// A file removed outside the generator is rewritten even though its hash is unchanged
+	os.Remove(filepath.Join(outputPath, "a.html"))
+	w = newTestWriter(t, outputPath)
+	w.writeFile("a.html", []byte("A"))
+	w.writeFile("b.html", []byte("B2"))
+	result, err = w.finish()
+	if err != nil {
+		t.Fatalf("finish failed: %v", err)
+	}
+	if result.Written != 1 || result.Skipped != 1 {
+		t.Errorf("Expected missing file to be rewritten, got %+v", result)
+	}
+}
+
+func TestSiteWriterStaleFiles(t *testing.T) {
+	outputPath := t.TempDir()
+
+	// Leftovers from the previous build, uploads and files placed by hand
+	files := map[string]string{
+		"old-slug.html":         "old",
+		"tags/removed.html":     "old tag",
+		"images/photo.jpg":      "jpg",
+		"images/embed.html":     "uploaded html",
+		"notes.txt":             "not generated",
+		"posts/page/2.html":     "old page",
+		".well-known/site.html": "hidden",
+		"google1234.html":       "site verification",
+		"legacy/about.html":     "legacy page",
+	}
+	for path, content := range files {
+		fullPath := filepath.Join(outputPath, filepath.FromSlash(path))
+		os.MkdirAll(filepath.Dir(fullPath), 0755)
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Only the files the previous build recorded are its to remove
+	previous, _ := json.Marshal(buildManifest{Files: map[string]string{
+		"index.html":        "old hash",
+		"old-slug.html":     "hash",
+		"tags/removed.html": "hash",
+		"posts/page/2.html": "hash",
+		"images/embed.html": "hash",
+	}})
+	if err := os.WriteFile(filepath.Join(outputPath, ManifestFile), previous, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	build := func(opts BuildOptions) *BuildResult {
+		w, err := newSiteWriter(outputPath, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := w.writeFile("index.html", []byte("index")); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.writeFile("tags/go.html", []byte("go")); err != nil {
+			t.Fatal(err)
+		}
+		result, err := w.finish()
+		if err != nil {
+			t.Fatalf("finish failed: %v", err)
+		}
+		return result
+	}
+
+	expected := []string{"old-slug.html", "posts/page/2.html", "tags/removed.html"}
+
+	// A dry run reports the orphans without touching the output directory
+	result := build(BuildOptions{DryRun: true})
+	if !result.DryRun || result.Written != 2 {
+		t.Errorf("Unexpected dry run result: %+v", result)
+	}
+	if len(result.DeletedFiles) != len(expected) {
+		t.Fatalf("Expected %v to be listed for removal, got %v", expected, result.DeletedFiles)
+	}
+	for i, path := range expected {
+		if result.DeletedFiles[i] != path {
+			t.Errorf("Expected %s at position %d, got %s", path, i, result.DeletedFiles[i])
+		}
+	}
+	if _, err := os.Stat(filepath.Join(outputPath, "old-slug.html")); err != nil {
+		t.Error("Dry run should not remove files")
+	}
+	if _, err := os.Stat(filepath.Join(outputPath, "index.html")); !os.IsNotExist(err) {
+		t.Error("Dry run should not write files")
+	}
+	if data, _ := os.ReadFile(filepath.Join(outputPath, ManifestFile)); string(data) != string(previous) {
+		t.Error("Dry run should not write a manifest")
+	}
+
+	// A real build removes the orphans and their empty directories
+	result = build(BuildOptions{})
+	if result.Deleted != len(expected) {
+		t.Errorf("Expected %d files removed, got %+v", len(expected), result)
+	}
+	for _, path := range expected {
+		if _, err := os.Stat(filepath.Join(outputPath, filepath.FromSlash(path))); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be removed", path)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(outputPath, "posts")); !os.IsNotExist(err) {
+		t.Error("Expected empty posts directory to be removed")
+	}
+	for _, path := range []string{"images/photo.jpg", "images/embed.html", "notes.txt", ".well-known/site.html", "tags/go.html", "google1234.html", "legacy/about.html"} {
+		if _, err := os.Stat(filepath.Join(outputPath, filepath.FromSlash(path))); err != nil {
+			t.Errorf("Expected %s to be kept", path)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package generator
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"path/filepath"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/models"
+	"github.com/ariefbayu/personal-blog-generator/internal/repository"
+)
+
+// RenderPostPreview renders a single post, published or not, through the header, post and footer
+// templates with the same navigation as the generated site. Nothing is written to the output directory.
+func RenderPostPreview(w io.Writer, post *models.Post, pageRepo *repository.PageRepository, settingsRepo *repository.SettingsRepository, templatePath string) error {
+	navData, err := previewNavigationData(pageRepo, settingsRepo)
+	if err != nil {
+		return err
+	}
+
+	tmpl, err := template.ParseFiles(
+		filepath.Join(templatePath, "header.html"),
+		filepath.Join(templatePath, "post.html"),
+		filepath.Join(templatePath, "footer.html"),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to parse post templates: %w", err)
+	}
+
+	return executeLayout(w, tmpl, "post.html", buildPostData(*post, navData))
+}
+
+// RenderPagePreview renders a single page through the header, page and footer templates
+// with the same navigation as the generated site. Nothing is written to the output directory.
+func RenderPagePreview(w io.Writer, page *models.Page, pageRepo *repository.PageRepository, settingsRepo *repository.SettingsRepository, templatePath string) error {
+	navData, err := previewNavigationData(pageRepo, settingsRepo)
+	if err != nil {
+		return err
+	}
+
+	tmpl, err := template.ParseFiles(
+		filepath.Join(templatePath, "header.html"),
+		filepath.Join(templatePath, "page.html"),
+		filepath.Join(templatePath, "footer.html"),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to parse page template: %w", err)
+	}
+
+	return executeLayout(w, tmpl, "page.html", buildPageData(*page, navData))
+}
+
+// previewNavigationData builds the navigation shown on every generated page
+func previewNavigationData(pageRepo *repository.PageRepository, settingsRepo *repository.SettingsRepository) (NavigationData, error) {
+	settings, err := settingsRepo.GetSettings()
+	if err != nil {
+		return NavigationData{}, fmt.Errorf("failed to get settings: %w", err)
+	}
+
+	navLinks, err := buildNavigationData(pageRepo, settings)
+	if err != nil {
+		return NavigationData{}, fmt.Errorf("failed to build navigation data: %w", err)
+	}
+
+	return NavigationData{NavLinks: navLinks, SiteName: settings.SiteName, FeedsEnabled: feedsEnabled(settings)}, nil
+}
+
+// executeLayout executes the header, content and footer templates in sequence
+func executeLayout(w io.Writer, tmpl *template.Template, content string, data interface{}) error {
+	if err := tmpl.ExecuteTemplate(w, "header.html", data); err != nil {
+		return fmt.Errorf("failed to execute header template: %w", err)
+	}
+	if err := tmpl.ExecuteTemplate(w, content, data); err != nil {
+		return fmt.Errorf("failed to execute %s template: %w", content, err)
+	}
+	if err := tmpl.ExecuteTemplate(w, "footer.html", data); err != nil {
+		return fmt.Errorf("failed to execute footer template: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package generator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// templateSet parses the site's templates once per build. The header and footer are parsed first,
+// and each content template is parsed into its own copy of them the first time it is needed.
+type templateSet struct {
+	templatePath string
+	base         *template.Template
+	parsed       map[string]*template.Template
+}
+
+func newTemplateSet(templatePath string) *templateSet {
+	return &templateSet{templatePath: templatePath, parsed: make(map[string]*template.Template)}
+}
+
+// has reports whether the content template exists, for templates older template sets may lack
+func (s *templateSet) has(content string) bool {
+	return hasTemplate(s.templatePath, content)
+}
+
+// get returns the header, content and footer templates for content, parsing content if it has not been yet.
+// It is called while queueing pages, not by the render workers.
+func (s *templateSet) get(content string) (*template.Template, error) {
+	if tmpl, ok := s.parsed[content]; ok {
+		return tmpl, nil
+	}
+
+	if s.base == nil {
+		base, err := template.ParseFiles(
+			filepath.Join(s.templatePath, "header.html"),
+			filepath.Join(s.templatePath, "footer.html"),
+		)
+		if err != nil {
+			return nil, err
+		}
+		s.base = base
+	}
+
+	// The base is never executed, so it can be cloned for every content template
+	tmpl, err := s.base.Clone()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tmpl.ParseFiles(filepath.Join(s.templatePath, content)); err != nil {
+		return nil, err
+	}
+	s.parsed[content] = tmpl
+	return tmpl, nil
+}
+
+// renderJob is a page waiting to be rendered. Its data is prepared by the worker rendering it,
+// so expensive work such as converting markdown runs in parallel too.
+type renderJob struct {
+	// name describes the page in errors, such as "post hello-world"
+	name    string
+	relPath string
+	tmpl    *template.Template
+	content string
+	data    func() interface{}
+}
+
+// renderer queues the pages of a build and renders them with a bounded pool of workers
+type renderer struct {
+	w         *siteWriter
+	templates *templateSet
+	jobs      []renderJob
+	// listed holds the pages to include in the sitemap
+	listed []sitemapPage
+}
+
+func newRenderer(w *siteWriter, templates *templateSet) *renderer {
+	return &renderer{w: w, templates: templates}
+}
+
+// add queues a page rendered from the header, content and footer templates into relPath
+func (r *renderer) add(name, relPath string, tmpl *template.Template, content string, data func() interface{}) {
+	r.jobs = append(r.jobs, renderJob{name: name, relPath: relPath, tmpl: tmpl, content: content, data: data})
+}
+
+// list adds a queued page to the sitemap, dated by when its content last changed
+func (r *renderer) list(relPath string, lastMod time.Time) {
+	r.listed = append(r.listed, sitemapPage{relPath: relPath, lastMod: lastMod})
+}
+
+// pending returns the number of queued pages
+func (r *renderer) pending() int {
+	return len(r.jobs)
+}
+
+// run renders the queued pages using up to workers goroutines, runtime.GOMAXPROCS(0) when workers is zero or less.
+// A page that fails does not stop the others. Failures are recorded in the build result in queue order,
+// and the returned error lists them too.
+func (r *renderer) run(workers int) error {
+	jobs := r.jobs
+	r.jobs = nil
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	workers = min(workers, len(jobs))
+
+	errs := make([]error, len(jobs))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				errs[i] = r.render(jobs[i])
+			}
+		}()
+	}
+	for i := range jobs {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	failed := 0
+	for i, err := range errs {
+		if err != nil {
+			r.w.fail(jobs[i].relPath, err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d pages failed to render:\n%w", failed, len(jobs), errors.Join(errs...))
+	}
+	return nil
+}
+
+// render renders one page. A panic while preparing its data fails only that page.
+func (r *renderer) render(job renderJob) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("failed to generate %s: panic: %v", job.name, p)
+		}
+	}()
+
+	var buf bytes.Buffer
+	if err := executeLayout(&buf, job.tmpl, job.content, job.data()); err != nil {
+		return fmt.Errorf("failed to generate %s: %w", job.name, err)
+	}
+	if err := r.w.writeFile(job.relPath, buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to generate %s: %w", job.name, err)
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/db"
+	"github.com/ariefbayu/personal-blog-generator/internal/models"
+	"github.com/ariefbayu/personal-blog-generator/internal/repository"
+)
+
+func newTestRenderer(t *testing.T, templatePath, outputPath string) *renderer {
+	t.Helper()
+	return newRenderer(newTestWriter(t, outputPath), newTemplateSet(templatePath))
+}
+
+func writeTestTemplates(t *testing.T, templatePath string, templates map[string]string) {
+	t.Helper()
+	for name, content := range templates {
+		if err := os.WriteFile(filepath.Join(templatePath, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestTemplateSetParsesOnce(t *testing.T) {
+	templatePath := t.TempDir()
+	writeTestTemplates(t, templatePath, map[string]string{
+		"header.html": `<title>{{.Title}}</title>`,
+		"footer.html": `</html>`,
+		"post.html":   `<h1>{{.Title}}</h1>`,
+		"page.html":   `<h2>{{.Title}}</h2>`,
+	})
+	templates := newTemplateSet(templatePath)
+
+	post, err := templates.get("post.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Changing a file after it was parsed has no effect on the build
+	writeTestTemplates(t, templatePath, map[string]string{"post.html": `{{.Broken`})
+	again, err := templates.get("post.html")
+	if err != nil || again != post {
+		t.Errorf("Expected the parsed post template to be reused, got %v", err)
+	}
+
+	page, err := templates.get("page.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Lookup("post.html") != nil {
+		t.Error("Content templates should not share a template set")
+	}
+	if _, err := templates.get("missing.html"); err == nil {
+		t.Error("Expected an error for a missing template")
+	}
+}
+
+func TestRendererReportsEveryFailure(t *testing.T) {
+	templatePath := t.TempDir()
+	outputPath := t.TempDir()
+	writeTestTemplates(t, templatePath, map[string]string{
+		"header.html": `<html><body>`,
+		"footer.html": `</body></html>`,
+		// Posts without tags fail to render
+		"post.html": `<h1>{{.Title}}</h1><p>Filed under {{index .Tags 0}}</p>`,
+	})
+
+	var posts []models.Post
+	for i := 1; i <= 20; i++ {
+		post := models.Post{Title: fmt.Sprintf("Post %d", i), Slug: fmt.Sprintf("post-%d", i), Tags: "go"}
+		if i == 7 || i == 15 {
+			post.Tags = ""
+		}
+		posts = append(posts, post)
+	}
+
+	r := newTestRenderer(t, templatePath, outputPath)
+	if err := generatePosts(r, posts, NavigationData{}); err != nil {
+		t.Fatal(err)
+	}
+	err := r.run(4)
+	if err == nil {
+		t.Fatal("Expected the broken posts to fail")
+	}
+	msg := err.Error()
+	if !strings.Contains(msg, "2 of 20 pages failed") || !strings.Contains(msg, "failed to generate post post-7:") || !strings.Contains(msg, "failed to generate post post-15:") {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if strings.Index(msg, "post-7:") > strings.Index(msg, "post-15:") {
+		t.Errorf("Failures should be listed in queue order: %v", err)
+	}
+
+	for _, post := range posts {
+		_, err := os.Stat(filepath.Join(outputPath, post.Slug+".html"))
+		if broken := post.Tags == ""; broken != os.IsNotExist(err) {
+			t.Errorf("Unexpected state of %s.html: %v", post.Slug, err)
+		}
+	}
+	if r.w.result.Written != 18 || len(r.w.current) != 18 {
+		t.Errorf("Expected 18 written files, got %+v", r.w.result)
+	}
+	failed := r.w.result.Failed
+	if len(failed) != 2 || !strings.HasPrefix(failed[0], "failed to generate post post-7:") || !strings.HasPrefix(failed[1], "failed to generate post post-15:") {
+		t.Errorf("Expected both failures in the build result, got %v", failed)
+	}
+}
+
+// BenchmarkGenerateStaticSite builds a site of a few thousand posts from the bundled templates,
+// with one render worker and with the default pool
+func BenchmarkGenerateStaticSite(b *testing.B) {
+	testDB, err := db.Connect(":memory:")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer testDB.Close()
+	if err := db.Migrate(testDB); err != nil {
+		b.Fatal(err)
+	}
+
+	postRepo := repository.NewPostRepository(testDB)
+	tags := []string{"go", "web", "databases", "testing", "tools"}
+	content := strings.Repeat("Some **bold** text, a [link](https://example.com) and `code`.\n\n", 20) +
+		"## A heading\n\n- one\n- two\n- three\n\n```go\nfmt.Println(\"hello\")\n```\n"
+	created := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 3000; i++ {
+		post := &models.Post{
+			Title:     fmt.Sprintf("Synthetic post %d", i),
+			Slug:      fmt.Sprintf("synthetic-post-%d", i),
+			Content:   content,
+			Tags:      tags[i%len(tags)] + ", " + tags[(i+2)%len(tags)],
+			Published: true,
+			CreatedAt: created.Add(time.Duration(i) * time.Hour),
+		}
+		if err := postRepo.CreatePost(post); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	portfolioRepo := repository.NewPortfolioRepository(testDB)
+	pageRepo := repository.NewPageRepository(testDB)
+	settingsRepo := repository.NewSettingsRepository(testDB)
+	templatePath, err := filepath.Abs("../../templates")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for _, workers := range []int{1, 0} {
+		name := fmt.Sprintf("workers=%d", workers)
+		if workers == 0 {
+			name = "workers=default"
+		}
+		b.Run(name, func(b *testing.B) {
+			// A dry run measures rendering rather than the speed of the disk
+			opts := BuildOptions{DryRun: true, Workers: workers}
+			outputPath := b.TempDir()
+			for b.Loop() {
+				if _, err := GenerateStaticSiteWithOptions(postRepo, portfolioRepo, pageRepo, settingsRepo, templatePath, outputPath, opts); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/models"
+	"github.com/ariefbayu/personal-blog-generator/internal/repository"
+	"github.com/ariefbayu/personal-blog-generator/internal/utils"
+)
+
+// searchIndexFile is the search index read by search.js on the search page
+const searchIndexFile = "search-index.json"
+
+// searchPageFile is where the search page is written. Posts and pages are written to <slug>.html,
+// so a page in its own directory cannot be taken by a post slugged "search".
+const searchPageFile = "search/index.html"
+
+// searchTextLimit is the maximum number of characters of body text kept per entry,
+// which keeps the index small enough to download on every search
+const searchTextLimit = 5000
+
+// SearchEntry is a post or page in the search index
+type SearchEntry struct {
+	Title string   `json:"title"`
+	Slug  string   `json:"slug"`
+	Tags  []string `json:"tags,omitempty"`
+	Text  string   `json:"text"`
+	Date  string   `json:"date,omitempty"`
+}
+
+// SearchData represents data for the search page template
+type SearchData struct {
+	Title string
+	NavigationData
+}
+
+// generateSearchIndex writes search-index.json with the plain text of all published posts and pages.
+// Posts come first, newest first, as the search page lists equal matches in index order.
+func generateSearchIndex(w *siteWriter, posts []models.Post, pageRepo *repository.PageRepository) error {
+	pages, err := pageRepo.GetAllPages()
+	if err != nil {
+		return fmt.Errorf("failed to query pages: %w", err)
+	}
+
+	entries := make([]SearchEntry, 0, len(posts)+len(pages))
+	for _, post := range posts {
+		entries = append(entries, SearchEntry{
+			Title: post.Title,
+			Slug:  post.Slug,
+			Tags:  utils.ParseTags(post.Tags),
+			Text:  searchText(post.Content),
+			Date:  post.CreatedAt.Format("2006-01-02"),
+		})
+	}
+	for _, page := range pages {
+		entries = append(entries, SearchEntry{
+			Title: page.Title,
+			Slug:  page.Slug,
+			Text:  searchText(page.Content),
+		})
+	}
+
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("failed to encode search index: %w", err)
+	}
+	return w.writeFile(searchIndexFile, data)
+}
+
+// searchText returns the plain text of markdown content, cut to searchTextLimit characters
+func searchText(content string) string {
+	text := plainText(content)
+	if runes := []rune(text); len(runes) > searchTextLimit {
+		text = string(runes[:searchTextLimit])
+	}
+	return text
+}
+
+// plainText renders markdown content and returns its text with whitespace collapsed
+func plainText(content string) string {
+	tokenizer := html.NewTokenizer(strings.NewReader(string(mdToHTML(content))))
+	var b strings.Builder
+	skip := 0
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return strings.Join(strings.Fields(b.String()), " ")
+		case html.StartTagToken:
+			if name, _ := tokenizer.TagName(); string(name) == "script" || string(name) == "style" {
+				skip++
+			}
+		case html.EndTagToken:
+			if name, _ := tokenizer.TagName(); (string(name) == "script" || string(name) == "style") && skip > 0 {
+				skip--
+			}
+			// Keep words in neighbouring blocks apart
+			b.WriteByte(' ')
+		case html.TextToken:
+			if skip == 0 {
+				b.Write(tokenizer.Text())
+			}
+		}
+	}
+}
+
+// generateSearchPage queues the search page, which searches search-index.json in the browser.
+// Template sets created before search existed have no search.html, in which case nothing is generated.
+func generateSearchPage(r *renderer, navData NavigationData) error {
+	if !r.templates.has("search.html") {
+		return nil
+	}
+
+	tmpl, err := r.templates.get("search.html")
+	if err != nil {
+		return fmt.Errorf("failed to parse search templates: %w", err)
+	}
+
+	searchData := SearchData{
+		Title:          "Search",
+		NavigationData: navData,
+	}
+	r.add("search page", searchPageFile, tmpl, "search.html", func() interface{} { return searchData })
+	return nil
+}
@@ -0,0 +1,108 @@
+package generator
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/models"
+	"github.com/ariefbayu/personal-blog-generator/internal/repository"
+)
+
+func TestGenerateSearchIndex(t *testing.T) {
+	testDB := setupSitemapDB(t)
+	pageRepo := repository.NewPageRepository(testDB)
+	if err := pageRepo.CreatePage(&models.Page{Title: "About", Slug: "about", Content: "About **me**."}); err != nil {
+		t.Fatal(err)
+	}
+
+	posts := []models.Post{
+		{
+			Title:     "Hello",
+			Slug:      "hello",
+			Tags:      "go, web",
+			Content:   "# Heading\n\nSome *emphasis* and [a link](/x.html).\n\n<script>alert(1)</script>\n",
+			CreatedAt: time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	outputPath := t.TempDir()
+
+	if err := generateSearchIndex(newTestWriter(t, outputPath), posts, pageRepo); err != nil {
+		t.Fatalf("generateSearchIndex failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(outputPath, searchIndexFile))
+	if err != nil {
+		t.Fatalf("%s was not created: %v", searchIndexFile, err)
+	}
+	var entries []SearchEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		t.Fatalf("Search index is not valid JSON: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(entries))
+	}
+
+	post := entries[0]
+	if post.Slug != "hello" || post.Date != "2023-01-02" || strings.Join(post.Tags, ",") != "go,web" {
+		t.Errorf("Unexpected post entry: %+v", post)
+	}
+	if post.Text != "Heading Some emphasis and a link ." {
+		t.Errorf("Expected markup to be stripped, got %q", post.Text)
+	}
+	if entries[1].Slug != "about" || entries[1].Text != "About me ." || entries[1].Date != "" {
+		t.Errorf("Unexpected page entry: %+v", entries[1])
+	}
+}
+
+func TestSearchTextLimit(t *testing.T) {
+	text := searchText(strings.Repeat("é", searchTextLimit+10))
+	if n := len([]rune(text)); n != searchTextLimit {
+		t.Errorf("Expected text cut to %d characters, got %d", searchTextLimit, n)
+	}
+}
+
+func TestGenerateSearchPage(t *testing.T) {
+	templatePath := t.TempDir()
+	outputPath := t.TempDir()
+
+	r := newTestRenderer(t, templatePath, outputPath)
+	if err := generateSearchPage(r, NavigationData{}); err != nil {
+		t.Fatalf("generateSearchPage should succeed without search.html: %v", err)
+	}
+	if r.pending() != 0 {
+		t.Error("search.html should not be queued without a search template")
+	}
+	if _, err := os.Stat(filepath.Join(outputPath, "search", "index.html")); !os.IsNotExist(err) {
+		t.Error("The search page should not be created without a search template")
+	}
+
+	templates := map[string]string{
+		"header.html": `<html><title>{{.SiteName}} - {{.Title}}</title><body>`,
+		"footer.html": `</body></html>`,
+		"search.html": `<input id="search-input"><script src="/js/search.js"></script>`,
+	}
+	for name, content := range templates {
+		if err := os.WriteFile(filepath.Join(templatePath, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r = newTestRenderer(t, templatePath, outputPath)
+	if err := generateSearchPage(r, NavigationData{SiteName: "Blog"}); err != nil {
+		t.Fatalf("generateSearchPage failed: %v", err)
+	}
+	if err := r.run(0); err != nil {
+		t.Fatalf("Rendering the search page failed: %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(outputPath, "search", "index.html"))
+	if err != nil {
+		t.Fatalf("search/index.html was not created: %v", err)
+	}
+	if !strings.Contains(string(content), "Blog - Search") || !strings.Contains(string(content), "/js/search.js") {
+		t.Errorf("Unexpected search page: %s", content)
+	}
+}
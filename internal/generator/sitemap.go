@@ -0,0 +1,84 @@
+package generator
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/repository"
+)
+
+// defaultRobotsTxt is used when no custom robots.txt content is configured
+const defaultRobotsTxt = "User-agent: *\nAllow: /\n"
+
+// sitemapURLSet represents the root element of a sitemap document
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 urlset"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+// sitemapURL represents a single URL entry in a sitemap
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// sitemapPage is a generated page listed in the sitemap
+type sitemapPage struct {
+	relPath string
+	lastMod time.Time
+}
+
+// generateSitemap writes sitemap.xml listing the pages the build produced, in the order they were queued.
+// Pages that failed or were never written are left out, as are pages not meant to be listed, such as the search page.
+// Sitemaps require absolute URLs, so nothing is written when the site base URL is not configured.
+func generateSitemap(w *siteWriter, pages []sitemapPage, settings *repository.Settings) error {
+	if strings.TrimSpace(settings.BaseURL) == "" {
+		return nil
+	}
+
+	var urlSet sitemapURLSet
+	for _, page := range pages {
+		if !w.written(page.relPath) {
+			continue
+		}
+		urlSet.URLs = append(urlSet.URLs, sitemapURL{
+			Loc:     absoluteURL(settings.BaseURL, page.relPath),
+			LastMod: sitemapDate(page.lastMod),
+		})
+	}
+
+	data, err := xml.MarshalIndent(urlSet, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to build sitemap: %w", err)
+	}
+	data = append([]byte(xml.Header), append(data, '\n')...)
+
+	return w.writeFile("sitemap.xml", data)
+}
+
+// generateRobotsTxt writes robots.txt from the configured content, referencing the sitemap when available
+func generateRobotsTxt(w *siteWriter, settings *repository.Settings) error {
+	content := settings.RobotsTxt
+	if strings.TrimSpace(content) == "" {
+		content = defaultRobotsTxt
+	}
+	if !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+
+	if strings.TrimSpace(settings.BaseURL) != "" && !strings.Contains(strings.ToLower(content), "sitemap:") {
+		content += "\nSitemap: " + absoluteURL(settings.BaseURL, "sitemap.xml") + "\n"
+	}
+
+	return w.writeFile("robots.txt", []byte(content))
+}
+
+// sitemapDate formats a time in the W3C date format used by sitemaps
+func sitemapDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format("2006-01-02")
+}
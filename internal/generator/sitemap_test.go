@@ -0,0 +1,170 @@
+package generator
+
+import (
+	"database/sql"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/db"
+	"github.com/ariefbayu/personal-blog-generator/internal/models"
+	"github.com/ariefbayu/personal-blog-generator/internal/repository"
+)
+
+func setupSitemapDB(t *testing.T) *sql.DB {
+	testDB, err := db.Connect(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to connect to test DB: %v", err)
+	}
+	if err := db.Migrate(testDB); err != nil {
+		t.Fatalf("Failed to migrate test DB: %v", err)
+	}
+	t.Cleanup(func() { testDB.Close() })
+	return testDB
+}
+
+func TestGenerateSitemap(t *testing.T) {
+	testDB := setupSitemapDB(t)
+	portfolioRepo := repository.NewPortfolioRepository(testDB)
+	pageRepo := repository.NewPageRepository(testDB)
+
+	if err := pageRepo.CreatePage(&models.Page{Title: "About", Slug: "about"}); err != nil {
+		t.Fatal(err)
+	}
+
+	templatePath := t.TempDir()
+	writeTestTemplates(t, templatePath, map[string]string{
+		"header.html":    `<html><body>`,
+		"footer.html":    `</body></html>`,
+		"index.html":     `<h1>Home</h1>`,
+		"post.html":      `<h1>{{.Title}}</h1>`,
+		"posts.html":     `<p>Page {{.CurrentPage}}</p>`,
+		"portfolio.html": `<h1>Portfolio</h1>`,
+		"page.html":      `<h1>{{.Title}}</h1>`,
+	})
+
+	posts := []models.Post{
+		{
+			Title:     "Hello",
+			Slug:      "hello",
+			CreatedAt: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+			UpdatedAt: time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			Title:     "Older",
+			Slug:      "older",
+			CreatedAt: time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	settings := &repository.Settings{BaseURL: "https://example.com"}
+	outputPath := t.TempDir()
+
+	// Build the pages the way a publish does, with one post per listing page and the portfolio menu hidden
+	r := newTestRenderer(t, templatePath, outputPath)
+	if err := generatePosts(r, posts, NavigationData{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := generateIndexPage(r, posts, portfolioRepo, NavigationData{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := generatePostsPage(r, posts, NavigationData{}, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := generatePortfolioPage(r, portfolioRepo, NavigationData{}, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := generatePages(r, pageRepo, NavigationData{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.run(0); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := generateSitemap(r.w, r.listed, settings); err != nil {
+		t.Fatalf("generateSitemap failed: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(outputPath, "sitemap.xml"))
+	if err != nil {
+		t.Fatalf("sitemap.xml was not created: %v", err)
+	}
+
+	var urlSet sitemapURLSet
+	if err := xml.Unmarshal(content, &urlSet); err != nil {
+		t.Fatalf("sitemap.xml is not valid XML: %v", err)
+	}
+
+	lastMods := make(map[string]string)
+	for _, u := range urlSet.URLs {
+		lastMods[u.Loc] = u.LastMod
+	}
+	for _, loc := range []string{
+		"https://example.com/index.html",
+		"https://example.com/posts.html",
+		"https://example.com/posts/page/2.html",
+		"https://example.com/hello.html",
+		"https://example.com/older.html",
+		"https://example.com/about.html",
+	} {
+		if _, ok := lastMods[loc]; !ok {
+			t.Errorf("Sitemap does not contain %s", loc)
+		}
+	}
+	if len(urlSet.URLs) != 6 {
+		t.Errorf("Expected 6 URLs, got %+v", urlSet.URLs)
+	}
+	if _, ok := lastMods["https://example.com/portfolio.html"]; ok {
+		t.Error("The portfolio page should not be listed while its menu is hidden")
+	}
+	if lastMods["https://example.com/hello.html"] != "2023-02-01" {
+		t.Errorf("Expected post lastmod 2023-02-01, got %s", lastMods["https://example.com/hello.html"])
+	}
+	if lastMods["https://example.com/posts/page/2.html"] != "2022-06-01" {
+		t.Errorf("Expected the second listing page dated by its post, got %s", lastMods["https://example.com/posts/page/2.html"])
+	}
+
+	// Pages that were never written are left out
+	w := newTestWriter(t, t.TempDir())
+	if err := generateSitemap(w, []sitemapPage{{relPath: "missing.html"}}, settings); err != nil {
+		t.Fatal(err)
+	}
+	urlSet = sitemapURLSet{}
+	content, _ = os.ReadFile(filepath.Join(w.outputPath, "sitemap.xml"))
+	if err := xml.Unmarshal(content, &urlSet); err != nil || len(urlSet.URLs) != 0 {
+		t.Errorf("Expected an empty sitemap, got %+v (%v)", urlSet.URLs, err)
+	}
+}
+
+func TestGenerateRobotsTxt(t *testing.T) {
+	outputPath := t.TempDir()
+
+	settings := &repository.Settings{BaseURL: "https://example.com/"}
+	if err := generateRobotsTxt(newTestWriter(t, outputPath), settings); err != nil {
+		t.Fatalf("generateRobotsTxt failed: %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(outputPath, "robots.txt"))
+	if err != nil {
+		t.Fatalf("robots.txt was not created: %v", err)
+	}
+	if !strings.Contains(string(content), "User-agent: *") {
+		t.Error("robots.txt does not contain default rules")
+	}
+	if !strings.Contains(string(content), "Sitemap: https://example.com/sitemap.xml") {
+		t.Error("robots.txt does not reference the sitemap")
+	}
+
+	settings = &repository.Settings{RobotsTxt: "User-agent: *\nDisallow: /drafts/"}
+	if err := generateRobotsTxt(newTestWriter(t, outputPath), settings); err != nil {
+		t.Fatalf("generateRobotsTxt failed: %v", err)
+	}
+	content, err = os.ReadFile(filepath.Join(outputPath, "robots.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "User-agent: *\nDisallow: /drafts/\n" {
+		t.Errorf("Unexpected robots.txt content: %q", string(content))
+	}
+}
@@ -0,0 +1,131 @@
+package generator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/models"
+	"github.com/ariefbayu/personal-blog-generator/internal/utils"
+)
+
+// TagLink represents a tag linking to its archive page
+type TagLink struct {
+	Name string
+	Slug string
+	URL  string
+}
+
+// TagItem represents a tag with its post count for the tags index page.
+// FeedURL is empty when feeds are not generated.
+type TagItem struct {
+	TagLink
+	Count   int
+	FeedURL string
+}
+
+// TagData represents data for the tag template. Tag is empty when rendering the tags index.
+type TagData struct {
+	Title string
+	Tag   *TagItem
+	Tags  []TagItem
+	Posts []PostItem
+	NavigationData
+}
+
+// tagGroup holds the posts sharing a tag slug
+type tagGroup struct {
+	name  string
+	slug  string
+	posts []models.Post
+}
+
+// buildTagLinks converts a comma-separated tag string into links to the tag archive pages
+func buildTagLinks(raw string) []TagLink {
+	var links []TagLink
+	for _, tag := range utils.ParseTags(raw) {
+		slug := utils.TagSlug(tag)
+		links = append(links, TagLink{Name: tag, Slug: slug, URL: "/tags/" + slug + ".html"})
+	}
+	return links
+}
+
+// groupPostsByTag groups posts by tag slug, keeping the first spelling of each tag as its display name.
+// Groups are sorted by tag name.
+func groupPostsByTag(posts []models.Post) []tagGroup {
+	groups := make(map[string]*tagGroup)
+	for _, post := range posts {
+		seen := make(map[string]bool)
+		for _, tag := range utils.ParseTags(post.Tags) {
+			slug := utils.TagSlug(tag)
+			if seen[slug] {
+				continue
+			}
+			seen[slug] = true
+			group, exists := groups[slug]
+			if !exists {
+				group = &tagGroup{name: tag, slug: slug}
+				groups[slug] = group
+			}
+			group.posts = append(group.posts, post)
+		}
+	}
+
+	result := make([]tagGroup, 0, len(groups))
+	for _, group := range groups {
+		result = append(result, *group)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return strings.ToLower(result[i].name) < strings.ToLower(result[j].name)
+	})
+	return result
+}
+
+// generateTagPages queues tags/index.html listing every tag and tags/<tag-slug>.html for each tag.
+// Template sets created before tag archives existed have no tag.html, in which case nothing is generated.
+func generateTagPages(r *renderer, posts []models.Post, navData NavigationData) error {
+	if !r.templates.has("tag.html") {
+		return nil
+	}
+
+	tmpl, err := r.templates.get("tag.html")
+	if err != nil {
+		return fmt.Errorf("failed to parse tag templates: %w", err)
+	}
+
+	groups := groupPostsByTag(posts)
+
+	tagItems := make([]TagItem, len(groups))
+	for i, group := range groups {
+		tagItems[i] = TagItem{
+			TagLink: TagLink{Name: group.name, Slug: group.slug, URL: "/tags/" + group.slug + ".html"},
+			Count:   len(group.posts),
+		}
+		if navData.FeedsEnabled {
+			tagItems[i].FeedURL = "/tags/" + group.slug + ".xml"
+		}
+	}
+
+	indexData := TagData{
+		Title:          "Tags",
+		Tags:           tagItems,
+		NavigationData: navData,
+	}
+	r.add("tags index", "tags/index.html", tmpl, "tag.html", func() interface{} { return indexData })
+	r.list("tags/index.html", latestPostUpdate(posts))
+
+	for i, group := range groups {
+		r.add("tag page "+group.slug, "tags/"+group.slug+".html", tmpl, "tag.html", func() interface{} {
+			return TagData{
+				Title:          group.name,
+				Tag:            &tagItems[i],
+				Tags:           tagItems,
+				Posts:          buildPostItems(group.posts),
+				NavigationData: navData,
+			}
+		})
+		r.list("tags/"+group.slug+".html", latestPostUpdate(group.posts))
+	}
+
+	return nil
+}
@@ -0,0 +1,124 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/models"
+)
+
+func TestGenerateTagPages(t *testing.T) {
+	templatePath := t.TempDir()
+	outputPath := t.TempDir()
+
+	templates := map[string]string{
+		"header.html": `<html><body>`,
+		"footer.html": `</body></html>`,
+		"tag.html": `{{if .Tag}}<h1>{{.Tag.Name}}</h1>{{range .Posts}}<a href="/{{.Slug}}.html">{{.Title}}</a>{{end}}` +
+			`{{else}}{{range .Tags}}<a href="{{.URL}}">{{.Name}} ({{.Count}})</a>{{end}}{{end}}`,
+	}
+	for name, content := range templates {
+		if err := os.WriteFile(filepath.Join(templatePath, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	posts := []models.Post{
+		{Title: "Newer", Slug: "newer", Tags: "Go, Web Dev", CreatedAt: time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{Title: "Older", Slug: "older", Tags: " go ", CreatedAt: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	r := newTestRenderer(t, templatePath, outputPath)
+	if err := generateTagPages(r, posts, NavigationData{}); err != nil {
+		t.Fatalf("generateTagPages failed: %v", err)
+	}
+	if err := r.run(0); err != nil {
+		t.Fatalf("Rendering the tag pages failed: %v", err)
+	}
+
+	indexContent, err := os.ReadFile(filepath.Join(outputPath, "tags", "index.html"))
+	if err != nil {
+		t.Fatalf("tags/index.html was not created: %v", err)
+	}
+	if !strings.Contains(string(indexContent), `<a href="/tags/go.html">Go (2)</a>`) {
+		t.Errorf("Tags index does not list the go tag with its count, got: %s", indexContent)
+	}
+	if !strings.Contains(string(indexContent), `<a href="/tags/web-dev.html">Web Dev (1)</a>`) {
+		t.Errorf("Tags index does not list the web-dev tag, got: %s", indexContent)
+	}
+
+	tagContent, err := os.ReadFile(filepath.Join(outputPath, "tags", "go.html"))
+	if err != nil {
+		t.Fatalf("tags/go.html was not created: %v", err)
+	}
+	tagStr := string(tagContent)
+	if !strings.Contains(tagStr, "/newer.html") || !strings.Contains(tagStr, "/older.html") {
+		t.Errorf("Tag page does not list all tagged posts, got: %s", tagStr)
+	}
+	if strings.Index(tagStr, "/newer.html") > strings.Index(tagStr, "/older.html") {
+		t.Error("Tag page should list newer posts first")
+	}
+}
+
+func TestGenerateTagPagesIndexTag(t *testing.T) {
+	templatePath := t.TempDir()
+	outputPath := t.TempDir()
+	writeTestTemplates(t, templatePath, map[string]string{
+		"header.html": `<html><body>`,
+		"footer.html": `</body></html>`,
+		"tag.html":    `{{if .Tag}}<h1>Tag {{.Tag.Name}}</h1>{{else}}<h1>All tags</h1>{{range .Tags}}<a href="{{.URL}}">{{.Name}}</a>{{end}}{{end}}`,
+	})
+
+	// A tag named "Index" must not take the place of the tags index
+	posts := []models.Post{{Title: "Post", Slug: "post", Tags: "Index, Go"}}
+	r := newTestRenderer(t, templatePath, outputPath)
+	if err := generateTagPages(r, posts, NavigationData{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.run(0); err != nil {
+		t.Fatal(err)
+	}
+
+	index, err := os.ReadFile(filepath.Join(outputPath, "tags", "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	url := buildTagLinks("Index")[0].URL
+	if url == "/tags/index.html" || !strings.Contains(string(index), "<h1>All tags</h1>") || !strings.Contains(string(index), `<a href="`+url+`">Index</a>`) {
+		t.Errorf("Expected the tags index linking the Index tag at its own page, got %s", index)
+	}
+	tagPage, err := os.ReadFile(filepath.Join(outputPath, filepath.FromSlash(strings.TrimPrefix(url, "/"))))
+	if err != nil || !strings.Contains(string(tagPage), "<h1>Tag Index</h1>") {
+		t.Errorf("Expected the Index tag page at %s, got %q (%v)", url, tagPage, err)
+	}
+}
+
+func TestGenerateTagPagesWithoutTemplate(t *testing.T) {
+	templatePath := t.TempDir()
+	outputPath := t.TempDir()
+
+	posts := []models.Post{{Title: "Post", Slug: "post", Tags: "go"}}
+	r := newTestRenderer(t, templatePath, outputPath)
+	if err := generateTagPages(r, posts, NavigationData{}); err != nil {
+		t.Fatalf("generateTagPages should succeed without tag.html: %v", err)
+	}
+	if err := r.run(0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(outputPath, "tags")); !os.IsNotExist(err) {
+		t.Error("tags directory should not be created without tag.html")
+	}
+}
+
+func TestBuildTagLinks(t *testing.T) {
+	links := buildTagLinks("Go, C++ Tips,, ")
+	if len(links) != 2 {
+		t.Fatalf("Expected 2 tag links, got %d", len(links))
+	}
+	if links[1].Name != "C++ Tips" || links[1].URL != "/tags/c-plus-plus-tips.html" {
+		t.Errorf("Unexpected tag link: %+v", links[1])
+	}
+}
@@ -88,6 +88,74 @@ func ServeDashboard(w http.ResponseWriter, r *http.Request) {
 		OutputPath:   OutputPath,
 		DBPath:       DBPath,
 		Scripts: template.HTML(`<script>
+        // startPublishJob starts a publish job and shows its progress on the button until it finishes.
+        // It resolves with the finished job.
+        async function startPublishJob(url, btn, label) {
+            const response = await fetch(url, {
+                method: 'POST',
+                headers: {
+                    'Content-Type': 'application/json'
+                }
+            });
+            const job = await response.json();
+            if (!response.ok && response.status !== 202) {
+                throw new Error(job.error);
+            }
+
+            return new Promise((resolve, reject) => {
+                const events = new EventSource(job.events);
+                events.addEventListener('step', function(e) {
+                    const step = JSON.parse(e.data).step;
+                    btn.innerHTML = '<span class="material-symbols-outlined">refresh</span><span>' + label + ': ' + escapeHtml(step) + '</span>';
+                });
+                events.addEventListener('done', function(e) {
+                    events.close();
+                    resolve(JSON.parse(e.data));
+                });
+                events.onerror = function() {
+                    // EventSource reconnects on its own unless the job is gone
+                    if (events.readyState === EventSource.CLOSED) {
+                        reject(new Error('Lost the connection to the publish job'));
+                    }
+                };
+            });
+        }
+
+        function escapeHtml(text) {
+            const div = document.createElement('div');
+            div.textContent = text;
+            return div.innerHTML;
+        }
+
+        async function loadPublishJobs() {
+            const list = document.getElementById('publish-jobs-list');
+            try {
+                const response = await fetch('/api/publish/jobs?limit=10');
+                const jobs = await response.json();
+                if (!response.ok) {
+                    throw new Error('Failed to load publish history');
+                }
+                if (jobs.length === 0) {
+                    list.innerHTML = '<p class="text-muted">Nothing has been published yet.</p>';
+                    return;
+                }
+                list.innerHTML = jobs.map(function(job) {
+                    const kind = job.dry_run ? 'Dry run' : 'Publish';
+                    const started = new Date(job.started_at).toLocaleString();
+                    const duration = job.status === 'running' ? 'running' : (job.duration_ms / 1000).toFixed(1) + 's';
+                    const detail = job.status === 'failed'
+                        ? escapeHtml(job.error)
+                        : job.written + ' written, ' + job.skipped + ' unchanged, ' + job.deleted + ' removed';
+                    return '<div class="token-item"><div><strong>' + kind + '</strong> ' + escapeHtml(job.status) +
+                        ' (' + escapeHtml(job.source) + ')<br><small class="text-muted">' + started + ' · ' + duration +
+                        ' · ' + detail + '</small></div></div>';
+                }).join('');
+            } catch (error) {
+                console.error('Publish history error:', error);
+                list.innerHTML = '<p class="text-muted">Failed to load publish history.</p>';
+            }
+        }
+
         document.getElementById('publish-site-btn').addEventListener('click', async function() {
             const btn = this;
             const originalText = btn.innerHTML;
@@ -95,7 +163,61 @@ func ServeDashboard(w http.ResponseWriter, r *http.Request) {
             btn.disabled = true;
 
             try {
-                const response = await fetch('/api/publish', {
+                const job = await startPublishJob('/api/publish', btn, 'Publishing');
+                if (job.status === 'succeeded') {
+                    alert('Site published successfully in ' + (job.duration_ms / 1000).toFixed(1) + 's.\n' +
+                        job.written + ' files written, ' + job.skipped + ' unchanged, ' + job.deleted + ' removed.');
+                } else {
+                    alert('Publish failed: ' + job.error);
+                }
+            } catch (error) {
+                console.error('Publish error:', error);
+                alert('Publish failed: ' + error.message);
+            } finally {
+                btn.innerHTML = originalText;
+                btn.disabled = false;
+                loadPublishJobs();
+            }
+        });
+
+        document.getElementById('publish-dry-run-btn').addEventListener('click', async function() {
+            const btn = this;
+            const originalText = btn.innerHTML;
+            btn.disabled = true;
+
+            try {
+                const job = await startPublishJob('/api/publish?dry_run=true', btn, 'Dry run');
+                if (job.status === 'succeeded') {
+                    let message = 'Publishing would write ' + job.written + ' files and leave ' + job.skipped + ' unchanged.';
+                    if (job.deleted_files && job.deleted_files.length > 0) {
+                        message += '\n\nThese stale files would be removed:\n' + job.deleted_files.join('\n');
+                    } else {
+                        message += '\n\nNo stale files would be removed.';
+                    }
+                    alert(message);
+                } else {
+                    alert('Dry run failed: ' + job.error);
+                }
+            } catch (error) {
+                console.error('Dry run error:', error);
+                alert('Dry run failed: ' + error.message);
+            } finally {
+                btn.innerHTML = originalText;
+                btn.disabled = false;
+                loadPublishJobs();
+            }
+        });
+
+        document.getElementById('publish-rollback-btn').addEventListener('click', async function() {
+            if (!confirm('Put the previously published build back live?')) {
+                return;
+            }
+
+            const btn = this;
+            btn.disabled = true;
+
+            try {
+                const response = await fetch('/api/publish/rollback', {
                     method: 'POST',
                     headers: {
                         'Content-Type': 'application/json'
@@ -105,18 +227,54 @@ func ServeDashboard(w http.ResponseWriter, r *http.Request) {
                 const result = await response.json();
 
                 if (response.ok) {
-                    alert('Site published successfully! Generated ' + result.count + ' post pages.');
+                    alert(result.message);
                 } else {
-                    alert('Publish failed: ' + result.error);
+                    alert(result.error);
                 }
             } catch (error) {
-                console.error('Publish error:', error);
+                console.error('Rollback error:', error);
+                alert('Network error. Please try again.');
+            } finally {
+                btn.disabled = false;
+            }
+        });
+
+        document.getElementById('deploy-site-btn').addEventListener('click', async function() {
+            if (!confirm('Publish the site and upload the changes to the server?')) {
+                return;
+            }
+
+            const btn = this;
+            const originalText = btn.innerHTML;
+            btn.innerHTML = '<span class="material-symbols-outlined">refresh</span><span>Deploying...</span>';
+            btn.disabled = true;
+
+            try {
+                const response = await fetch('/api/deploy', {
+                    method: 'POST',
+                    headers: {
+                        'Content-Type': 'application/json'
+                    }
+                });
+
+                const result = await response.json();
+
+                if (response.ok) {
+                    alert(result.message);
+                } else {
+                    alert('Deploy failed: ' + result.error);
+                }
+            } catch (error) {
+                console.error('Deploy error:', error);
                 alert('Network error. Please try again.');
             } finally {
                 btn.innerHTML = originalText;
                 btn.disabled = false;
+                loadPublishJobs();
             }
         });
+
+        loadPublishJobs();
     </script>`),
 	}
 
@@ -176,7 +334,7 @@ func ServeEditPostPage(w http.ResponseWriter, r *http.Request) {
 		ActiveNav: "posts",
 		Content:   content,
 		ExtraHead: template.HTML(`<link rel="stylesheet" href="/admin/vendor/easymde.min.css">`),
-		Scripts:   template.HTML(`<script src="/admin/vendor/easymde.min.js"></script><script src="/admin/js/post_form.js"></script>`),
+		Scripts:   template.HTML(`<script src="/admin/vendor/easymde.min.js"></script><script src="/admin/js/post_form.js"></script><script src="/admin/js/revisions.js"></script>`),
 	}
 
 	if err := renderAdminPage(w, data); err != nil {
@@ -294,7 +452,7 @@ func ServeEditPagePage(w http.ResponseWriter, r *http.Request) {
 		ActiveNav: "pages",
 		Content:   content,
 		ExtraHead: template.HTML(`<link rel="stylesheet" href="/admin/vendor/easymde.min.css">`),
-		Scripts:   template.HTML(`<script src="/admin/vendor/easymde.min.js"></script><script src="/admin/js/page_form.js"></script>`),
+		Scripts:   template.HTML(`<script src="/admin/vendor/easymde.min.js"></script><script src="/admin/js/page_form.js"></script><script src="/admin/js/revisions.js"></script>`),
 	}
 
 	if err := renderAdminPage(w, data); err != nil {
@@ -313,6 +471,7 @@ func ServeSettingsPage(w http.ResponseWriter, r *http.Request) {
 		Title:     "Settings",
 		ActiveNav: "settings",
 		Content:   content,
+		Scripts:   template.HTML(`<script src="/admin/js/tokens.js"></script><script src="/admin/js/import.js"></script><script src="/admin/js/backups.js"></script>`),
 	}
 
 	if err := renderAdminPage(w, data); err != nil {
@@ -3,13 +3,14 @@ package handlers
 import (
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"strings"
 	"testing"
 )
 
 func TestServeDashboard(t *testing.T) {
-	// Set ADMIN_FILES_PATH to project directory for testing
-	AdminFilesPath = "../../../personal-blog-generator/admin-files"
+	// Serve admin files from the project directory for testing
+	AdminFS = os.DirFS("../../admin-files")
 
 	req := httptest.NewRequest("GET", "/admin/dashboard", nil)
 	w := httptest.NewRecorder()
@@ -2,8 +2,9 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"io"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -11,9 +12,10 @@ import (
 	"strings"
 	"time"
 
-	"github.com/ariefbayu/personal-blog-generator/internal/generator"
 	"github.com/ariefbayu/personal-blog-generator/internal/models"
+	"github.com/ariefbayu/personal-blog-generator/internal/publish"
 	"github.com/ariefbayu/personal-blog-generator/internal/repository"
+	"github.com/ariefbayu/personal-blog-generator/internal/utils"
 )
 
 type FileNode struct {
@@ -93,7 +95,28 @@ func (h *APIHandlers) GetPostsHandler(w http.ResponseWriter, r *http.Request) {
 
 	offset := (page - 1) * limit
 
-	posts, total, err := h.postRepo.GetPostsPaginated(limit, offset)
+	query := r.URL.Query()
+	filter := repository.PostFilter{
+		Query: strings.TrimSpace(query.Get("q")),
+		Tag:   strings.TrimSpace(query.Get("tag")),
+		From:  query.Get("from"),
+		To:    query.Get("to"),
+		Sort:  query.Get("sort"),
+	}
+	if published := query.Get("published"); published != "" {
+		value, err := strconv.ParseBool(published)
+		if err != nil {
+			http.Error(w, "published must be true or false", http.StatusBadRequest)
+			return
+		}
+		filter.Published = &value
+	}
+	if err := filter.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	posts, total, err := h.postRepo.GetPostsPaginated(limit, offset, filter)
 	if err != nil {
 		http.Error(w, "Failed to fetch posts", http.StatusInternalServerError)
 		return
@@ -210,63 +233,59 @@ func (h *APIHandlers) DeletePostHandler(w http.ResponseWriter, r *http.Request)
 	w.WriteHeader(http.StatusNoContent)
 }
 
-func (h *APIHandlers) PublishSiteHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	// Get paths from environment variables
-	templatePath := os.Getenv("TEMPLATE_PATH")
-	if templatePath == "" {
-		homeDir, err := os.UserHomeDir()
-		if err != nil {
-			templatePath = "./templates" // fallback
-		} else {
-			templatePath = filepath.Join(homeDir, ".personal-blog-generator", "templates")
-		}
+// PublishRollbackHandler makes an earlier build live again.
+// The optional JSON body {"build": "<id>"} selects the build, otherwise the previous one is used.
+func (h *APIHandlers) PublishRollbackHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Build string `json:"build"`
 	}
-	log.Printf("DEBUG: templatePath = %s", templatePath)
-	outputPath := os.Getenv("OUTPUT_PATH")
-	if outputPath == "" {
-		homeDir, err := os.UserHomeDir()
-		if err != nil {
-			outputPath = "./html-outputs" // fallback
-		} else {
-			outputPath = filepath.Join(homeDir, "html-outputs")
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+			http.Error(w, "Invalid JSON", http.StatusBadRequest)
+			return
 		}
 	}
 
-	// Generate the static site
-	err := generator.GenerateStaticSite(h.postRepo, h.portfolioRepo, h.pageRepo, h.settingsRepo, templatePath, outputPath)
+	build, err := h.site().Rollback(req.Build)
 	if err != nil {
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{"error": fmt.Sprintf("Generation failed: %s", err.Error())})
+		switch {
+		case errors.Is(err, publish.ErrBuildNotFound):
+			w.WriteHeader(http.StatusNotFound)
+		case errors.Is(err, publish.ErrNoPreviousBuild):
+			w.WriteHeader(http.StatusConflict)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		json.NewEncoder(w).Encode(map[string]string{"error": fmt.Sprintf("Rollback failed: %s", err.Error())})
 		return
 	}
 
-	// Count published posts for the response
-	posts, err := h.postRepo.GetAllPosts()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Rolled back to build " + build.ID,
+		"build":   build,
+	})
+}
+
+// GetPublishBuildsHandler lists the builds available for rollback, oldest first
+func (h *APIHandlers) GetPublishBuildsHandler(w http.ResponseWriter, r *http.Request) {
+	builds, err := h.site().Builds()
 	if err != nil {
-		// If counting fails, just return success without count
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]string{"message": "Site generated successfully"})
+		http.Error(w, "Failed to list builds", http.StatusInternalServerError)
 		return
 	}
-
-	publishedCount := 0
-	for _, post := range posts {
-		if post.Published {
-			publishedCount++
-		}
+	if builds == nil {
+		builds = []publish.Build{}
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"message": "Site generated successfully",
-		"count":   publishedCount,
-	})
+	json.NewEncoder(w).Encode(builds)
+}
+
+// site returns the publisher for the configured template and output paths
+func (h *APIHandlers) site() *publish.Site {
+	return publish.NewSite(h.postRepo, h.portfolioRepo, h.pageRepo, h.settingsRepo, utils.GetTemplatePath(), utils.GetOutputPath())
 }
 
 func (h *APIHandlers) GetSettingsHandler(w http.ResponseWriter, r *http.Request) {
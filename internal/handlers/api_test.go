@@ -6,9 +6,13 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/ariefbayu/personal-blog-generator/internal/db"
+	"github.com/ariefbayu/personal-blog-generator/internal/generator"
 	"github.com/ariefbayu/personal-blog-generator/internal/models"
 	"github.com/ariefbayu/personal-blog-generator/internal/repository"
 )
@@ -173,6 +177,62 @@ func TestCreatePostHandler(t *testing.T) {
 	}
 }
 
+func TestScheduledPostHandler(t *testing.T) {
+	testDB := setupTestDB(t)
+	defer testDB.Close()
+
+	postRepo := repository.NewPostRepository(testDB)
+	portfolioRepo := repository.NewPortfolioRepository(testDB)
+	pageRepo := repository.NewPageRepository(testDB)
+	settingsRepo := repository.NewSettingsRepository(testDB)
+	apiHandlers := NewAPIHandlers(postRepo, portfolioRepo, pageRepo, settingsRepo)
+
+	publishAt := time.Now().Add(24 * time.Hour)
+	postData := models.Post{
+		Title:     "Future Post",
+		Slug:      "future-post",
+		Content:   "Coming soon",
+		Published: true,
+		PublishAt: &publishAt,
+	}
+
+	body, _ := json.Marshal(postData)
+	req := httptest.NewRequest("POST", "/api/posts", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	apiHandlers.CreatePostHandler(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest("GET", "/api/posts", nil)
+	w = httptest.NewRecorder()
+	apiHandlers.GetPostsHandler(w, req)
+
+	var response struct {
+		Posts []models.Post `json:"posts"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode JSON: %v", err)
+	}
+	if len(response.Posts) != 1 {
+		t.Fatalf("Expected 1 post, got %d", len(response.Posts))
+	}
+	if !response.Posts[0].Scheduled {
+		t.Error("Expected post to be reported as scheduled")
+	}
+	if response.Posts[0].PublishAt == nil || !response.Posts[0].PublishAt.Equal(publishAt) {
+		t.Errorf("Expected publish_at %v, got %v", publishAt, response.Posts[0].PublishAt)
+	}
+
+	published, err := postRepo.GetPublishedPosts()
+	if err != nil {
+		t.Fatalf("Failed to get published posts: %v", err)
+	}
+	if len(published) != 0 {
+		t.Errorf("Expected scheduled post to be excluded from published posts, got %d", len(published))
+	}
+}
+
 func TestGetTemplatesHandler(t *testing.T) {
 	// Create temp directory
 	tempDir, err := os.MkdirTemp("", "templates_test")
@@ -325,3 +385,180 @@ func TestSaveTemplateHandler(t *testing.T) {
 		t.Errorf("Expected backup content '%s', got '%s'", initialContent, string(bakContent))
 	}
 }
+
+func TestPublishRollbackHandler(t *testing.T) {
+	testDB := setupTestDB(t)
+	defer testDB.Close()
+
+	templatePath, err := filepath.Abs("../../templates")
+	if err != nil {
+		t.Fatal(err)
+	}
+	outputPath := filepath.Join(t.TempDir(), "html-outputs")
+	os.Setenv("TEMPLATE_PATH", templatePath)
+	os.Setenv("OUTPUT_PATH", outputPath)
+	defer os.Unsetenv("TEMPLATE_PATH")
+	defer os.Unsetenv("OUTPUT_PATH")
+
+	postRepo := repository.NewPostRepository(testDB)
+	portfolioRepo := repository.NewPortfolioRepository(testDB)
+	pageRepo := repository.NewPageRepository(testDB)
+	settingsRepo := repository.NewSettingsRepository(testDB)
+	apiHandlers := NewAPIHandlers(postRepo, portfolioRepo, pageRepo, settingsRepo)
+
+	// Nothing to roll back to before the first publish
+	req := httptest.NewRequest("POST", "/api/publish/rollback", nil)
+	w := httptest.NewRecorder()
+	apiHandlers.PublishRollbackHandler(w, req)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("Expected status 409, got %d: %s", w.Code, w.Body.String())
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := apiHandlers.site().Publish(generator.BuildOptions{}); err != nil {
+			t.Fatalf("Publish failed: %v", err)
+		}
+	}
+
+	req = httptest.NewRequest("GET", "/api/publish/builds", nil)
+	w = httptest.NewRecorder()
+	apiHandlers.GetPublishBuildsHandler(w, req)
+	var builds []struct {
+		ID      string `json:"id"`
+		Current bool   `json:"current"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&builds); err != nil {
+		t.Fatalf("Failed to decode JSON: %v", err)
+	}
+	if len(builds) != 2 || !builds[1].Current {
+		t.Fatalf("Expected two builds with the newest live, got %+v", builds)
+	}
+
+	req = httptest.NewRequest("POST", "/api/publish/rollback", nil)
+	w = httptest.NewRecorder()
+	apiHandlers.PublishRollbackHandler(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	target, err := os.Readlink(outputPath)
+	if err != nil {
+		t.Fatalf("Output path should be a symlink: %v", err)
+	}
+	if filepath.Base(target) != builds[0].ID {
+		t.Errorf("Expected output to point at %s, got %s", builds[0].ID, target)
+	}
+
+	req = httptest.NewRequest("POST", "/api/publish/rollback", strings.NewReader(`{"build": "missing"}`))
+	w = httptest.NewRecorder()
+	apiHandlers.PublishRollbackHandler(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+}
+
+func TestGetPostsHandlerFilters(t *testing.T) {
+	testDB := setupTestDB(t)
+	defer testDB.Close()
+
+	postRepo := repository.NewPostRepository(testDB)
+	apiHandlers := NewAPIHandlers(postRepo, repository.NewPortfolioRepository(testDB), repository.NewPageRepository(testDB), repository.NewSettingsRepository(testDB))
+
+	for _, post := range []models.Post{
+		{Title: "Concurrency in Go", Slug: "go-concurrency", Content: "Channels and <b>goroutines</b> explained.", Tags: "go", Published: true, CreatedAt: time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC)},
+		{Title: "Writing SQL", Slug: "sql", Content: "Queries for Go programs and more.", Tags: "databases", Published: true, CreatedAt: time.Date(2024, 3, 5, 9, 0, 0, 0, time.UTC)},
+		{Title: "Café notes", Slug: "cafe", Content: "Draft about coffee.", Tags: "life, go", Published: false, CreatedAt: time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)},
+	} {
+		if err := postRepo.CreatePost(&post); err != nil {
+			t.Fatalf("Failed to create post: %v", err)
+		}
+	}
+
+	get := func(query string) (int, []models.Post, int) {
+		t.Helper()
+		req := httptest.NewRequest("GET", "/api/posts?"+query, nil)
+		w := httptest.NewRecorder()
+		apiHandlers.GetPostsHandler(w, req)
+		var response struct {
+			Posts []models.Post `json:"posts"`
+			Total int           `json:"total"`
+		}
+		if w.Code == http.StatusOK {
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatalf("Failed to decode JSON: %v", err)
+			}
+		}
+		return w.Code, response.Posts, response.Total
+	}
+	slugs := func(posts []models.Post) string {
+		var s []string
+		for _, p := range posts {
+			s = append(s, p.Slug)
+		}
+		return strings.Join(s, ",")
+	}
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"", "cafe,sql,go-concurrency"},
+		{"q=go", "go-concurrency,cafe,sql"},
+		{"q=goroutine", "go-concurrency"},
+		{"q=cafe", "cafe"},
+		{"q=go+programs", "sql"},
+		{`q="goroutines+OR+(`, ""},
+		{`q=goroutines"`, "go-concurrency"},
+		{"tag=Go", "cafe,go-concurrency"},
+		{"published=false", "cafe"},
+		{"published=true&tag=go", "go-concurrency"},
+		{"from=2024-03-05&to=2024-06-01", "cafe,sql"},
+		{"to=2024-03-04", "go-concurrency"},
+		{"sort=oldest", "go-concurrency,sql,cafe"},
+		{"sort=title", "cafe,go-concurrency,sql"},
+		{"q=go&sort=newest&limit=2", "cafe,sql"},
+	}
+	for _, tt := range tests {
+		code, posts, _ := get(tt.query)
+		if code != http.StatusOK {
+			t.Errorf("%s: expected status 200, got %d", tt.query, code)
+			continue
+		}
+		if got := slugs(posts); got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.query, tt.want, got)
+		}
+	}
+
+	_, posts, total := get("q=goroutines")
+	if total != 1 || len(posts) != 1 {
+		t.Fatalf("Expected 1 match, got %d", total)
+	}
+	if !strings.Contains(posts[0].Snippet, "<mark>goroutines</mark>") || !strings.Contains(posts[0].Snippet, "&lt;b&gt;") {
+		t.Errorf("Expected an escaped, highlighted snippet, got %q", posts[0].Snippet)
+	}
+
+	// The index follows edits and deletes
+	post, _ := postRepo.GetPostBySlug("sql")
+	post.Content = "Now about indexes."
+	if err := postRepo.UpdatePost(post); err != nil {
+		t.Fatal(err)
+	}
+	if _, posts, _ := get("q=programs"); len(posts) != 0 {
+		t.Errorf("Expected the old content to be gone from the index, got %q", slugs(posts))
+	}
+	if _, posts, _ := get("q=indexes"); slugs(posts) != "sql" {
+		t.Errorf("Expected the new content to be indexed, got %q", slugs(posts))
+	}
+	if err := postRepo.DeletePost(post.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, posts, _ := get("q=indexes"); len(posts) != 0 {
+		t.Errorf("Expected the deleted post to be gone from the index, got %q", slugs(posts))
+	}
+
+	for _, query := range []string{"published=maybe", "from=2024-13-01", "from=2024-06-01&to=2024-01-01", "sort=random", "sort=relevance"} {
+		if code, _, _ := get(query); code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", query, code)
+		}
+	}
+}
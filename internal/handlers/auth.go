@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"html/template"
+	"io/fs"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/auth"
+	"github.com/ariefbayu/personal-blog-generator/internal/repository"
+)
+
+// dummyPasswordHash is checked when a username does not exist, so failed logins take the same time either way
+var dummyPasswordHash, _ = auth.HashPassword("not-a-real-password")
+
+type AuthHandlers struct {
+	userRepo *repository.UserRepository
+	auth     *auth.Authenticator
+}
+
+func NewAuthHandlers(userRepo *repository.UserRepository, authenticator *auth.Authenticator) *AuthHandlers {
+	return &AuthHandlers{
+		userRepo: userRepo,
+		auth:     authenticator,
+	}
+}
+
+type credentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// ServeLoginPage shows the sign-in form, or the account setup form while no users exist
+func (h *AuthHandlers) ServeLoginPage(w http.ResponseWriter, r *http.Request) {
+	next := safeRedirect(r.URL.Query().Get("next"))
+
+	if user, _, err := h.auth.CurrentUser(r); err == nil && user != nil {
+		http.Redirect(w, r, next, http.StatusFound)
+		return
+	}
+
+	count, err := h.userRepo.CountUsers()
+	if err != nil {
+		http.Error(w, "Failed to load users", http.StatusInternalServerError)
+		return
+	}
+
+	page, err := fs.ReadFile(AdminFS, "login.html")
+	if err != nil {
+		http.Error(w, "Login page template not found", http.StatusInternalServerError)
+		return
+	}
+	tmpl, err := template.New("login").Parse(string(page))
+	if err != nil {
+		http.Error(w, "Failed to render login page", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	tmpl.Execute(w, map[string]interface{}{
+		"SetupRequired": count == 0,
+		"Next":          next,
+	})
+}
+
+func (h *AuthHandlers) LoginHandler(w http.ResponseWriter, r *http.Request) {
+	var req credentials
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.userRepo.GetUserByUsername(strings.TrimSpace(req.Username))
+	if err != nil && err != sql.ErrNoRows {
+		http.Error(w, "Failed to load user", http.StatusInternalServerError)
+		return
+	}
+	if user == nil {
+		auth.CheckPassword(dummyPasswordHash, req.Password)
+		writeAuthError(w, "Invalid username or password", http.StatusUnauthorized)
+		return
+	}
+	if !auth.CheckPassword(user.PasswordHash, req.Password) {
+		writeAuthError(w, "Invalid username or password", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.auth.Login(w, r, user); err != nil {
+		http.Error(w, "Failed to create session", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user)
+}
+
+// SetupHandler creates the first admin account. It is refused once any user exists.
+func (h *AuthHandlers) SetupHandler(w http.ResponseWriter, r *http.Request) {
+	var req credentials
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	user, err := auth.CreateInitialUser(h.userRepo, req.Username, req.Password)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrUserExists):
+			writeAuthError(w, "Setup has already been completed", http.StatusConflict)
+		case errors.Is(err, auth.ErrPasswordTooShort), errors.Is(err, auth.ErrUsernameRequired):
+			writeAuthError(w, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, "Failed to create user", http.StatusInternalServerError)
+		}
+		return
+	}
+	log.Printf("Created admin account %q", user.Username)
+
+	if err := h.auth.Login(w, r, user); err != nil {
+		http.Error(w, "Failed to create session", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(user)
+}
+
+func (h *AuthHandlers) LogoutHandler(w http.ResponseWriter, r *http.Request) {
+	if err := h.auth.Logout(w, r); err != nil {
+		http.Error(w, "Failed to end session", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// MeHandler returns the signed-in user
+func (h *AuthHandlers) MeHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(auth.UserFromContext(r.Context()))
+}
+
+// ChangePasswordHandler replaces the signed-in user's password.
+// Every session of the user is ended and a new one is started for this browser.
+func (h *AuthHandlers) ChangePasswordHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		CurrentPassword string `json:"current_password"`
+		NewPassword     string `json:"new_password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	user := auth.UserFromContext(r.Context())
+	if !auth.CheckPassword(user.PasswordHash, req.CurrentPassword) {
+		writeAuthError(w, "Current password is incorrect", http.StatusForbidden)
+		return
+	}
+
+	hash, err := auth.HashPassword(req.NewPassword)
+	if err != nil {
+		if errors.Is(err, auth.ErrPasswordTooShort) {
+			writeAuthError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Failed to hash password", http.StatusInternalServerError)
+		return
+	}
+	if err := h.userRepo.UpdatePasswordHash(user.ID, hash); err != nil {
+		http.Error(w, "Failed to update password", http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.auth.Login(w, r, user); err != nil {
+		http.Error(w, "Failed to create session", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// safeRedirect only allows redirects to local paths, falling back to the dashboard
+func safeRedirect(next string) string {
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, "/\\") {
+		return "/admin/dashboard"
+	}
+	return next
+}
+
+func writeAuthError(w http.ResponseWriter, message string, status int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
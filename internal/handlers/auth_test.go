@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/auth"
+	"github.com/ariefbayu/personal-blog-generator/internal/repository"
+)
+
+func TestSetupAndLoginHandlers(t *testing.T) {
+	testDB := setupTestDB(t)
+	defer testDB.Close()
+
+	userRepo := repository.NewUserRepository(testDB)
+	authHandlers := NewAuthHandlers(userRepo, auth.NewAuthenticator(userRepo, repository.NewAPITokenRepository(testDB)))
+
+	// Setup rejects weak passwords
+	req := httptest.NewRequest("POST", "/api/auth/setup", strings.NewReader(`{"username":"admin","password":"short"}`))
+	w := httptest.NewRecorder()
+	authHandlers.SetupHandler(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400, got %d: %s", w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest("POST", "/api/auth/setup", strings.NewReader(`{"username":"admin","password":"correct horse"}`))
+	w = httptest.NewRecorder()
+	authHandlers.SetupHandler(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+	if strings.Contains(w.Body.String(), "password") {
+		t.Error("Response should not contain the password hash")
+	}
+
+	// Setup only works once
+	req = httptest.NewRequest("POST", "/api/auth/setup", strings.NewReader(`{"username":"intruder","password":"correct horse"}`))
+	w = httptest.NewRecorder()
+	authHandlers.SetupHandler(w, req)
+	if w.Code != http.StatusConflict {
+		t.Errorf("Expected status 409, got %d", w.Code)
+	}
+
+	for _, body := range []string{
+		`{"username":"admin","password":"wrong horse"}`,
+		`{"username":"nobody","password":"correct horse"}`,
+	} {
+		req = httptest.NewRequest("POST", "/api/auth/login", strings.NewReader(body))
+		w = httptest.NewRecorder()
+		authHandlers.LoginHandler(w, req)
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status 401 for %s, got %d", body, w.Code)
+		}
+	}
+
+	req = httptest.NewRequest("POST", "/api/auth/login", strings.NewReader(`{"username":"admin","password":"correct horse"}`))
+	w = httptest.NewRecorder()
+	authHandlers.LoginHandler(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if len(w.Result().Cookies()) != 2 {
+		t.Errorf("Expected session and CSRF cookies, got %d", len(w.Result().Cookies()))
+	}
+}
+
+func TestSafeRedirect(t *testing.T) {
+	tests := map[string]string{
+		"":                     "/admin/dashboard",
+		"/admin/posts":         "/admin/posts",
+		"//evil.example":       "/admin/dashboard",
+		"/\\evil.example":      "/admin/dashboard",
+		"https://evil.example": "/admin/dashboard",
+	}
+	for next, want := range tests {
+		if got := safeRedirect(next); got != want {
+			t.Errorf("safeRedirect(%q) = %q, want %q", next, got, want)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/backup"
+)
+
+// maxRestoreSize limits the size of an uploaded backup archive
+var maxRestoreSize int64 = 1 << 30
+
+type BackupHandlers struct {
+	manager *backup.Manager
+}
+
+func NewBackupHandlers(manager *backup.Manager) *BackupHandlers {
+	return &BackupHandlers{manager: manager}
+}
+
+func (h *BackupHandlers) GetBackupsHandler(w http.ResponseWriter, r *http.Request) {
+	archives, err := h.manager.List()
+	if err != nil {
+		http.Error(w, "Failed to list backups", http.StatusInternalServerError)
+		return
+	}
+	if archives == nil {
+		archives = []backup.Archive{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(archives)
+}
+
+func (h *BackupHandlers) CreateBackupHandler(w http.ResponseWriter, r *http.Request) {
+	archive, err := h.manager.Create()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to create backup: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(archive)
+}
+
+func (h *BackupHandlers) DownloadBackupHandler(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	file, err := h.manager.Path(name)
+	if err != nil {
+		http.Error(w, "Backup not found", http.StatusNotFound)
+		return
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		http.Error(w, "Failed to open backup", http.StatusInternalServerError)
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		http.Error(w, "Failed to open backup", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, name))
+	http.ServeContent(w, r, name, info.ModTime(), f)
+}
+
+// RestoreBackupHandler restores a stored backup named in the URL
+func (h *BackupHandlers) RestoreBackupHandler(w http.ResponseWriter, r *http.Request) {
+	result, err := h.manager.RestoreFile(chi.URLParam(r, "name"))
+	writeRestoreResult(w, result, err)
+}
+
+// RestoreUploadHandler restores an uploaded archive, sent as the request body or as the "file" field of a form
+func (h *BackupHandlers) RestoreUploadHandler(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxRestoreSize)
+
+	var body io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		// Stream the file part instead of buffering what may be a large archive
+		reader, err := r.MultipartReader()
+		if err != nil {
+			http.Error(w, "Invalid form", http.StatusBadRequest)
+			return
+		}
+		for {
+			part, err := reader.NextPart()
+			if err != nil {
+				http.Error(w, "Missing file", http.StatusBadRequest)
+				return
+			}
+			if part.FormName() == "file" {
+				body = part
+				break
+			}
+		}
+	}
+
+	result, err := h.manager.Restore(body)
+	writeRestoreResult(w, result, err)
+}
+
+func writeRestoreResult(w http.ResponseWriter, result *backup.RestoreResult, err error) {
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		http.Error(w, fmt.Sprintf("Backup archive is larger than %d MB", tooLarge.Limit>>20), http.StatusRequestEntityTooLarge)
+	case errors.Is(err, backup.ErrBackupNotFound):
+		http.Error(w, "Backup not found", http.StatusNotFound)
+	case errors.Is(err, backup.ErrIncompatible):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, backup.ErrInvalidArchive):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case err != nil:
+		http.Error(w, fmt.Sprintf("Restore failed: %s", err.Error()), http.StatusInternalServerError)
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(result)
+	}
+}
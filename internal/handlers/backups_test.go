@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/backup"
+	"github.com/ariefbayu/personal-blog-generator/internal/db"
+)
+
+func TestBackupHandlers(t *testing.T) {
+	dir := t.TempDir()
+	database, err := db.Connect(filepath.Join(dir, "blog.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	if err := db.Migrate(database); err != nil {
+		t.Fatal(err)
+	}
+
+	manager := backup.NewManager(database, filepath.Join(dir, "backups"), filepath.Join(dir, "out"), filepath.Join(dir, "templates"))
+	h := NewBackupHandlers(manager)
+	r := chi.NewRouter()
+	r.Get("/api/backups", h.GetBackupsHandler)
+	r.Post("/api/backups", h.CreateBackupHandler)
+	r.Get("/api/backups/{name}", h.DownloadBackupHandler)
+	r.Post("/api/backups/restore", h.RestoreUploadHandler)
+	r.Post("/api/backups/{name}/restore", h.RestoreBackupHandler)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/api/backups", nil))
+	if w.Code != 201 {
+		t.Fatalf("Expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var archive backup.Archive
+	json.NewDecoder(w.Body).Decode(&archive)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api/backups", nil))
+	var archives []backup.Archive
+	json.NewDecoder(w.Body).Decode(&archives)
+	if len(archives) != 1 || archives[0].Name != archive.Name {
+		t.Errorf("Unexpected backup list: %+v", archives)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api/backups/"+archive.Name, nil))
+	if w.Code != 200 || int64(w.Body.Len()) != archive.Size {
+		t.Fatalf("Expected the archive download, got %d with %d bytes", w.Code, w.Body.Len())
+	}
+	downloaded := w.Body.Bytes()
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api/backups/missing.tar.gz", nil))
+	if w.Code != 404 {
+		t.Errorf("Expected 404 for an unknown backup, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/api/backups/restore", bytes.NewReader(downloaded)))
+	if w.Code != 200 {
+		t.Fatalf("Expected the uploaded backup to restore, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// Uploads over the size limit are refused before they are restored
+	originalLimit := maxRestoreSize
+	maxRestoreSize = int64(len(downloaded) / 2)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/api/backups/restore", bytes.NewReader(downloaded)))
+	maxRestoreSize = originalLimit
+	if w.Code != 413 {
+		t.Errorf("Expected 413 for an archive over the limit, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/api/backups/restore", strings.NewReader("not an archive")))
+	if w.Code != 400 {
+		t.Errorf("Expected 400 for an invalid archive, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/api/backups/"+archive.Name+"/restore", nil))
+	if w.Code != 200 {
+		t.Errorf("Expected the stored backup to restore, got %d: %s", w.Code, w.Body.String())
+	}
+}
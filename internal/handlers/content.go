@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/content"
+)
+
+// maxImportSize limits the size of an uploaded import
+const maxImportSize = 64 << 20
+
+type ContentHandlers struct {
+	store *content.Store
+}
+
+func NewContentHandlers(store *content.Store) *ContentHandlers {
+	return &ContentHandlers{store: store}
+}
+
+// ExportHandler downloads all content, as a zip of Markdown files by default or as JSON with ?format=json
+func (h *ContentHandlers) ExportHandler(w http.ResponseWriter, r *http.Request) {
+	stamp := time.Now().Format("20060102-150405")
+
+	if r.URL.Query().Get("format") == "json" {
+		var buf bytes.Buffer
+		if err := h.store.ExportJSON(&buf); err != nil {
+			http.Error(w, "Failed to export content", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="blog-export-%s.json"`, stamp))
+		w.Write(buf.Bytes())
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := h.store.ExportZip(&buf); err != nil {
+		http.Error(w, "Failed to export content", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="blog-export-%s.zip"`, stamp))
+	w.Write(buf.Bytes())
+}
+
+// ImportHandler upserts content from an export, sent as the request body or as the "file" field of a form.
+// Zip archives are read as Markdown exports, anything else as a JSON export.
+func (h *ContentHandlers) ImportHandler(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+
+	var body io.Reader = r.Body
+	if file, _, err := r.FormFile("file"); err == nil {
+		defer file.Close()
+		body = file
+	}
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		http.Error(w, "Import file too large or unreadable", http.StatusBadRequest)
+		return
+	}
+
+	var result *content.ImportResult
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		result, err = h.store.ImportZip(bytes.NewReader(data), int64(len(data)))
+	} else {
+		result, err = h.store.ImportJSON(bytes.NewReader(data))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err != nil {
+		// Content before the failing item has already been imported, so report the counts too
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":  fmt.Sprintf("Import failed: %s", err.Error()),
+			"result": result,
+		})
+		return
+	}
+	json.NewEncoder(w).Encode(result)
+}
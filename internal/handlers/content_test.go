@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/content"
+	"github.com/ariefbayu/personal-blog-generator/internal/models"
+	"github.com/ariefbayu/personal-blog-generator/internal/repository"
+)
+
+func TestContentExportImport(t *testing.T) {
+	sourceDB := setupTestDB(t)
+	defer sourceDB.Close()
+	postRepo := repository.NewPostRepository(sourceDB)
+	if err := postRepo.CreatePost(&models.Post{Title: "Hello", Slug: "hello", Content: "Body", Published: true}); err != nil {
+		t.Fatal(err)
+	}
+	source := NewContentHandlers(content.NewStore(postRepo, repository.NewPageRepository(sourceDB), repository.NewPortfolioRepository(sourceDB)))
+
+	w := httptest.NewRecorder()
+	source.ExportHandler(w, httptest.NewRequest("GET", "/api/export", nil))
+	if w.Code != 200 || w.Header().Get("Content-Type") != "application/zip" {
+		t.Fatalf("Expected zip export, got %d %s", w.Code, w.Header().Get("Content-Type"))
+	}
+	archive := w.Body.Bytes()
+	zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		t.Fatalf("Invalid zip: %v", err)
+	}
+	names := map[string]bool{}
+	for _, f := range zr.File {
+		names[f.Name] = true
+	}
+	if !names["posts/hello.md"] || !names["portfolio.yaml"] {
+		t.Errorf("Unexpected zip contents: %v", names)
+	}
+
+	targetDB := setupTestDB(t)
+	defer targetDB.Close()
+	target := NewContentHandlers(content.NewStore(repository.NewPostRepository(targetDB), repository.NewPageRepository(targetDB), repository.NewPortfolioRepository(targetDB)))
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	part, _ := mw.CreateFormFile("file", "export.zip")
+	part.Write(archive)
+	mw.Close()
+
+	req := httptest.NewRequest("POST", "/api/import", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	w = httptest.NewRecorder()
+	target.ImportHandler(w, req)
+	if w.Code != 200 {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var result content.ImportResult
+	json.NewDecoder(w.Body).Decode(&result)
+	if result.PostsCreated != 1 {
+		t.Errorf("Expected one created post, got %+v", result)
+	}
+
+	// A JSON export can be sent as the raw body
+	w = httptest.NewRecorder()
+	source.ExportHandler(w, httptest.NewRequest("GET", "/api/export?format=json", nil))
+	exported := w.Body.Bytes()
+
+	w = httptest.NewRecorder()
+	target.ImportHandler(w, httptest.NewRequest("POST", "/api/import", bytes.NewReader(exported)))
+	json.NewDecoder(w.Body).Decode(&result)
+	if w.Code != 200 || result.PostsUpdated != 1 {
+		t.Errorf("Expected JSON import to update the post, got %d %+v", w.Code, result)
+	}
+
+	w = httptest.NewRecorder()
+	target.ImportHandler(w, httptest.NewRequest("POST", "/api/import", bytes.NewReader([]byte("not an export"))))
+	if w.Code != 400 {
+		t.Errorf("Expected 400 for invalid import, got %d", w.Code)
+	}
+}
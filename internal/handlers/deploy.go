@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/deploy"
+	"github.com/ariefbayu/personal-blog-generator/internal/models"
+	"github.com/ariefbayu/personal-blog-generator/internal/publish"
+	"github.com/ariefbayu/personal-blog-generator/internal/repository"
+)
+
+type DeployHandlers struct {
+	queue          *publish.Queue
+	deploymentRepo *repository.DeploymentRepository
+}
+
+func NewDeployHandlers(queue *publish.Queue, deploymentRepo *repository.DeploymentRepository) *DeployHandlers {
+	return &DeployHandlers{queue: queue, deploymentRepo: deploymentRepo}
+}
+
+// DeployHandler publishes the site through the publish queue and uploads the files that changed to the
+// deployment target. The response includes the publish job, whose progress is streamed at its events URL.
+// With dry_run=true nothing is published or uploaded, the response lists what the live build would change.
+func (h *DeployHandlers) DeployHandler(w http.ResponseWriter, r *http.Request) {
+	opts := deploy.Options{DryRun: r.URL.Query().Get("dry_run") == "true"}
+	cfg := deploy.ConfigFromEnv()
+	if !cfg.Configured() {
+		writeJSONError(w, http.StatusBadRequest, map[string]interface{}{"error": deploy.ErrNotConfigured.Error()})
+		return
+	}
+
+	var build *publishJobResponse
+	if !opts.DryRun {
+		job, err := h.queue.Run(r.Context(), models.PublishSourceDeploy, false)
+		if r.Context().Err() != nil {
+			return
+		}
+		build = &publishJobResponse{PublishJob: job, Events: "/api/publish/" + job.ID + "/events"}
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, map[string]interface{}{
+				"error":   fmt.Sprintf("Generation failed: %s", err.Error()),
+				"publish": build,
+			})
+			return
+		}
+	}
+
+	// A deployment that stops halfway leaves the target inconsistent, so it is not tied to the request
+	result, err := deploy.Run(context.WithoutCancel(r.Context()), cfg, h.queue.Site().OutputPath, opts, h.deploymentRepo)
+	switch {
+	case errors.Is(err, deploy.ErrNotConfigured):
+		writeJSONError(w, http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	case errors.Is(err, deploy.ErrInProgress):
+		writeJSONError(w, http.StatusConflict, map[string]interface{}{"error": err.Error()})
+		return
+	case err != nil:
+		writeJSONError(w, http.StatusInternalServerError, map[string]interface{}{
+			"error":      fmt.Sprintf("Deployment failed: %s", err.Error()),
+			"deployment": result,
+		})
+		return
+	}
+
+	message := fmt.Sprintf("Deployed to %s: %d uploaded, %d deleted, %d unchanged", result.Target, len(result.Uploaded), len(result.Deleted), result.Unchanged)
+	if opts.DryRun {
+		message = "Dry run completed, the target was not changed"
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":    message,
+		"publish":    build,
+		"deployment": result,
+	})
+}
+
+// GetDeploymentsHandler lists recorded deployments, newest first
+func (h *DeployHandlers) GetDeploymentsHandler(w http.ResponseWriter, r *http.Request) {
+	limit := 20
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 && l <= 100 {
+		limit = l
+	}
+
+	deployments, err := h.deploymentRepo.GetDeployments(limit)
+	if err != nil {
+		http.Error(w, "Failed to get deployments", http.StatusInternalServerError)
+		return
+	}
+	if deployments == nil {
+		deployments = []models.Deployment{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(deployments)
+}
+
+func writeJSONError(w http.ResponseWriter, status int, body map[string]interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
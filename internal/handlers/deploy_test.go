@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/models"
+	"github.com/ariefbayu/personal-blog-generator/internal/publish"
+	"github.com/ariefbayu/personal-blog-generator/internal/repository"
+)
+
+func TestDeployHandler(t *testing.T) {
+	testDB := setupTestDB(t)
+	defer testDB.Close()
+
+	templatePath, err := filepath.Abs("../../templates")
+	if err != nil {
+		t.Fatal(err)
+	}
+	outputPath := filepath.Join(t.TempDir(), "html-outputs")
+	target := filepath.Join(t.TempDir(), "www")
+	t.Setenv("DEPLOY_TARGET", "")
+	t.Setenv("DEPLOY_HOST", "")
+	t.Setenv("DEPLOY_PATH", "")
+
+	postRepo := repository.NewPostRepository(testDB)
+	site := publish.NewSite(postRepo, repository.NewPortfolioRepository(testDB), repository.NewPageRepository(testDB), repository.NewSettingsRepository(testDB), templatePath, outputPath)
+	jobRepo := repository.NewPublishJobRepository(testDB)
+	h := NewDeployHandlers(publish.NewQueue(site, jobRepo), repository.NewDeploymentRepository(testDB))
+
+	w := httptest.NewRecorder()
+	h.DeployHandler(w, httptest.NewRequest("POST", "/api/deploy", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400 without a target, got %d: %s", w.Code, w.Body.String())
+	}
+
+	t.Setenv("DEPLOY_PATH", target)
+	postRepo.CreatePost(&models.Post{Title: "Hello", Slug: "hello", Content: "Hello world", Published: true})
+
+	w = httptest.NewRecorder()
+	h.DeployHandler(w, httptest.NewRequest("POST", "/api/deploy", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var response struct {
+		Publish struct {
+			ID      string
+			Status  string
+			Written int
+			Events  string
+		}
+		Deployment struct {
+			Uploaded  []string
+			Unchanged int
+		}
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+	if response.Publish.Written == 0 || len(response.Deployment.Uploaded) == 0 {
+		t.Fatalf("Expected the site to be published and uploaded, got %+v", response)
+	}
+	// The publish went through the queue, so it has a job record and an event stream
+	if response.Publish.Status != models.PublishJobSucceeded || response.Publish.Events != "/api/publish/"+response.Publish.ID+"/events" {
+		t.Errorf("Unexpected publish job: %+v", response.Publish)
+	}
+	jobs, err := jobRepo.GetPublishJobs(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var deployJobs int
+	for _, job := range jobs {
+		if job.Source == models.PublishSourceDeploy {
+			deployJobs++
+			if job.ID != response.Publish.ID {
+				t.Errorf("Expected the recorded job to be %s, got %s", response.Publish.ID, job.ID)
+			}
+		}
+	}
+	if deployJobs != 1 {
+		t.Errorf("Expected one recorded deploy publish job, got %+v", jobs)
+	}
+	if _, err := os.Stat(filepath.Join(target, "hello.html")); err != nil {
+		t.Errorf("Expected the post on the target: %v", err)
+	}
+
+	// Nothing changed, so a dry run has nothing to upload
+	w = httptest.NewRecorder()
+	h.DeployHandler(w, httptest.NewRequest("POST", "/api/deploy?dry_run=true", nil))
+	response.Deployment.Uploaded = nil
+	json.NewDecoder(w.Body).Decode(&response)
+	if w.Code != http.StatusOK || len(response.Deployment.Uploaded) != 0 || response.Deployment.Unchanged == 0 {
+		t.Errorf("Expected a dry run with nothing to upload, got %d: %+v", w.Code, response)
+	}
+
+	w = httptest.NewRecorder()
+	h.GetDeploymentsHandler(w, httptest.NewRequest("GET", "/api/deploy/history", nil))
+	var deployments []models.Deployment
+	json.NewDecoder(w.Body).Decode(&deployments)
+	if len(deployments) != 1 || deployments[0].Status != models.DeploymentSucceeded {
+		t.Errorf("Expected one recorded deployment, got %+v", deployments)
+	}
+}
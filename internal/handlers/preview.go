@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/generator"
+	"github.com/ariefbayu/personal-blog-generator/internal/repository"
+	"github.com/ariefbayu/personal-blog-generator/internal/utils"
+)
+
+type PreviewHandlers struct {
+	postRepo     *repository.PostRepository
+	pageRepo     *repository.PageRepository
+	settingsRepo *repository.SettingsRepository
+}
+
+func NewPreviewHandlers(postRepo *repository.PostRepository, pageRepo *repository.PageRepository, settingsRepo *repository.SettingsRepository) *PreviewHandlers {
+	return &PreviewHandlers{
+		postRepo:     postRepo,
+		pageRepo:     pageRepo,
+		settingsRepo: settingsRepo,
+	}
+}
+
+// PreviewPostHandler renders a post through the site templates without publishing it
+func (h *PreviewHandlers) PreviewPostHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid post ID", http.StatusBadRequest)
+		return
+	}
+
+	post, err := h.postRepo.GetPostByID(id)
+	if err != nil {
+		http.Error(w, "Post not found", http.StatusNotFound)
+		return
+	}
+
+	// Render into a buffer so template errors do not leave a partial page
+	var buf bytes.Buffer
+	if err := generator.RenderPostPreview(&buf, post, h.pageRepo, h.settingsRepo, utils.GetTemplatePath()); err != nil {
+		log.Printf("Failed to render preview for post %d: %v", id, err)
+		http.Error(w, "Failed to render preview: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writePreview(w, buf.Bytes())
+}
+
+// PreviewPageHandler renders a page through the site templates without publishing it
+func (h *PreviewHandlers) PreviewPageHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid page ID", http.StatusBadRequest)
+		return
+	}
+
+	page, err := h.pageRepo.GetPageByID(id)
+	if err != nil {
+		http.Error(w, "Page not found", http.StatusNotFound)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := generator.RenderPagePreview(&buf, page, h.pageRepo, h.settingsRepo, utils.GetTemplatePath()); err != nil {
+		log.Printf("Failed to render preview for page %d: %v", id, err)
+		http.Error(w, "Failed to render preview: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writePreview(w, buf.Bytes())
+}
+
+// writePreview writes rendered preview HTML, keeping drafts out of caches and search engines
+func writePreview(w http.ResponseWriter, html []byte) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Robots-Tag", "noindex")
+	w.Write(html)
+}
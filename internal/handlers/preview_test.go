@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/models"
+	"github.com/ariefbayu/personal-blog-generator/internal/repository"
+)
+
+func TestPreviewHandlers(t *testing.T) {
+	// Setup test database
+	db := setupTestDB(t)
+	defer db.Close()
+
+	templatePath, err := filepath.Abs("../../templates")
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("TEMPLATE_PATH", templatePath)
+	defer os.Unsetenv("TEMPLATE_PATH")
+
+	postRepo := repository.NewPostRepository(db)
+	pageRepo := repository.NewPageRepository(db)
+	settingsRepo := repository.NewSettingsRepository(db)
+	handlers := NewPreviewHandlers(postRepo, pageRepo, settingsRepo)
+
+	r := chi.NewRouter()
+	r.Get("/admin/preview/posts/{id}", handlers.PreviewPostHandler)
+	r.Get("/admin/preview/pages/{id}", handlers.PreviewPageHandler)
+
+	draft := &models.Post{Title: "Unpublished Draft", Slug: "draft", Content: "# Work in progress", CreatedAt: time.Now()}
+	if err := postRepo.CreatePost(draft); err != nil {
+		t.Fatalf("Failed to create post: %v", err)
+	}
+	page := &models.Page{Title: "About Me", Slug: "about", Content: "Hello there", ShowInNav: true}
+	if err := pageRepo.CreatePage(page); err != nil {
+		t.Fatalf("Failed to create page: %v", err)
+	}
+
+	t.Run("PreviewDraftPost", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/admin/preview/posts/1", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		body := w.Body.String()
+		if !strings.Contains(body, "Unpublished Draft") || !strings.Contains(body, "Work in progress</h1>") {
+			t.Error("Expected rendered draft content in preview")
+		}
+		if !strings.Contains(body, `href="/posts.html"`) {
+			t.Error("Expected site navigation in preview")
+		}
+		if w.Header().Get("Cache-Control") != "no-store" {
+			t.Error("Expected preview to be excluded from caches")
+		}
+	})
+
+	t.Run("PreviewPage", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/admin/preview/pages/1", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+		if !strings.Contains(w.Body.String(), "Hello there") {
+			t.Error("Expected rendered page content in preview")
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/admin/preview/posts/99", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", w.Code)
+		}
+	})
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/models"
+	"github.com/ariefbayu/personal-blog-generator/internal/publish"
+)
+
+// eventKeepAlive is how often an idle event stream sends a comment, so proxies keep the connection open
+const eventKeepAlive = 15 * time.Second
+
+type PublishHandlers struct {
+	queue *publish.Queue
+}
+
+func NewPublishHandlers(queue *publish.Queue) *PublishHandlers {
+	return &PublishHandlers{queue: queue}
+}
+
+// publishJobResponse is a job with the URL of its event stream
+type publishJobResponse struct {
+	models.PublishJob
+	Events string `json:"events"`
+}
+
+// PublishSiteHandler starts a publish job and responds with 202 and the job, whose progress
+// is streamed at its events URL. With wait=true it responds once the job has finished.
+// A dry run reports what would be written and removed without touching the output directory.
+func (h *PublishHandlers) PublishSiteHandler(w http.ResponseWriter, r *http.Request) {
+	dryRun := r.URL.Query().Get("dry_run") == "true"
+	job, started, err := h.queue.Start(models.PublishSourceManual, dryRun)
+	if errors.Is(err, publish.ErrBusy) {
+		writeJSONError(w, http.StatusConflict, map[string]interface{}{"error": err.Error(), "job": job.ID})
+		return
+	}
+
+	status := http.StatusAccepted
+	if !started {
+		// Another click already started the same job
+		status = http.StatusOK
+	}
+	if r.URL.Query().Get("wait") == "true" {
+		// A failed job is reported in the response, only a cancelled request has nothing to report
+		job, _ = h.queue.Wait(r.Context(), job.ID)
+		if r.Context().Err() != nil {
+			return
+		}
+		status = http.StatusOK
+		if job.Status == models.PublishJobFailed {
+			status = http.StatusInternalServerError
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(publishJobResponse{PublishJob: job, Events: "/api/publish/" + job.ID + "/events"})
+}
+
+// PublishEventsHandler streams a job's progress as Server-Sent Events. Each step is a "step" event,
+// and a final "done" event carries the finished job. Reconnecting clients resume after Last-Event-ID.
+func (h *PublishHandlers) PublishEventsHandler(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	id := chi.URLParam(r, "job")
+	after, _ := strconv.Atoi(r.Header.Get("Last-Event-ID"))
+	events, job, changed, err := h.queue.Events(id, after)
+	if err != nil {
+		http.Error(w, "Publish job not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	// Stop nginx from buffering the stream
+	w.Header().Set("X-Accel-Buffering", "no")
+	keepAlive := time.NewTicker(eventKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		for _, event := range events {
+			data, _ := json.Marshal(event)
+			fmt.Fprintf(w, "id: %d\nevent: step\ndata: %s\n\n", event.ID, data)
+			after = event.ID
+		}
+		if job.Status != models.PublishJobRunning {
+			data, _ := json.Marshal(job)
+			fmt.Fprintf(w, "event: done\ndata: %s\n\n", data)
+			flusher.Flush()
+			return
+		}
+		flusher.Flush()
+
+		select {
+		case <-changed:
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		case <-r.Context().Done():
+			return
+		}
+		events, job, changed, err = h.queue.Events(id, after)
+		if err != nil {
+			return
+		}
+	}
+}
+
+// GetPublishJobsHandler lists the running job and recent publish jobs, newest first
+func (h *PublishHandlers) GetPublishJobsHandler(w http.ResponseWriter, r *http.Request) {
+	limit := 20
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 && l <= 100 {
+		limit = l
+	}
+
+	jobs, err := h.queue.History(limit)
+	if err != nil {
+		http.Error(w, "Failed to get publish jobs", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(jobs)
+}
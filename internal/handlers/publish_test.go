@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"bufio"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/generator"
+	"github.com/ariefbayu/personal-blog-generator/internal/models"
+	"github.com/ariefbayu/personal-blog-generator/internal/publish"
+	"github.com/ariefbayu/personal-blog-generator/internal/repository"
+)
+
+func newTestPublishHandlers(t *testing.T, testDB *sql.DB, outputPath string) *PublishHandlers {
+	t.Helper()
+	templatePath, err := filepath.Abs("../../templates")
+	if err != nil {
+		t.Fatal(err)
+	}
+	site := publish.NewSite(repository.NewPostRepository(testDB), repository.NewPortfolioRepository(testDB), repository.NewPageRepository(testDB), repository.NewSettingsRepository(testDB), templatePath, outputPath)
+	return NewPublishHandlers(publish.NewQueue(site, repository.NewPublishJobRepository(testDB)))
+}
+
+func TestPublishSiteHandlerDryRun(t *testing.T) {
+	testDB := setupTestDB(t)
+	defer testDB.Close()
+
+	// Publishing keeps builds in a sibling directory, so the output path must not be the temp dir itself
+	outputPath := filepath.Join(t.TempDir(), "html-outputs")
+	if err := os.MkdirAll(outputPath, 0755); err != nil {
+		t.Fatal(err)
+	}
+	// A page left behind by an earlier build under a slug that no longer exists,
+	// and a page placed by hand, which no build recorded
+	stalePath := filepath.Join(outputPath, "renamed-post.html")
+	if err := os.WriteFile(stalePath, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	manifest := `{"files": {"renamed-post.html": "old hash"}}`
+	if err := os.WriteFile(filepath.Join(outputPath, generator.ManifestFile), []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	handPlacedPath := filepath.Join(outputPath, "google1234.html")
+	if err := os.WriteFile(handPlacedPath, []byte("site verification"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	h := newTestPublishHandlers(t, testDB, outputPath)
+
+	req := httptest.NewRequest("POST", "/api/publish?dry_run=true&wait=true", nil)
+	w := httptest.NewRecorder()
+	h.PublishSiteHandler(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var response struct {
+		DryRun       bool     `json:"dry_run"`
+		Deleted      int      `json:"deleted"`
+		DeletedFiles []string `json:"deleted_files"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode JSON: %v", err)
+	}
+	if !response.DryRun || response.Deleted != 1 || len(response.DeletedFiles) != 1 || response.DeletedFiles[0] != "renamed-post.html" {
+		t.Errorf("Unexpected dry run response: %+v", response)
+	}
+	if _, err := os.Stat(stalePath); err != nil {
+		t.Error("Dry run should not remove the stale file")
+	}
+	if _, err := os.Stat(filepath.Join(outputPath, "index.html")); !os.IsNotExist(err) {
+		t.Error("Dry run should not generate files")
+	}
+
+	// A real publish removes it
+	req = httptest.NewRequest("POST", "/api/publish?wait=true", nil)
+	w = httptest.NewRecorder()
+	h.PublishSiteHandler(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if _, err := os.Stat(stalePath); !os.IsNotExist(err) {
+		t.Error("Publish should remove the stale file")
+	}
+	if _, err := os.Stat(handPlacedPath); err != nil {
+		t.Error("Publish should keep files no build produced")
+	}
+}
+
+// readEvents reads a Server-Sent Events stream until the done event,
+// returning the IDs and steps of the step events and the data of the done event
+func readEvents(t *testing.T, resp *http.Response) (ids, steps []string, done string) {
+	t.Helper()
+	scanner := bufio.NewScanner(resp.Body)
+	event := ""
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "id: "):
+			ids = append(ids, strings.TrimPrefix(line, "id: "))
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data := strings.TrimPrefix(line, "data: ")
+			if event == "done" {
+				return ids, steps, data
+			}
+			var step publish.Event
+			if err := json.Unmarshal([]byte(data), &step); err != nil {
+				t.Fatalf("Invalid event data %q: %v", data, err)
+			}
+			steps = append(steps, step.Step)
+		}
+	}
+	t.Fatalf("The stream ended without a done event: %v", scanner.Err())
+	return nil, nil, ""
+}
+
+func TestPublishJobEvents(t *testing.T) {
+	testDB := setupTestDB(t)
+	defer testDB.Close()
+
+	h := newTestPublishHandlers(t, testDB, filepath.Join(t.TempDir(), "html-outputs"))
+	r := chi.NewRouter()
+	r.Post("/api/publish", h.PublishSiteHandler)
+	r.Get("/api/publish/jobs", h.GetPublishJobsHandler)
+	r.Get("/api/publish/{job}/events", h.PublishEventsHandler)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	resp, err := http.Post(server.URL+"/api/publish", "application/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var job struct {
+		ID     string `json:"id"`
+		Status string `json:"status"`
+		Events string `json:"events"`
+	}
+	json.NewDecoder(resp.Body).Decode(&job)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted || job.ID == "" || job.Status != models.PublishJobRunning {
+		t.Fatalf("Expected a running job, got %d: %+v", resp.StatusCode, job)
+	}
+
+	resp, err = http.Get(server.URL + job.Events)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Errorf("Unexpected Content-Type %q", resp.Header.Get("Content-Type"))
+	}
+	ids, steps, done := readEvents(t, resp)
+	resp.Body.Close()
+	if len(steps) < 3 || steps[0] != "Publishing started" || steps[len(steps)-1] != "Making the new build live" {
+		t.Errorf("Unexpected steps: %v", steps)
+	}
+	var finished models.PublishJob
+	json.Unmarshal([]byte(done), &finished)
+	if finished.ID != job.ID || finished.Status != models.PublishJobSucceeded || finished.Written == 0 || finished.FinishedAt == nil {
+		t.Errorf("Unexpected finished job: %s", done)
+	}
+
+	// A reconnecting client only gets the events it missed
+	req, _ := http.NewRequest("GET", server.URL+job.Events, nil)
+	req.Header.Set("Last-Event-ID", ids[len(ids)-2])
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resumed, _, _ := readEvents(t, resp)
+	resp.Body.Close()
+	if len(resumed) != 1 || resumed[0] != ids[len(ids)-1] {
+		t.Errorf("Expected only the last event after reconnecting, got %v", resumed)
+	}
+
+	resp, err = http.Get(server.URL + "/api/publish/jobs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var history []models.PublishJob
+	json.NewDecoder(resp.Body).Decode(&history)
+	resp.Body.Close()
+	if len(history) != 1 || history[0].ID != job.ID || history[0].Status != models.PublishJobSucceeded {
+		t.Errorf("Unexpected job history: %+v", history)
+	}
+
+	resp, err = http.Get(server.URL + "/api/publish/unknown/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown job, got %d", resp.StatusCode)
+	}
+}
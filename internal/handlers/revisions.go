@@ -0,0 +1,287 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/models"
+	"github.com/ariefbayu/personal-blog-generator/internal/repository"
+	"github.com/ariefbayu/personal-blog-generator/internal/utils"
+)
+
+type RevisionHandlers struct {
+	postRepo *repository.PostRepository
+	pageRepo *repository.PageRepository
+}
+
+func NewRevisionHandlers(postRepo *repository.PostRepository, pageRepo *repository.PageRepository) *RevisionHandlers {
+	return &RevisionHandlers{
+		postRepo: postRepo,
+		pageRepo: pageRepo,
+	}
+}
+
+func (h *RevisionHandlers) GetPostRevisionsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid post ID", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.postRepo.GetPostByID(id); err != nil {
+		http.Error(w, "Post not found", http.StatusNotFound)
+		return
+	}
+
+	revisions, err := h.postRepo.GetPostRevisions(id)
+	if err != nil {
+		http.Error(w, "Failed to fetch revisions", http.StatusInternalServerError)
+		return
+	}
+	if revisions == nil {
+		revisions = []models.PostRevision{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"revisions": revisions,
+		"total":     len(revisions),
+	})
+}
+
+func (h *RevisionHandlers) GetPostRevisionHandler(w http.ResponseWriter, r *http.Request) {
+	id, rev, ok := parseRevisionParams(w, r, "post")
+	if !ok {
+		return
+	}
+
+	revision, err := h.postRepo.GetPostRevision(id, rev)
+	if err != nil {
+		http.Error(w, "Revision not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(revision)
+}
+
+func (h *RevisionHandlers) RestorePostRevisionHandler(w http.ResponseWriter, r *http.Request) {
+	id, rev, ok := parseRevisionParams(w, r, "post")
+	if !ok {
+		return
+	}
+
+	post, err := h.postRepo.RestorePostRevision(id, rev)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			http.Error(w, "Revision not found", http.StatusNotFound)
+		case strings.Contains(err.Error(), "UNIQUE constraint failed"):
+			http.Error(w, "Slug already exists", http.StatusConflict)
+		default:
+			http.Error(w, "Failed to restore revision", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(post)
+}
+
+func (h *RevisionHandlers) DiffPostRevisionsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid post ID", http.StatusBadRequest)
+		return
+	}
+	from, to, ok := parseDiffParams(w, r)
+	if !ok {
+		return
+	}
+
+	toRev, err := h.postRepo.GetPostRevision(id, to)
+	if err != nil {
+		http.Error(w, "Revision not found", http.StatusNotFound)
+		return
+	}
+	if from == 0 {
+		from = toRev.Revision - 1
+	}
+
+	// Diffing against revision 0 shows the whole first revision as added
+	fromText := ""
+	if from > 0 {
+		fromRev, err := h.postRepo.GetPostRevision(id, from)
+		if err != nil {
+			http.Error(w, "Revision not found", http.StatusNotFound)
+			return
+		}
+		fromText = postRevisionText(fromRev)
+	}
+
+	writeDiffResponse(w, id, from, toRev.Revision, fromText, postRevisionText(toRev))
+}
+
+func (h *RevisionHandlers) GetPageRevisionsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid page ID", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.pageRepo.GetPageByID(id); err != nil {
+		http.Error(w, "Page not found", http.StatusNotFound)
+		return
+	}
+
+	revisions, err := h.pageRepo.GetPageRevisions(id)
+	if err != nil {
+		http.Error(w, "Failed to fetch revisions", http.StatusInternalServerError)
+		return
+	}
+	if revisions == nil {
+		revisions = []models.PageRevision{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"revisions": revisions,
+		"total":     len(revisions),
+	})
+}
+
+func (h *RevisionHandlers) GetPageRevisionHandler(w http.ResponseWriter, r *http.Request) {
+	id, rev, ok := parseRevisionParams(w, r, "page")
+	if !ok {
+		return
+	}
+
+	revision, err := h.pageRepo.GetPageRevision(id, rev)
+	if err != nil {
+		http.Error(w, "Revision not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(revision)
+}
+
+func (h *RevisionHandlers) RestorePageRevisionHandler(w http.ResponseWriter, r *http.Request) {
+	id, rev, ok := parseRevisionParams(w, r, "page")
+	if !ok {
+		return
+	}
+
+	page, err := h.pageRepo.RestorePageRevision(id, rev)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			http.Error(w, "Revision not found", http.StatusNotFound)
+		case strings.Contains(err.Error(), "UNIQUE constraint failed"):
+			http.Error(w, "Slug already exists", http.StatusConflict)
+		default:
+			http.Error(w, "Failed to restore revision", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+func (h *RevisionHandlers) DiffPageRevisionsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid page ID", http.StatusBadRequest)
+		return
+	}
+	from, to, ok := parseDiffParams(w, r)
+	if !ok {
+		return
+	}
+
+	toRev, err := h.pageRepo.GetPageRevision(id, to)
+	if err != nil {
+		http.Error(w, "Revision not found", http.StatusNotFound)
+		return
+	}
+	if from == 0 {
+		from = toRev.Revision - 1
+	}
+
+	fromText := ""
+	if from > 0 {
+		fromRev, err := h.pageRepo.GetPageRevision(id, from)
+		if err != nil {
+			http.Error(w, "Revision not found", http.StatusNotFound)
+			return
+		}
+		fromText = pageRevisionText(fromRev)
+	}
+
+	writeDiffResponse(w, id, from, toRev.Revision, fromText, pageRevisionText(toRev))
+}
+
+// parseRevisionParams reads the {id} and {rev} URL parameters, writing a 400 response when invalid
+func parseRevisionParams(w http.ResponseWriter, r *http.Request, kind string) (int64, int, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid %s ID", kind), http.StatusBadRequest)
+		return 0, 0, false
+	}
+	rev, err := strconv.Atoi(chi.URLParam(r, "rev"))
+	if err != nil || rev < 1 {
+		http.Error(w, "Invalid revision number", http.StatusBadRequest)
+		return 0, 0, false
+	}
+	return id, rev, true
+}
+
+// parseDiffParams reads the optional from and to query parameters.
+// Zero means the latest revision for to, and the revision preceding to for from.
+func parseDiffParams(w http.ResponseWriter, r *http.Request) (int, int, bool) {
+	var from, to int
+	for name, target := range map[string]*int{"from": &from, "to": &to} {
+		value := r.URL.Query().Get(name)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			http.Error(w, fmt.Sprintf("Invalid %s revision", name), http.StatusBadRequest)
+			return 0, 0, false
+		}
+		*target = n
+	}
+	return from, to, true
+}
+
+// writeDiffResponse writes a unified diff between two revision texts as JSON
+func writeDiffResponse(w http.ResponseWriter, id int64, from, to int, fromText, toText string) {
+	diff := utils.UnifiedDiff(fmt.Sprintf("revision %d", from), fmt.Sprintf("revision %d", to), fromText, toText)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"id":   id,
+		"from": from,
+		"to":   to,
+		"diff": diff,
+	})
+}
+
+// postRevisionText renders a post revision as plain text so metadata changes show up in diffs
+func postRevisionText(rev *models.PostRevision) string {
+	return fmt.Sprintf("Title: %s\nSlug: %s\nTags: %s\nFeatured Image: %s\n\n%s\n", rev.Title, rev.Slug, rev.Tags, rev.FeaturedImage, rev.Content)
+}
+
+// pageRevisionText renders a page revision as plain text so metadata changes show up in diffs
+func pageRevisionText(rev *models.PageRevision) string {
+	return fmt.Sprintf("Title: %s\nSlug: %s\n\n%s\n", rev.Title, rev.Slug, rev.Content)
+}
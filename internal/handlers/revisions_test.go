@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/models"
+	"github.com/ariefbayu/personal-blog-generator/internal/repository"
+)
+
+func TestRevisionHandlers(t *testing.T) {
+	// Setup test database
+	db := setupTestDB(t)
+	defer db.Close()
+
+	postRepo := repository.NewPostRepository(db)
+	pageRepo := repository.NewPageRepository(db)
+	handlers := NewRevisionHandlers(postRepo, pageRepo)
+
+	r := chi.NewRouter()
+	r.Get("/api/posts/{id}/revisions", handlers.GetPostRevisionsHandler)
+	r.Get("/api/posts/{id}/revisions/diff", handlers.DiffPostRevisionsHandler)
+	r.Get("/api/posts/{id}/revisions/{rev}", handlers.GetPostRevisionHandler)
+	r.Post("/api/posts/{id}/revisions/{rev}/restore", handlers.RestorePostRevisionHandler)
+	r.Get("/api/pages/{id}/revisions", handlers.GetPageRevisionsHandler)
+	r.Get("/api/pages/{id}/revisions/diff", handlers.DiffPageRevisionsHandler)
+	r.Post("/api/pages/{id}/revisions/{rev}/restore", handlers.RestorePageRevisionHandler)
+
+	post := &models.Post{Title: "Draft", Slug: "draft", Content: "line one\nline two", Published: true, CreatedAt: time.Now()}
+	if err := postRepo.CreatePost(post); err != nil {
+		t.Fatalf("Failed to create post: %v", err)
+	}
+	post.Content = "line one\nline 2"
+	post.UpdatedAt = time.Now()
+	if err := postRepo.UpdatePost(post); err != nil {
+		t.Fatalf("Failed to update post: %v", err)
+	}
+
+	page := &models.Page{Title: "About", Slug: "about", Content: "Hello"}
+	if err := pageRepo.CreatePage(page); err != nil {
+		t.Fatalf("Failed to create page: %v", err)
+	}
+	page.Content = "Oops"
+	if err := pageRepo.UpdatePage(page); err != nil {
+		t.Fatalf("Failed to update page: %v", err)
+	}
+
+	t.Run("ListPostRevisions", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/posts/1/revisions", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+
+		var response struct {
+			Revisions []models.PostRevision `json:"revisions"`
+			Total     int                   `json:"total"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if response.Total != 2 {
+			t.Fatalf("Expected 2 revisions, got %d", response.Total)
+		}
+		if response.Revisions[0].Revision != 2 {
+			t.Errorf("Expected newest revision first, got %d", response.Revisions[0].Revision)
+		}
+	})
+
+	t.Run("GetPostRevision", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/posts/1/revisions/1", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+
+		var rev models.PostRevision
+		json.Unmarshal(w.Body.Bytes(), &rev)
+		if rev.Content != "line one\nline two" {
+			t.Errorf("Expected original content, got '%s'", rev.Content)
+		}
+
+		req = httptest.NewRequest("GET", "/api/posts/1/revisions/99", nil)
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", w.Code)
+		}
+	})
+
+	t.Run("DiffPostRevisions", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/posts/1/revisions/diff", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+
+		var response struct {
+			From int    `json:"from"`
+			To   int    `json:"to"`
+			Diff string `json:"diff"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		if response.From != 1 || response.To != 2 {
+			t.Errorf("Expected diff from 1 to 2, got %d to %d", response.From, response.To)
+		}
+		if !strings.Contains(response.Diff, "-line two\n+line 2\n") {
+			t.Errorf("Unexpected diff:\n%s", response.Diff)
+		}
+	})
+
+	t.Run("RestorePostRevision", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/api/posts/1/revisions/1/restore", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+
+		restored, err := postRepo.GetPostByID(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if restored.Content != "line one\nline two" {
+			t.Errorf("Expected restored content, got '%s'", restored.Content)
+		}
+		if !restored.Published {
+			t.Error("Expected published state to be kept")
+		}
+
+		revisions, _ := postRepo.GetPostRevisions(1)
+		if len(revisions) != 3 {
+			t.Errorf("Expected restore to add a revision, got %d revisions", len(revisions))
+		}
+	})
+
+	t.Run("RestorePageRevision", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/pages/1/revisions/diff?from=1&to=2", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+		if !strings.Contains(w.Body.String(), `-Hello\n+Oops`) {
+			t.Errorf("Unexpected diff response: %s", w.Body.String())
+		}
+
+		req = httptest.NewRequest("POST", "/api/pages/1/revisions/1/restore", nil)
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+
+		restored, err := pageRepo.GetPageByID(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if restored.Content != "Hello" {
+			t.Errorf("Expected restored content 'Hello', got '%s'", restored.Content)
+		}
+	})
+
+	t.Run("MissingPost", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/posts/42/revisions", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", w.Code)
+		}
+	})
+}
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/models"
+	"github.com/ariefbayu/personal-blog-generator/internal/repository"
+)
+
+type TagHandlers struct {
+	tagRepo *repository.TagRepository
+}
+
+func NewTagHandlers(tagRepo *repository.TagRepository) *TagHandlers {
+	return &TagHandlers{tagRepo: tagRepo}
+}
+
+func (h *TagHandlers) GetTagsHandler(w http.ResponseWriter, r *http.Request) {
+	tags, err := h.tagRepo.GetAllTags()
+	if err != nil {
+		http.Error(w, "Failed to fetch tags", http.StatusInternalServerError)
+		return
+	}
+	if tags == nil {
+		tags = []models.Tag{}
+	}
+
+	response := map[string]interface{}{
+		"tags":  tags,
+		"total": len(tags),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *TagHandlers) RenameTagHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := strings.TrimPrefix(r.URL.Path, "/api/tags/")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid tag ID", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(req.Name) == "" {
+		http.Error(w, "Name is required", http.StatusBadRequest)
+		return
+	}
+
+	err = h.tagRepo.RenameTag(id, req.Name)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			http.Error(w, "Tag not found", http.StatusNotFound)
+		case errors.Is(err, repository.ErrTagExists):
+			http.Error(w, "A tag with this name already exists, merge the tags instead", http.StatusConflict)
+		case errors.Is(err, repository.ErrInvalidTagName):
+			http.Error(w, "Tag name is required", http.StatusBadRequest)
+		default:
+			http.Error(w, "Failed to rename tag", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	tag, err := h.tagRepo.GetTagByID(id)
+	if err != nil {
+		http.Error(w, "Failed to fetch tag", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tag)
+}
+
+func (h *TagHandlers) MergeTagsHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		SourceIDs []int64 `json:"source_ids"`
+		TargetID  int64   `json:"target_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if len(req.SourceIDs) == 0 || req.TargetID == 0 {
+		http.Error(w, "source_ids and target_id are required", http.StatusBadRequest)
+		return
+	}
+
+	err := h.tagRepo.MergeTags(req.SourceIDs, req.TargetID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "Tag not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to merge tags", http.StatusInternalServerError)
+		return
+	}
+
+	tag, err := h.tagRepo.GetTagByID(req.TargetID)
+	if err != nil {
+		http.Error(w, "Failed to fetch tag", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tag)
+}
+
+func (h *TagHandlers) DeleteTagHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := strings.TrimPrefix(r.URL.Path, "/api/tags/")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid tag ID", http.StatusBadRequest)
+		return
+	}
+
+	err = h.tagRepo.DeleteTag(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "Tag not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to delete tag", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/models"
+	"github.com/ariefbayu/personal-blog-generator/internal/repository"
+)
+
+func TestTagHandlers(t *testing.T) {
+	// Setup test database
+	db := setupTestDB(t)
+	defer db.Close()
+
+	// Create repositories and handlers
+	postRepo := repository.NewPostRepository(db)
+	tagRepo := repository.NewTagRepository(db)
+	handlers := NewTagHandlers(tagRepo)
+
+	// Variants of the same tag collapse into one
+	posts := []*models.Post{
+		{Title: "Post 1", Slug: "post-1", Tags: "Go, Web", Published: true, CreatedAt: time.Now()},
+		{Title: "Post 2", Slug: "post-2", Tags: " go,golang", Published: true, CreatedAt: time.Now()},
+	}
+	for _, post := range posts {
+		if err := postRepo.CreatePost(post); err != nil {
+			t.Fatalf("Failed to create post: %v", err)
+		}
+	}
+	if posts[1].Tags != "Go,golang" {
+		t.Errorf("Expected canonical tags 'Go,golang', got '%s'", posts[1].Tags)
+	}
+
+	tagsByName := func() map[string]models.Tag {
+		req := httptest.NewRequest("GET", "/api/tags", nil)
+		w := httptest.NewRecorder()
+		handlers.GetTagsHandler(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+		var response struct {
+			Tags []models.Tag `json:"tags"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		result := make(map[string]models.Tag)
+		for _, tag := range response.Tags {
+			result[tag.Name] = tag
+		}
+		return result
+	}
+
+	t.Run("GetTags", func(t *testing.T) {
+		tags := tagsByName()
+		if len(tags) != 3 {
+			t.Fatalf("Expected 3 tags, got %d", len(tags))
+		}
+		if tags["Go"].PostCount != 2 {
+			t.Errorf("Expected Go to have 2 posts, got %d", tags["Go"].PostCount)
+		}
+	})
+
+	t.Run("RenameTagConflict", func(t *testing.T) {
+		tags := tagsByName()
+		body, _ := json.Marshal(map[string]string{"name": "GO"})
+		req := httptest.NewRequest("PUT", fmt.Sprintf("/api/tags/%d", tags["golang"].ID), bytes.NewReader(body))
+		w := httptest.NewRecorder()
+		handlers.RenameTagHandler(w, req)
+		if w.Code != http.StatusConflict {
+			t.Errorf("Expected status 409, got %d", w.Code)
+		}
+	})
+
+	t.Run("RenameTag", func(t *testing.T) {
+		tags := tagsByName()
+		body, _ := json.Marshal(map[string]string{"name": "Golang"})
+		req := httptest.NewRequest("PUT", fmt.Sprintf("/api/tags/%d", tags["Go"].ID), bytes.NewReader(body))
+		w := httptest.NewRecorder()
+		handlers.RenameTagHandler(w, req)
+		if w.Code != http.StatusConflict {
+			t.Errorf("Expected status 409 for slug collision with golang, got %d", w.Code)
+		}
+
+		body, _ = json.Marshal(map[string]string{"name": "Go Lang"})
+		req = httptest.NewRequest("PUT", fmt.Sprintf("/api/tags/%d", tags["Go"].ID), bytes.NewReader(body))
+		w = httptest.NewRecorder()
+		handlers.RenameTagHandler(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+
+		post, err := postRepo.GetPostByID(posts[0].ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if post.Tags != "Go Lang,Web" {
+			t.Errorf("Expected post tags 'Go Lang,Web', got '%s'", post.Tags)
+		}
+	})
+
+	t.Run("MergeTags", func(t *testing.T) {
+		tags := tagsByName()
+		body, _ := json.Marshal(map[string]interface{}{
+			"source_ids": []int64{tags["golang"].ID},
+			"target_id":  tags["Go Lang"].ID,
+		})
+		req := httptest.NewRequest("POST", "/api/tags/merge", bytes.NewReader(body))
+		w := httptest.NewRecorder()
+		handlers.MergeTagsHandler(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+
+		tags = tagsByName()
+		if _, exists := tags["golang"]; exists {
+			t.Error("Expected source tag to be deleted after merge")
+		}
+		post, err := postRepo.GetPostByID(posts[1].ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if post.Tags != "Go Lang" {
+			t.Errorf("Expected post tags 'Go Lang', got '%s'", post.Tags)
+		}
+	})
+
+	t.Run("DeleteTag", func(t *testing.T) {
+		tags := tagsByName()
+		req := httptest.NewRequest("DELETE", fmt.Sprintf("/api/tags/%d", tags["Web"].ID), nil)
+		w := httptest.NewRecorder()
+		handlers.DeleteTagHandler(w, req)
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("Expected status 204, got %d", w.Code)
+		}
+
+		post, err := postRepo.GetPostByID(posts[0].ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if post.Tags != "Go Lang" {
+			t.Errorf("Expected post tags 'Go Lang', got '%s'", post.Tags)
+		}
+
+		req = httptest.NewRequest("DELETE", fmt.Sprintf("/api/tags/%d", tags["Web"].ID), nil)
+		w = httptest.NewRecorder()
+		handlers.DeleteTagHandler(w, req)
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", w.Code)
+		}
+	})
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/auth"
+	"github.com/ariefbayu/personal-blog-generator/internal/models"
+	"github.com/ariefbayu/personal-blog-generator/internal/repository"
+)
+
+type TokenHandlers struct {
+	tokenRepo *repository.APITokenRepository
+	auth      *auth.Authenticator
+}
+
+func NewTokenHandlers(tokenRepo *repository.APITokenRepository, authenticator *auth.Authenticator) *TokenHandlers {
+	return &TokenHandlers{
+		tokenRepo: tokenRepo,
+		auth:      authenticator,
+	}
+}
+
+// GetTokensHandler lists the signed-in user's API tokens and the scopes a token can have
+func (h *TokenHandlers) GetTokensHandler(w http.ResponseWriter, r *http.Request) {
+	user := auth.UserFromContext(r.Context())
+	tokens, err := h.tokenRepo.GetAPITokens(user.ID)
+	if err != nil {
+		http.Error(w, "Failed to fetch tokens", http.StatusInternalServerError)
+		return
+	}
+	if tokens == nil {
+		tokens = []models.APIToken{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"tokens": tokens,
+		"scopes": auth.Scopes,
+	})
+}
+
+// CreateTokenHandler creates an API token. The plain token is only returned in this response.
+func (h *TokenHandlers) CreateTokenHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name      string     `json:"name"`
+		Scopes    []string   `json:"scopes"`
+		ExpiresAt *time.Time `json:"expires_at"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		writeAuthError(w, "Expiry must be in the future", http.StatusBadRequest)
+		return
+	}
+
+	user := auth.UserFromContext(r.Context())
+	token, plain, err := h.auth.CreateAPIToken(user, req.Name, req.Scopes, req.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidScope) || errors.Is(err, auth.ErrTokenNameRequired) {
+			writeAuthError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Failed to create token", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"token":     plain,
+		"api_token": token,
+	})
+}
+
+func (h *TokenHandlers) RevokeTokenHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid token ID", http.StatusBadRequest)
+		return
+	}
+
+	user := auth.UserFromContext(r.Context())
+	if err := h.tokenRepo.RevokeAPIToken(id, user.ID); err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Token not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to revoke token", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
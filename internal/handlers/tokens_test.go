@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/auth"
+	"github.com/ariefbayu/personal-blog-generator/internal/repository"
+)
+
+func TestTokenHandlers(t *testing.T) {
+	testDB := setupTestDB(t)
+	defer testDB.Close()
+
+	userRepo := repository.NewUserRepository(testDB)
+	tokenRepo := repository.NewAPITokenRepository(testDB)
+	tokenHandlers := NewTokenHandlers(tokenRepo, auth.NewAuthenticator(userRepo, tokenRepo))
+
+	user, err := auth.CreateInitialUser(userRepo, "admin", "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := chi.NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			next.ServeHTTP(w, req.WithContext(auth.WithUser(req.Context(), user)))
+		})
+	})
+	r.Get("/api/tokens", tokenHandlers.GetTokensHandler)
+	r.Post("/api/tokens", tokenHandlers.CreateTokenHandler)
+	r.Delete("/api/tokens/{id}", tokenHandlers.RevokeTokenHandler)
+
+	req := httptest.NewRequest("POST", "/api/tokens", strings.NewReader(`{"name":"ci","scopes":["bogus"]}`))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for unknown scope, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest("POST", "/api/tokens", strings.NewReader(`{"name":"ci","scopes":["posts:write"],"expires_at":"2000-01-01T00:00:00Z"}`))
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for past expiry, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest("POST", "/api/tokens", strings.NewReader(`{"name":"ci","scopes":["posts:write","publish"]}`))
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var created struct {
+		Token    string `json:"token"`
+		APIToken struct {
+			ID     int64    `json:"id"`
+			Scopes []string `json:"scopes"`
+		} `json:"api_token"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&created); err != nil {
+		t.Fatalf("Failed to decode JSON: %v", err)
+	}
+	if !strings.HasPrefix(created.Token, "pbg_") || len(created.APIToken.Scopes) != 2 {
+		t.Errorf("Unexpected created token: %+v", created)
+	}
+
+	req = httptest.NewRequest("GET", "/api/tokens", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if strings.Contains(w.Body.String(), created.Token) {
+		t.Error("Listing tokens must not reveal the token")
+	}
+
+	req = httptest.NewRequest("DELETE", fmt.Sprintf("/api/tokens/%d", created.APIToken.ID), nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Expected status 204, got %d", w.Code)
+	}
+
+	// Revoking twice reports the token as gone
+	req = httptest.NewRequest("DELETE", fmt.Sprintf("/api/tokens/%d", created.APIToken.ID), nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+}
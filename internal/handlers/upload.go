@@ -16,6 +16,7 @@ const (
 )
 
 var (
+	// uploadDir overrides the upload directory, which is otherwise OUTPUT_PATH/images
 	uploadDir = ""
 )
 
@@ -41,12 +42,16 @@ func UploadImageHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	outputPath := os.Getenv("OUTPUT_PATH")
-	if outputPath == "" {
-		http.Error(w, "OUTPUT_PATH not configured", http.StatusInternalServerError)
-		return
+	// Resolve the directory per request, so a change of OUTPUT_PATH is picked up
+	dir := uploadDir
+	if dir == "" {
+		outputPath := os.Getenv("OUTPUT_PATH")
+		if outputPath == "" {
+			http.Error(w, "OUTPUT_PATH not configured", http.StatusInternalServerError)
+			return
+		}
+		dir = filepath.Join(outputPath, "images")
 	}
-	uploadDir = filepath.Join(outputPath, "images")
 
 	// Parse multipart form with max memory
 	err := r.ParseMultipartForm(maxUploadSize)
@@ -90,13 +95,13 @@ func UploadImageHandler(w http.ResponseWriter, r *http.Request) {
 	filename := id + ext
 
 	// Ensure upload directory exists
-	if err := os.MkdirAll(uploadDir, 0755); err != nil {
+	if err := os.MkdirAll(dir, 0755); err != nil {
 		http.Error(w, "Unable to create upload directory", http.StatusInternalServerError)
 		return
 	}
 
 	// Create destination file
-	dstPath := filepath.Join(uploadDir, filename)
+	dstPath := filepath.Join(dir, filename)
 	dst, err := os.Create(dstPath)
 	if err != nil {
 		http.Error(w, "Unable to create file", http.StatusInternalServerError)
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -162,4 +163,37 @@ func TestUploadImageHandler_FileTooLarge(t *testing.T) {
 	if w.Code != http.StatusBadRequest {
 		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
 	}
-}
\ No newline at end of file
+}
+func TestUploadImageHandler_ResolvesOutputPathPerRequest(t *testing.T) {
+	upload := func() {
+		t.Helper()
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		part, err := writer.CreateFormFile("image", "test.png")
+		if err != nil {
+			t.Fatal(err)
+		}
+		part.Write([]byte{0x89, 0x50, 0x4E, 0x47, 0x0D, 0x0A, 0x1A, 0x0A})
+		writer.Close()
+
+		req := httptest.NewRequest(http.MethodPost, "/api/upload/image", body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		w := httptest.NewRecorder()
+		UploadImageHandler(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+		}
+	}
+
+	for _, outputPath := range []string{t.TempDir(), t.TempDir()} {
+		t.Setenv("OUTPUT_PATH", outputPath)
+		upload()
+		files, err := os.ReadDir(filepath.Join(outputPath, "images"))
+		if err != nil || len(files) != 1 {
+			t.Errorf("expected one file in %s/images, got %d (%v)", outputPath, len(files), err)
+		}
+	}
+	if uploadDir != "" {
+		t.Errorf("the upload directory should not be remembered, got %q", uploadDir)
+	}
+}
@@ -0,0 +1,348 @@
+package importer
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var (
+	blankLinesRegex = regexp.MustCompile(`\n[ \t]*\n`)
+	extraLinesRegex = regexp.MustCompile(`\n{3,}`)
+	spaceRegex      = regexp.MustCompile(`[ \t\r\n]+`)
+	blockTagRegex   = regexp.MustCompile(`(?i)^<(p|div|h[1-6]|ul|ol|li|blockquote|pre|table|figure|hr|iframe|!--)\b`)
+)
+
+// HTMLToMarkdown converts post HTML into Markdown. Elements without a Markdown
+// equivalent, such as tables and embeds, are kept as raw HTML.
+func HTMLToMarkdown(src string) string {
+	return htmlToMarkdown(src, nil)
+}
+
+// htmlToMarkdown converts src, passing every link and image URL through rewrite when it is set
+func htmlToMarkdown(src string, rewrite func(string) string) string {
+	nodes, err := html.ParseFragment(strings.NewReader(autoParagraph(src)), &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body})
+	if err != nil {
+		return src
+	}
+
+	root := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	for _, n := range nodes {
+		root.AppendChild(n)
+	}
+
+	c := converter{rewrite: rewrite}
+	out := c.blocks(root, "\n\n")
+	return strings.TrimSpace(extraLinesRegex.ReplaceAllString(out, "\n\n")) + "\n"
+}
+
+// autoParagraph wraps text separated by blank lines in <p> tags, the way WordPress
+// renders classic editor content. HTML that already uses <p> is left alone.
+func autoParagraph(src string) string {
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+	if strings.Contains(strings.ToLower(src), "<p") {
+		return src
+	}
+
+	var b strings.Builder
+	for _, block := range blankLinesRegex.Split(src, -1) {
+		block = strings.TrimSpace(block)
+		if block == "" {
+			continue
+		}
+		if blockTagRegex.MatchString(block) {
+			b.WriteString(block)
+		} else {
+			b.WriteString("<p>" + strings.ReplaceAll(block, "\n", "<br>\n") + "</p>")
+		}
+		b.WriteString("\n\n")
+	}
+	return b.String()
+}
+
+type converter struct {
+	rewrite func(string) string
+}
+
+func (c converter) url(u string) string {
+	if c.rewrite == nil {
+		return u
+	}
+	return c.rewrite(u)
+}
+
+func isBlock(n *html.Node) bool {
+	if n.Type != html.ElementNode {
+		return false
+	}
+	switch n.DataAtom {
+	case atom.P, atom.Div, atom.Section, atom.Article, atom.Header, atom.Footer, atom.Main, atom.Aside,
+		atom.Figure, atom.Figcaption, atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6,
+		atom.Ul, atom.Ol, atom.Blockquote, atom.Pre, atom.Hr, atom.Table, atom.Iframe, atom.Video, atom.Audio:
+		return true
+	}
+	return false
+}
+
+// blocks renders the children of n as Markdown blocks joined by sep
+func (c converter) blocks(n *html.Node, sep string) string {
+	var out []string
+	var inline strings.Builder
+
+	flush := func() {
+		if text := cleanInline(inline.String()); text != "" {
+			out = append(out, text)
+		}
+		inline.Reset()
+	}
+
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if !isBlock(child) {
+			inline.WriteString(c.inline(child))
+			continue
+		}
+		flush()
+		if block := c.block(child); block != "" {
+			out = append(out, block)
+		}
+	}
+	flush()
+
+	return strings.Join(out, sep)
+}
+
+func (c converter) block(n *html.Node) string {
+	switch n.DataAtom {
+	case atom.P:
+		return cleanInline(c.inlineChildren(n))
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		level := int(n.Data[1] - '0')
+		return strings.Repeat("#", level) + " " + cleanInline(c.inlineChildren(n))
+	case atom.Ul, atom.Ol:
+		return c.list(n)
+	case atom.Blockquote:
+		return prefixLines(c.blocks(n, "\n\n"), "> ")
+	case atom.Pre:
+		return codeBlock(n)
+	case atom.Hr:
+		return "---"
+	case atom.Figcaption:
+		if text := cleanInline(c.inlineChildren(n)); text != "" {
+			return "*" + text + "*"
+		}
+		return ""
+	case atom.Table, atom.Iframe, atom.Video, atom.Audio:
+		return c.raw(n)
+	}
+	return c.blocks(n, "\n\n")
+}
+
+func (c converter) list(n *html.Node) string {
+	var items []string
+	number := 1
+	if start, err := strconv.Atoi(attr(n, "start")); err == nil {
+		number = start
+	}
+
+	for li := n.FirstChild; li != nil; li = li.NextSibling {
+		if li.Type != html.ElementNode || li.DataAtom != atom.Li {
+			continue
+		}
+		marker := "- "
+		if n.DataAtom == atom.Ol {
+			marker = strconv.Itoa(number) + ". "
+			number++
+		}
+		body := c.blocks(li, "\n")
+		indent := strings.Repeat(" ", len(marker))
+		items = append(items, marker+strings.ReplaceAll(body, "\n", "\n"+indent))
+	}
+	return strings.Join(items, "\n")
+}
+
+func (c converter) inlineChildren(n *html.Node) string {
+	var b strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		b.WriteString(c.inline(child))
+	}
+	return b.String()
+}
+
+func (c converter) inline(n *html.Node) string {
+	switch n.Type {
+	case html.TextNode:
+		return escapeMarkdown(spaceRegex.ReplaceAllString(n.Data, " "))
+	case html.ElementNode:
+	default:
+		return ""
+	}
+
+	switch n.DataAtom {
+	case atom.Br:
+		return "  \n"
+	case atom.Strong, atom.B:
+		return wrap(c.inlineChildren(n), "**")
+	case atom.Em, atom.I:
+		return wrap(c.inlineChildren(n), "*")
+	case atom.Del, atom.S, atom.Strike:
+		return wrap(c.inlineChildren(n), "~~")
+	case atom.Code, atom.Kbd, atom.Tt:
+		return inlineCode(textContent(n))
+	case atom.A:
+		text := c.inlineChildren(n)
+		href := attr(n, "href")
+		if href == "" {
+			return text
+		}
+		if strings.TrimSpace(text) == "" {
+			text = href
+		}
+		return "[" + strings.TrimSpace(text) + "](" + c.url(href) + titleSuffix(attr(n, "title")) + ")"
+	case atom.Img:
+		src := attr(n, "src")
+		if src == "" {
+			return ""
+		}
+		return "![" + escapeMarkdown(attr(n, "alt")) + "](" + c.url(src) + titleSuffix(attr(n, "title")) + ")"
+	case atom.Script, atom.Style, atom.Noscript:
+		return ""
+	case atom.Sup, atom.Sub, atom.Iframe, atom.Video, atom.Audio:
+		return c.raw(n)
+	}
+	return c.inlineChildren(n)
+}
+
+// codeBlock renders a <pre> element as a fenced code block, keeping the language of a nested <code class="language-x">
+func codeBlock(n *html.Node) string {
+	lang := ""
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.DataAtom == atom.Code {
+			for _, class := range strings.Fields(attr(child, "class")) {
+				if strings.HasPrefix(class, "language-") {
+					lang = strings.TrimPrefix(class, "language-")
+				}
+			}
+		}
+	}
+
+	code := strings.Trim(textContent(n), "\n")
+	fence := "```"
+	for strings.Contains(code, fence) {
+		fence += "`"
+	}
+	return fence + lang + "\n" + code + "\n" + fence
+}
+
+func inlineCode(text string) string {
+	if strings.Contains(text, "`") {
+		return "`` " + text + " ``"
+	}
+	return "`" + text + "`"
+}
+
+// wrap surrounds text with a Markdown marker, keeping surrounding spaces outside of it
+func wrap(text, marker string) string {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return text
+	}
+	start := text[:strings.Index(text, trimmed)]
+	end := text[len(start)+len(trimmed):]
+	return start + marker + trimmed + marker + end
+}
+
+func titleSuffix(title string) string {
+	if title == "" {
+		return ""
+	}
+	return ` "` + strings.ReplaceAll(title, `"`, `\"`) + `"`
+}
+
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`, "<", "&lt;")
+
+func escapeMarkdown(text string) string {
+	return markdownEscaper.Replace(text)
+}
+
+// cleanInline trims rendered inline content and the spaces left around line breaks
+func cleanInline(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if i > 0 {
+			line = strings.TrimLeft(line, " ")
+		}
+		if strings.HasSuffix(line, "  ") {
+			line = strings.TrimRight(line, " ") + "  "
+		}
+		lines[i] = line
+	}
+	text = strings.TrimSpace(strings.Join(lines, "\n"))
+	return strings.TrimSuffix(text, "  ")
+}
+
+func prefixLines(text, prefix string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if line == "" {
+			lines[i] = strings.TrimRight(prefix, " ")
+		} else {
+			lines[i] = prefix + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func textContent(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var b strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.DataAtom == atom.Br {
+			b.WriteString("\n")
+			continue
+		}
+		b.WriteString(textContent(child))
+	}
+	return b.String()
+}
+
+func attr(n *html.Node, name string) string {
+	for _, a := range n.Attr {
+		if a.Key == name {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// raw renders n as HTML, rewriting the URLs of the media and links inside it
+func (c converter) raw(n *html.Node) string {
+	if c.rewrite != nil {
+		rewriteURLs(n, c.rewrite)
+	}
+	return renderHTML(n)
+}
+
+func rewriteURLs(n *html.Node, rewrite func(string) string) {
+	for i, a := range n.Attr {
+		if a.Key == "src" || a.Key == "poster" || (a.Key == "href" && n.DataAtom == atom.A) {
+			n.Attr[i].Val = rewrite(a.Val)
+		}
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		rewriteURLs(child, rewrite)
+	}
+}
+
+func renderHTML(n *html.Node) string {
+	var buf bytes.Buffer
+	if err := html.Render(&buf, n); err != nil {
+		return ""
+	}
+	return buf.String()
+}
@@ -0,0 +1,50 @@
+package importer
+
+import "testing"
+
+func TestHTMLToMarkdown(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{
+			name: "inline formatting",
+			html: `<p>Some <strong>bold</strong>, <em>italic </em>and <code>code</code> with a <a href="https://example.com" title="Example">link</a>.</p>`,
+			want: "Some **bold**, *italic* and `code` with a [link](https://example.com \"Example\").\n",
+		},
+		{
+			name: "classic editor paragraphs",
+			html: "First line\nsecond line\n\nSecond paragraph with a_b",
+			want: "First line  \nsecond line\n\nSecond paragraph with a\\_b\n",
+		},
+		{
+			name: "headings and lists",
+			html: `<h2>Title</h2><ul><li>One</li><li>Two<ol start="3"><li>Three</li></ol></li></ul>`,
+			want: "## Title\n\n- One\n- Two\n  3. Three\n",
+		},
+		{
+			name: "code block and quote",
+			html: "<pre><code class=\"language-go\">fmt.Println(\"&lt;hi&gt;\")\n</code></pre><blockquote><p>Quoted</p><p>Twice</p></blockquote>",
+			want: "```go\nfmt.Println(\"<hi>\")\n```\n\n> Quoted\n>\n> Twice\n",
+		},
+		{
+			name: "gutenberg image",
+			html: "<!-- wp:image -->\n<figure class=\"wp-block-image\"><img src=\"/a.png\" alt=\"An image\"/><figcaption>Caption</figcaption></figure>\n<!-- /wp:image -->",
+			want: "![An image](/a.png)\n\n*Caption*\n",
+		},
+		{
+			name: "table kept as HTML",
+			html: `<p>Before</p><table><tr><td>cell</td></tr></table>`,
+			want: "Before\n\n<table><tbody><tr><td>cell</td></tr></tbody></table>\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HTMLToMarkdown(tt.html); got != tt.want {
+				t.Errorf("got:\n%q\nwant:\n%q", got, tt.want)
+			}
+		})
+	}
+}
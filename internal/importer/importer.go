@@ -0,0 +1,248 @@
+// Package importer brings posts and pages over from other blog engines.
+package importer
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/models"
+	"github.com/ariefbayu/personal-blog-generator/internal/repository"
+)
+
+// Options controls how imported items are saved
+type Options struct {
+	// DryRun reports what would be imported without writing anything
+	DryRun bool
+	// Overwrite updates existing posts and pages with the same slug instead of skipping them
+	Overwrite bool
+}
+
+// Item is a post or a page read from a source, not yet saved
+type Item struct {
+	Source string
+	Post   *models.Post
+	Page   *models.Page
+}
+
+func (it Item) kind() string {
+	if it.Page != nil {
+		return "page"
+	}
+	return "post"
+}
+
+func (it Item) slug() string {
+	if it.Page != nil {
+		return it.Page.Slug
+	}
+	return it.Post.Slug
+}
+
+func (it Item) title() string {
+	if it.Page != nil {
+		return it.Page.Title
+	}
+	return it.Post.Title
+}
+
+// Conflict is an imported item whose slug is already taken
+type Conflict struct {
+	Kind   string `json:"kind"`
+	Slug   string `json:"slug"`
+	Title  string `json:"title"`
+	Source string `json:"source"`
+	// Reason is "exists" when the slug is in the database and "duplicate" when an earlier item in the same import used it
+	Reason string `json:"reason"`
+	// Overwritten is set when the existing item was updated
+	Overwritten bool `json:"overwritten"`
+}
+
+// Skipped is a source entry that was not imported
+type Skipped struct {
+	Source string `json:"source"`
+	Reason string `json:"reason"`
+}
+
+// Redirect is an old URL of an imported item, to be redirected by the web server
+type Redirect struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Report summarizes an import
+type Report struct {
+	DryRun       bool       `json:"dry_run"`
+	PostsCreated int        `json:"posts_created"`
+	PostsUpdated int        `json:"posts_updated"`
+	PagesCreated int        `json:"pages_created"`
+	PagesUpdated int        `json:"pages_updated"`
+	Drafts       int        `json:"drafts"`
+	Conflicts    []Conflict `json:"conflicts"`
+	Skipped      []Skipped  `json:"skipped"`
+	Media        []Media    `json:"media"`
+	Redirects    []Redirect `json:"redirects"`
+	Warnings     []string   `json:"warnings"`
+}
+
+func (r *Report) skip(source, format string, args ...interface{}) {
+	r.Skipped = append(r.Skipped, Skipped{Source: source, Reason: fmt.Sprintf(format, args...)})
+}
+
+func (r *Report) warn(format string, args ...interface{}) {
+	r.Warnings = append(r.Warnings, fmt.Sprintf(format, args...))
+}
+
+// Print writes the report in a human-readable form
+func (r *Report) Print(w io.Writer) {
+	if r.DryRun {
+		fmt.Fprintln(w, "Dry run: nothing was written")
+	}
+	fmt.Fprintf(w, "Posts: %d created, %d updated (%d drafts)\n", r.PostsCreated, r.PostsUpdated, r.Drafts)
+	fmt.Fprintf(w, "Pages: %d created, %d updated\n", r.PagesCreated, r.PagesUpdated)
+
+	for _, c := range r.Conflicts {
+		action := "skipped"
+		if c.Overwritten {
+			action = "overwritten"
+		}
+		reason := "already exists"
+		if c.Reason == "duplicate" {
+			reason = "is used twice in this import"
+		}
+		fmt.Fprintf(w, "Conflict: %s %q (%s) %s, %s\n", c.Kind, c.Slug, c.Source, reason, action)
+	}
+	for _, s := range r.Skipped {
+		fmt.Fprintf(w, "Skipped: %s: %s\n", s.Source, s.Reason)
+	}
+	if len(r.Media) > 0 {
+		counts := map[string]int{}
+		for _, m := range r.Media {
+			counts[m.Status]++
+		}
+		fmt.Fprintf(w, "Media: %d downloaded, %d copied, %d relinked, %d failed",
+			counts[MediaDownloaded], counts[MediaCopied], counts[MediaRelinked], counts[MediaFailed])
+		if r.DryRun {
+			fmt.Fprintf(w, ", %d to download", counts[MediaPlanned])
+		}
+		fmt.Fprintln(w)
+		for _, m := range r.Media {
+			if m.Status == MediaFailed {
+				fmt.Fprintf(w, "Media failed: %s: %s\n", m.URL, m.Error)
+			}
+		}
+	}
+	for _, redirect := range r.Redirects {
+		fmt.Fprintf(w, "Redirect: %s -> %s\n", redirect.From, redirect.To)
+	}
+	for _, warning := range r.Warnings {
+		fmt.Fprintf(w, "Warning: %s\n", warning)
+	}
+}
+
+// Importer saves items from any source through the post and page repositories
+type Importer struct {
+	postRepo *repository.PostRepository
+	pageRepo *repository.PageRepository
+}
+
+func NewImporter(postRepo *repository.PostRepository, pageRepo *repository.PageRepository) *Importer {
+	return &Importer{postRepo: postRepo, pageRepo: pageRepo}
+}
+
+// Save creates the items, reporting slugs that are already taken.
+// Taken slugs are skipped unless opts.Overwrite is set, and nothing is written on a dry run.
+func (im *Importer) Save(items []Item, opts Options, report *Report) error {
+	report.DryRun = opts.DryRun
+	seen := map[string]bool{}
+
+	for _, item := range items {
+		key := item.kind() + ":" + item.slug()
+		if item.slug() == "" {
+			report.skip(item.Source, "no slug could be derived from the title")
+			continue
+		}
+		if seen[key] {
+			report.Conflicts = append(report.Conflicts, Conflict{Kind: item.kind(), Slug: item.slug(), Title: item.title(), Source: item.Source, Reason: "duplicate"})
+			continue
+		}
+		seen[key] = true
+
+		var err error
+		if item.Page != nil {
+			err = im.savePage(item, opts, report)
+		} else {
+			err = im.savePost(item, opts, report)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to import %s %q from %s: %w", item.kind(), item.slug(), item.Source, err)
+		}
+	}
+	return nil
+}
+
+func (im *Importer) savePost(item Item, opts Options, report *Report) error {
+	post := item.Post
+	existing, err := im.postRepo.GetPostBySlug(post.Slug)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+
+	if existing != nil {
+		conflict := Conflict{Kind: "post", Slug: post.Slug, Title: post.Title, Source: item.Source, Reason: "exists", Overwritten: opts.Overwrite}
+		report.Conflicts = append(report.Conflicts, conflict)
+		if !opts.Overwrite {
+			return nil
+		}
+		report.PostsUpdated++
+	} else {
+		report.PostsCreated++
+	}
+	if !post.Published {
+		report.Drafts++
+	}
+	if opts.DryRun {
+		return nil
+	}
+
+	if existing != nil {
+		post.ID = existing.ID
+		if post.UpdatedAt.IsZero() {
+			post.UpdatedAt = time.Now()
+		}
+		return im.postRepo.UpdatePost(post)
+	}
+	if post.CreatedAt.IsZero() {
+		post.CreatedAt = time.Now()
+	}
+	return im.postRepo.CreatePost(post)
+}
+
+func (im *Importer) savePage(item Item, opts Options, report *Report) error {
+	page := item.Page
+	existing, err := im.pageRepo.GetPageBySlug(page.Slug)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+
+	if existing != nil {
+		conflict := Conflict{Kind: "page", Slug: page.Slug, Title: page.Title, Source: item.Source, Reason: "exists", Overwritten: opts.Overwrite}
+		report.Conflicts = append(report.Conflicts, conflict)
+		if !opts.Overwrite {
+			return nil
+		}
+		report.PagesUpdated++
+	} else {
+		report.PagesCreated++
+	}
+	if opts.DryRun {
+		return nil
+	}
+
+	if existing != nil {
+		page.ID = existing.ID
+		return im.pageRepo.UpdatePage(page)
+	}
+	return im.pageRepo.CreatePage(page)
+}
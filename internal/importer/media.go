@@ -0,0 +1,257 @@
+package importer
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/utils"
+)
+
+// Media statuses
+const (
+	MediaDownloaded = "downloaded"
+	MediaCopied     = "copied"
+	MediaRelinked   = "relinked"
+	MediaPlanned    = "planned"
+	MediaFailed     = "failed"
+)
+
+// maxMediaSize limits the size of a downloaded media file
+const maxMediaSize = 20 << 20
+
+// uploadsMarker is the part of a WordPress media URL before the path inside the uploads directory
+const uploadsMarker = "/wp-content/uploads/"
+
+var mediaExtensions = map[string]bool{
+	".jpg":  true,
+	".jpeg": true,
+	".png":  true,
+	".gif":  true,
+	".webp": true,
+}
+
+var mediaTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// Media is a referenced image and where it ended up
+type Media struct {
+	URL    string `json:"url"`
+	Path   string `json:"path,omitempty"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// MediaOptions controls how referenced images are brought into OUTPUT_PATH/images
+type MediaOptions struct {
+	// ImagesDir receives the images. Links are left untouched when it is empty.
+	ImagesDir string
+	// UploadsDir is a local copy of wp-content/uploads, used before downloading
+	UploadsDir string
+	// Download fetches images that are not available locally
+	Download bool
+	// Client is used for downloads, defaulting to a client with a 30 second timeout
+	Client *http.Client
+}
+
+// mediaLocalizer copies each referenced image into the images directory once and
+// maps its URL to the /images path it is served from
+type mediaLocalizer struct {
+	opts   MediaOptions
+	dryRun bool
+	report *Report
+	seen   map[string]string
+}
+
+func newMediaLocalizer(opts MediaOptions, dryRun bool, report *Report) *mediaLocalizer {
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &mediaLocalizer{opts: opts, dryRun: dryRun, report: report, seen: map[string]string{}}
+}
+
+// localize returns the local URL for an image URL, or the URL itself when it is not an image or could not be fetched
+func (m *mediaLocalizer) localize(raw string) string {
+	if m.opts.ImagesDir == "" {
+		return raw
+	}
+	if local, ok := m.seen[raw]; ok {
+		return local
+	}
+
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https" && !strings.HasPrefix(raw, "//")) {
+		return raw
+	}
+	if u.Scheme == "" {
+		u.Scheme = "https"
+	}
+	name, uploadPath := mediaFileName(u)
+	if name == "" {
+		return raw
+	}
+
+	source := ""
+	if uploadPath != "" && m.opts.UploadsDir != "" {
+		if p := filepath.Join(m.opts.UploadsDir, filepath.FromSlash(uploadPath)); fileExists(p) {
+			source = p
+		}
+	}
+
+	media := Media{URL: raw}
+	var data []byte
+	switch {
+	case source != "":
+		media.Status = MediaCopied
+		data, err = os.ReadFile(source)
+	case !m.opts.Download:
+		err = fmt.Errorf("not found locally and downloads are disabled")
+	case m.dryRun:
+		media.Status = MediaPlanned
+	default:
+		media.Status = MediaDownloaded
+		data, err = m.download(u.String())
+	}
+
+	if err == nil && data != nil {
+		name, err = m.place(name, data, &media)
+	}
+
+	local := "/images/" + name
+	media.Path = local
+	if err != nil {
+		media.Status = MediaFailed
+		media.Path = ""
+		media.Error = err.Error()
+		local = raw
+	}
+	m.report.Media = append(m.report.Media, media)
+	m.seen[raw] = local
+	return local
+}
+
+// place stores an image under name and returns the name it ended up with. An existing file is reused
+// only when its content is identical; otherwise the image gets a name qualified by a hash of its content,
+// so an unrelated image is never overwritten or linked in its place.
+func (m *mediaLocalizer) place(name string, data []byte, media *Media) (string, error) {
+	target := filepath.Join(m.opts.ImagesDir, name)
+	existing, err := os.ReadFile(target)
+	if err == nil && !bytes.Equal(existing, data) {
+		sum := sha256.Sum256(data)
+		ext := path.Ext(name)
+		name = strings.TrimSuffix(name, ext) + "-" + hex.EncodeToString(sum[:4]) + ext
+		target = filepath.Join(m.opts.ImagesDir, name)
+		existing, err = os.ReadFile(target)
+	}
+	if err == nil && bytes.Equal(existing, data) {
+		media.Status = MediaRelinked
+		return name, nil
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+
+	if !m.dryRun {
+		if err := writeMedia(target, bytes.NewReader(data)); err != nil {
+			return "", err
+		}
+	}
+	return name, nil
+}
+
+func (m *mediaLocalizer) download(src string) ([]byte, error) {
+	resp, err := m.opts.Client.Get(src)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxMediaSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxMediaSize {
+		return nil, fmt.Errorf("file is larger than %d MB", maxMediaSize>>20)
+	}
+	if contentType := http.DetectContentType(data); !mediaTypes[contentType] {
+		return nil, fmt.Errorf("unexpected content type %s", contentType)
+	}
+	return data, nil
+}
+
+// mediaFileName derives a file name for an image URL. WordPress uploads keep their
+// year and month (2019/05/photo.jpg becomes 2019-05-photo.jpg) so equal names from
+// different months do not collide. Other images add a hash of their host and path
+// (photo.jpg becomes photo-1a2b3c4d.jpg), as their base names alone say little.
+// It also returns the path inside the uploads directory.
+func mediaFileName(u *url.URL) (string, string) {
+	ext := strings.ToLower(path.Ext(u.Path))
+	if !mediaExtensions[ext] {
+		return "", ""
+	}
+
+	sum := sha256.Sum256([]byte(strings.ToLower(u.Host) + u.Path))
+	hash := hex.EncodeToString(sum[:4])
+
+	uploadPath := ""
+	name := path.Base(u.Path)
+	if i := strings.Index(u.Path, uploadsMarker); i >= 0 {
+		uploadPath = u.Path[i+len(uploadsMarker):]
+		name = strings.ReplaceAll(uploadPath, "/", "-")
+	}
+
+	base := utils.Slugify(strings.TrimSuffix(name, path.Ext(name)))
+	switch {
+	case base == "":
+		// Names without letters or digits, such as emoji, still get a name of their own
+		base = "image-" + hash
+	case uploadPath == "":
+		base += "-" + hash
+	}
+	return base + ext, uploadPath
+}
+
+// writeMedia writes through a temporary file so an interrupted import never leaves a partial image behind
+func writeMedia(target string, r io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".import-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), target)
+}
+
+func fileExists(name string) bool {
+	info, err := os.Stat(name)
+	return err == nil && !info.IsDir()
+}
@@ -0,0 +1,73 @@
+package importer
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMediaFileName(t *testing.T) {
+	name := func(raw string) string {
+		t.Helper()
+		u, err := url.Parse(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		name, _ := mediaFileName(u)
+		return name
+	}
+
+	if got := name("https://example.com/wp-content/uploads/2019/05/photo.JPG"); got != "2019-05-photo.jpg" {
+		t.Errorf("Unexpected upload name %q", got)
+	}
+	if got := name("https://example.com/notes.txt"); got != "" {
+		t.Errorf("Expected no name for a non-image, got %q", got)
+	}
+
+	a, b := name("https://cdn.example.com/a/photo.jpg"), name("https://cdn.example.com/b/photo.jpg")
+	other := name("https://images.example.org/a/photo.jpg")
+	if !strings.HasPrefix(a, "photo-") || a == b || a == other || b == other {
+		t.Errorf("Expected distinct names for different images, got %q, %q and %q", a, b, other)
+	}
+
+	if got := name("https://example.com/%E5%86%99%E7%9C%9F.png"); !strings.HasPrefix(got, "写真-") {
+		t.Errorf("Expected a name kept from a non-ASCII file, got %q", got)
+	}
+	if got := name("https://example.com/%F0%9F%8E%89.png"); !strings.HasPrefix(got, "image-") || !strings.HasSuffix(got, ".png") {
+		t.Errorf("Expected a fallback name, got %q", got)
+	}
+}
+
+func TestMediaKeepsDifferentExistingFile(t *testing.T) {
+	server := mediaServer(t)
+	imagesDir := t.TempDir()
+	existing := filepath.Join(imagesDir, "2019-05-photo.png")
+	if err := os.WriteFile(existing, []byte("another image"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	report := &Report{}
+	m := newMediaLocalizer(MediaOptions{ImagesDir: imagesDir, Download: true}, false, report)
+	local := m.localize(server.URL + "/wp-content/uploads/2019/05/photo.png")
+	if local == "/images/2019-05-photo.png" || !strings.HasPrefix(local, "/images/2019-05-photo-") {
+		t.Errorf("Expected the image under a new name, got %q", local)
+	}
+	if len(report.Media) != 1 || report.Media[0].Status != MediaDownloaded {
+		t.Errorf("Expected a download, got %+v", report.Media)
+	}
+	if data, _ := os.ReadFile(existing); string(data) != "another image" {
+		t.Error("The existing file should not be overwritten")
+	}
+
+	// A later import finds the identical file and relinks it
+	report = &Report{}
+	m = newMediaLocalizer(MediaOptions{ImagesDir: imagesDir, Download: true}, false, report)
+	if again := m.localize(server.URL + "/wp-content/uploads/2019/05/photo.png"); again != local {
+		t.Errorf("Expected %q again, got %q", local, again)
+	}
+	if len(report.Media) != 1 || report.Media[0].Status != MediaRelinked {
+		t.Errorf("Expected the identical file to be relinked, got %+v", report.Media)
+	}
+}
@@ -0,0 +1,393 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/models"
+	"github.com/ariefbayu/personal-blog-generator/internal/utils"
+)
+
+var (
+	// jekyllNameRegex matches Jekyll post file names like 2019-05-01-my-post.md
+	jekyllNameRegex = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})-(.+)$`)
+	templateRegex   = regexp.MustCompile(`\{\{[<%]|\{%`)
+)
+
+var staticDateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05 -07:00",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// ErrUnknownLayout is returned when a directory is neither a Hugo nor a Jekyll site
+var ErrUnknownLayout = errors.New("no content, _posts or Markdown files found")
+
+// ImportStaticSite imports the posts and pages of a Hugo or Jekyll site
+func (im *Importer) ImportStaticSite(fsys fs.FS, opts Options) (*Report, error) {
+	report := &Report{}
+	items, err := ReadStaticSite(fsys, report)
+	if err != nil {
+		return nil, err
+	}
+	if err := im.Save(items, opts, report); err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+// staticFile is a content file found in a site and what it becomes
+type staticFile struct {
+	path  string
+	page  bool
+	draft bool
+	// date and slug come from a Jekyll file name
+	date time.Time
+	slug string
+}
+
+// ReadStaticSite finds the content files of a Hugo site (content/), a Jekyll site
+// (_posts/ and _drafts/) or a bare Hugo content directory, and parses them into items.
+// Aliases and old permalinks are reported as redirects to the new URLs.
+func ReadStaticSite(fsys fs.FS, report *Report) ([]Item, error) {
+	files, err := findStaticFiles(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []Item
+	for _, file := range files {
+		item, ok, err := readStaticFile(fsys, file, report)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+func findStaticFiles(fsys fs.FS) ([]staticFile, error) {
+	var files []staticFile
+	switch {
+	case isDir(fsys, "_posts"):
+		for _, dir := range []string{"_posts", "_drafts"} {
+			if !isDir(fsys, dir) {
+				continue
+			}
+			found, err := walkContent(fsys, dir, func(name string) staticFile {
+				file := staticFile{path: name, draft: dir == "_drafts"}
+				base := strings.TrimSuffix(path.Base(name), path.Ext(name))
+				if m := jekyllNameRegex.FindStringSubmatch(base); m != nil {
+					file.date, _ = time.Parse("2006-01-02", m[1])
+					file.slug = m[2]
+				} else {
+					file.slug = base
+				}
+				return file
+			})
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, found...)
+		}
+
+		// Pages are the Markdown files at the top of the site
+		entries, err := fs.ReadDir(fsys, ".")
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			ext := path.Ext(name)
+			base := strings.TrimSuffix(name, ext)
+			if entry.IsDir() || (ext != ".md" && ext != ".markdown") || strings.EqualFold(base, "readme") || base == "index" {
+				continue
+			}
+			files = append(files, staticFile{path: name, page: true, slug: base})
+		}
+
+	default:
+		root := "."
+		if isDir(fsys, "content") {
+			root = "content"
+		}
+		found, err := walkContent(fsys, root, func(name string) staticFile {
+			rel := strings.TrimPrefix(strings.TrimPrefix(name, root), "/")
+			base := strings.TrimSuffix(path.Base(rel), path.Ext(rel))
+			// A page bundle's index.md takes the name of its directory
+			if base == "index" && path.Dir(rel) != "." {
+				base = path.Base(path.Dir(rel))
+			}
+			// Files directly in content/ are standalone pages, files in a section are posts
+			return staticFile{path: name, page: !strings.Contains(rel, "/"), slug: base}
+		})
+		if err != nil {
+			return nil, err
+		}
+		files = found
+	}
+
+	if len(files) == 0 {
+		return nil, ErrUnknownLayout
+	}
+	return files, nil
+}
+
+// walkContent lists the Markdown and HTML files below dir, skipping Hugo's _index.md list pages
+func walkContent(fsys fs.FS, dir string, describe func(name string) staticFile) ([]staticFile, error) {
+	var files []staticFile
+	err := fs.WalkDir(fsys, dir, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		switch path.Ext(name) {
+		case ".md", ".markdown", ".html":
+		default:
+			return nil
+		}
+		if strings.HasPrefix(path.Base(name), "_index.") {
+			return nil
+		}
+		files = append(files, describe(name))
+		return nil
+	})
+	return files, err
+}
+
+func readStaticFile(fsys fs.FS, file staticFile, report *Report) (Item, bool, error) {
+	data, err := fs.ReadFile(fsys, file.path)
+	if err != nil {
+		return Item{}, false, err
+	}
+
+	format, header, body := splitStaticFrontMatter(string(data))
+	fm := frontMatter{}
+	switch format {
+	case "yaml":
+		err = yaml.Unmarshal([]byte(header), &fm)
+	case "toml":
+		err = toml.Unmarshal([]byte(header), &fm)
+	default:
+		if file.page {
+			// Without front matter a top-level file is not content, e.g. a LICENSE.md
+			return Item{}, false, nil
+		}
+	}
+	if err != nil {
+		report.skip(file.path, "invalid %s front matter: %v", format, err)
+		return Item{}, false, nil
+	}
+
+	if path.Ext(file.path) == ".html" {
+		body = HTMLToMarkdown(body)
+	}
+	body = strings.TrimLeft(body, "\n")
+	if templateRegex.MatchString(body) {
+		report.warn("%s uses shortcodes or Liquid tags, which are kept as text", file.path)
+	}
+
+	title := fm.str("title")
+	if title == "" {
+		title = strings.ReplaceAll(file.slug, "-", " ")
+	}
+	slug := strings.Trim(fm.str("slug"), "/")
+	if slug == "" {
+		slug = utils.Slugify(file.slug)
+	}
+
+	date := fm.date("date")
+	if date.IsZero() {
+		date = file.date
+	}
+	lastmod := fm.date("lastmod", "last_modified_at", "updated")
+	draft := file.draft || fm.boolean("draft", false) || !fm.boolean("published", true)
+
+	for _, alias := range append(fm.list("aliases", "redirect_from"), fm.str("url"), fm.str("permalink")) {
+		if alias != "" {
+			report.Redirects = append(report.Redirects, Redirect{From: alias, To: "/" + slug + ".html"})
+		}
+	}
+
+	kind := strings.ToLower(fm.str("type") + " " + fm.str("layout"))
+	if file.page || strings.Contains(kind, "page") {
+		if draft {
+			report.skip(file.path, "pages cannot be drafts, so draft page %q was not imported", title)
+			return Item{}, false, nil
+		}
+		return Item{Source: file.path, Page: &models.Page{
+			Title:     title,
+			Slug:      slug,
+			Content:   body,
+			ShowInNav: fm.boolean("show_in_nav", false) || fm.has("menu"),
+			SortOrder: fm.integer("weight", "nav_order"),
+			CreatedAt: date,
+			UpdatedAt: lastmod,
+		}}, true, nil
+	}
+
+	post := &models.Post{
+		Title:         title,
+		Slug:          slug,
+		Content:       body,
+		Tags:          strings.Join(uniqueTags(fm.list("tags", "categories")), ", "),
+		FeaturedImage: fm.featuredImage(),
+		Published:     !draft,
+		CreatedAt:     date,
+		UpdatedAt:     lastmod,
+	}
+	if publishAt := fm.date("publishDate", "publishdate"); !publishAt.IsZero() && publishAt.After(time.Now()) && !draft {
+		post.PublishAt = &publishAt
+	}
+	return Item{Source: file.path, Post: post}, true, nil
+}
+
+// splitStaticFrontMatter splits YAML (---) or TOML (+++) front matter from the body
+func splitStaticFrontMatter(text string) (string, string, string) {
+	text = strings.ReplaceAll(strings.TrimPrefix(text, "\ufeff"), "\r\n", "\n")
+	formats := map[string]string{"---": "yaml", "+++": "toml"}
+
+	firstLine, rest, found := strings.Cut(text, "\n")
+	format, ok := formats[strings.TrimSpace(firstLine)]
+	if !found || !ok {
+		return "", "", text
+	}
+
+	delimiter := strings.TrimSpace(firstLine)
+	lines := strings.SplitAfter(rest, "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) == delimiter {
+			return format, strings.Join(lines[:i], ""), strings.Join(lines[i+1:], "")
+		}
+	}
+	return "", "", text
+}
+
+// frontMatter holds decoded YAML or TOML front matter
+type frontMatter map[string]interface{}
+
+func (fm frontMatter) has(key string) bool {
+	_, ok := fm[key]
+	return ok
+}
+
+func (fm frontMatter) str(key string) string {
+	switch v := fm[key].(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func (fm frontMatter) boolean(key string, fallback bool) bool {
+	switch v := fm[key].(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true" || v == "yes"
+	}
+	return fallback
+}
+
+func (fm frontMatter) integer(keys ...string) int {
+	for _, key := range keys {
+		switch v := fm[key].(type) {
+		case int:
+			return v
+		case int64:
+			return int(v)
+		case float64:
+			return int(v)
+		}
+	}
+	return 0
+}
+
+// list reads lists of strings, or comma or space separated strings as Jekyll allows
+func (fm frontMatter) list(keys ...string) []string {
+	var values []string
+	for _, key := range keys {
+		switch v := fm[key].(type) {
+		case []interface{}:
+			for _, item := range v {
+				values = append(values, strings.TrimSpace(fmt.Sprint(item)))
+			}
+		case string:
+			if strings.Contains(v, ",") {
+				values = append(values, utils.ParseTags(v)...)
+			} else {
+				values = append(values, strings.Fields(v)...)
+			}
+		}
+	}
+	return values
+}
+
+func (fm frontMatter) date(keys ...string) time.Time {
+	for _, key := range keys {
+		switch v := fm[key].(type) {
+		case time.Time:
+			return v
+		case string:
+			for _, layout := range staticDateLayouts {
+				if t, err := time.Parse(layout, strings.TrimSpace(v)); err == nil {
+					return t
+				}
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// featuredImage reads the image keys used by common Hugo and Jekyll themes
+func (fm frontMatter) featuredImage() string {
+	for _, key := range []string{"featured_image", "featuredImage", "image", "cover"} {
+		switch v := fm[key].(type) {
+		case string:
+			return v
+		case map[string]interface{}:
+			return frontMatter(v).str("image")
+		case frontMatter:
+			// yaml.v3 decodes nested maps into the type of the outer map
+			return v.str("image")
+		}
+	}
+	return ""
+}
+
+func uniqueTags(tags []string) []string {
+	var out []string
+	seen := map[string]bool{}
+	for _, tag := range tags {
+		if tag == "" || seen[strings.ToLower(tag)] {
+			continue
+		}
+		seen[strings.ToLower(tag)] = true
+		out = append(out, tag)
+	}
+	return out
+}
+
+func isDir(fsys fs.FS, name string) bool {
+	info, err := fs.Stat(fsys, name)
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,119 @@
+package importer
+
+import (
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestImportHugoSite(t *testing.T) {
+	im, postRepo, pageRepo := setupImporter(t)
+	site := fstest.MapFS{
+		"config.toml":                {Data: []byte(`title = "Old site"`)},
+		"content/_index.md":          {Data: []byte("---\ntitle: Home\n---\n")},
+		"content/about.md":           {Data: []byte("+++\ntitle = \"About\"\nweight = 3\n[menu]\nmain = {}\n+++\nAbout me.\n")},
+		"content/posts/one.md":       {Data: []byte("+++\ntitle = \"One\"\ndate = 2021-03-04T05:06:07Z\ntags = [\"go\", \"hugo\"]\naliases = [\"/2021/03/one/\"]\n+++\n\nFirst post with {{< figure src=\"x.png\" >}}\n")},
+		"content/posts/two/index.md": {Data: []byte("---\ntitle: Two\ndate: 2022-01-02\ndraft: true\ncategories: [notes]\ncover:\n  image: /img/two.png\n---\nSecond.\n")},
+		"content/posts/three.md":     {Data: []byte("---\ntitle: Three\nslug: custom-three\n---\nThird.\n")},
+	}
+
+	report, err := im.ImportStaticSite(site, Options{DryRun: true})
+	if err != nil {
+		t.Fatalf("Dry run failed: %v", err)
+	}
+	if report.PostsCreated != 3 || report.PagesCreated != 1 || report.Drafts != 1 {
+		t.Errorf("Unexpected dry run report: %+v", report)
+	}
+	if _, err := postRepo.GetPostBySlug("one"); err == nil {
+		t.Error("Dry run should not create posts")
+	}
+
+	report, err = im.ImportStaticSite(site, Options{})
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if len(report.Redirects) != 1 || report.Redirects[0] != (Redirect{From: "/2021/03/one/", To: "/one.html"}) {
+		t.Errorf("Unexpected redirects: %+v", report.Redirects)
+	}
+	if len(report.Warnings) != 1 {
+		t.Errorf("Expected a shortcode warning, got %v", report.Warnings)
+	}
+
+	one, err := postRepo.GetPostBySlug("one")
+	if err != nil {
+		t.Fatalf("Post one not found: %v", err)
+	}
+	if one.Tags != "go,hugo" || !one.Published || !one.CreatedAt.Equal(time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)) {
+		t.Errorf("Unexpected post: %+v", one)
+	}
+
+	two, err := postRepo.GetPostBySlug("two")
+	if err != nil {
+		t.Fatalf("Page bundle not imported: %v", err)
+	}
+	if two.Published || two.Tags != "notes" || two.FeaturedImage != "/img/two.png" {
+		t.Errorf("Expected unpublished draft with cover image, got %+v", two)
+	}
+
+	if _, err := postRepo.GetPostBySlug("custom-three"); err != nil {
+		t.Errorf("Expected the front matter slug to be used: %v", err)
+	}
+
+	about, err := pageRepo.GetPageBySlug("about")
+	if err != nil {
+		t.Fatalf("Page not imported: %v", err)
+	}
+	if !about.ShowInNav || about.SortOrder != 3 {
+		t.Errorf("Unexpected page: %+v", about)
+	}
+}
+
+func TestImportJekyllSite(t *testing.T) {
+	im, postRepo, pageRepo := setupImporter(t)
+	site := fstest.MapFS{
+		"_config.yml":                       {Data: []byte("title: Old site\n")},
+		"README.md":                         {Data: []byte("# Site source\n")},
+		"index.md":                          {Data: []byte("---\nlayout: home\n---\n")},
+		"contact.md":                        {Data: []byte("---\nlayout: page\ntitle: Contact\n---\nMail me.\n")},
+		"_posts/2019-05-01-hello-jekyll.md": {Data: []byte("---\nlayout: post\ntitle: Hello Jekyll\ntags: ruby static\nredirect_from: /old-hello/\n---\nHello.\n")},
+		"_posts/2019-06-01-hidden.md":       {Data: []byte("---\ntitle: Hidden\npublished: false\n---\nHidden.\n")},
+		"_drafts/upcoming.md":               {Data: []byte("---\ntitle: Upcoming\n---\nSoon.\n")},
+	}
+
+	report, err := im.ImportStaticSite(site, Options{})
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if report.PostsCreated != 3 || report.Drafts != 2 || report.PagesCreated != 1 {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+
+	post, err := postRepo.GetPostBySlug("hello-jekyll")
+	if err != nil {
+		t.Fatalf("Post not imported: %v", err)
+	}
+	if post.Tags != "ruby,static" || !post.Published || post.CreatedAt.Format("2006-01-02") != "2019-05-01" {
+		t.Errorf("Unexpected post: %+v", post)
+	}
+	for _, slug := range []string{"hidden", "upcoming"} {
+		draft, err := postRepo.GetPostBySlug(slug)
+		if err != nil || draft.Published {
+			t.Errorf("Expected %s to be an unpublished draft, got %+v (%v)", slug, draft, err)
+		}
+	}
+	if _, err := pageRepo.GetPageBySlug("contact"); err != nil {
+		t.Errorf("Expected contact page: %v", err)
+	}
+	if _, err := pageRepo.GetPageBySlug("readme"); err == nil {
+		t.Error("A README without front matter should not become a page")
+	}
+
+	// A second run reports every slug as a conflict
+	report, err = im.ImportStaticSite(site, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Conflicts) != 4 || report.PostsCreated != 0 {
+		t.Errorf("Expected conflicts on the second run, got %+v", report)
+	}
+}
@@ -0,0 +1,190 @@
+package importer
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/models"
+	"github.com/ariefbayu/personal-blog-generator/internal/utils"
+)
+
+// wxrDateLayout is the layout of wp:post_date and wp:post_date_gmt
+const wxrDateLayout = "2006-01-02 15:04:05"
+
+var captionRegex = regexp.MustCompile(`(?s)\[caption[^\]]*\](.*?)\[/caption\]`)
+
+type wxrFile struct {
+	Channel struct {
+		Items []wxrItem `xml:"item"`
+	} `xml:"channel"`
+}
+
+type wxrItem struct {
+	Title         string        `xml:"title"`
+	PubDate       string        `xml:"pubDate"`
+	Content       string        `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	PostID        string        `xml:"post_id"`
+	PostDate      string        `xml:"post_date"`
+	PostDateGMT   string        `xml:"post_date_gmt"`
+	Modified      string        `xml:"post_modified"`
+	ModifiedGMT   string        `xml:"post_modified_gmt"`
+	PostName      string        `xml:"post_name"`
+	Status        string        `xml:"status"`
+	PostType      string        `xml:"post_type"`
+	MenuOrder     int           `xml:"menu_order"`
+	AttachmentURL string        `xml:"attachment_url"`
+	Categories    []wxrCategory `xml:"category"`
+	Meta          []wxrMeta     `xml:"postmeta"`
+}
+
+type wxrCategory struct {
+	Domain   string `xml:"domain,attr"`
+	Nicename string `xml:"nicename,attr"`
+	Name     string `xml:",chardata"`
+}
+
+type wxrMeta struct {
+	Key   string `xml:"meta_key"`
+	Value string `xml:"meta_value"`
+}
+
+func (it wxrItem) meta(key string) string {
+	for _, m := range it.Meta {
+		if m.Key == key {
+			return m.Value
+		}
+	}
+	return ""
+}
+
+// WordPressOptions controls a WordPress import
+type WordPressOptions struct {
+	Options
+	Media MediaOptions
+}
+
+// ImportWordPress imports the posts and pages of a WordPress export (WXR) file.
+// Post HTML is converted to Markdown, categories and tags become post tags, and
+// referenced images are brought into opts.Media.ImagesDir and relinked.
+func (im *Importer) ImportWordPress(r io.Reader, opts WordPressOptions) (*Report, error) {
+	report := &Report{}
+	items, err := ReadWordPress(r, opts.Media, opts.DryRun, report)
+	if err != nil {
+		return nil, err
+	}
+	if err := im.Save(items, opts.Options, report); err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+// ReadWordPress parses a WXR file into items, localizing media as it goes
+func ReadWordPress(r io.Reader, media MediaOptions, dryRun bool, report *Report) ([]Item, error) {
+	decoder := xml.NewDecoder(r)
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity
+	decoder.AutoClose = xml.HTMLAutoClose
+
+	var file wxrFile
+	if err := decoder.Decode(&file); err != nil {
+		return nil, fmt.Errorf("invalid WordPress export: %w", err)
+	}
+
+	attachments := map[string]string{}
+	for _, item := range file.Channel.Items {
+		if item.PostType == "attachment" && item.AttachmentURL != "" {
+			attachments[item.PostID] = item.AttachmentURL
+		}
+	}
+
+	localizer := newMediaLocalizer(media, dryRun, report)
+	var items []Item
+	for _, item := range file.Channel.Items {
+		source := fmt.Sprintf("%s %s", item.PostType, item.PostID)
+		if item.PostType != "post" && item.PostType != "page" {
+			continue
+		}
+
+		switch item.Status {
+		case "publish", "future", "draft", "pending", "private":
+		default:
+			report.skip(source, "status %q is not imported", item.Status)
+			continue
+		}
+
+		title := strings.TrimSpace(item.Title)
+		slug := item.PostName
+		if slug == "" {
+			slug = utils.Slugify(title)
+		}
+		created := wxrDate(item.PostDateGMT, item.PostDate, item.PubDate)
+		updated := wxrDate(item.ModifiedGMT, item.Modified, "")
+		content := htmlToMarkdown(captionRegex.ReplaceAllString(item.Content, "$1"), localizer.localize)
+
+		if item.PostType == "page" {
+			if item.Status != "publish" {
+				report.skip(source, "pages cannot be drafts, so %s page %q was not imported", item.Status, title)
+				continue
+			}
+			items = append(items, Item{Source: source, Page: &models.Page{
+				Title:     title,
+				Slug:      slug,
+				Content:   content,
+				SortOrder: item.MenuOrder,
+				CreatedAt: created,
+				UpdatedAt: updated,
+			}})
+			continue
+		}
+
+		post := &models.Post{
+			Title:     title,
+			Slug:      slug,
+			Content:   content,
+			Tags:      strings.Join(wxrTags(item.Categories), ", "),
+			Published: item.Status == "publish" || item.Status == "future",
+			CreatedAt: created,
+			UpdatedAt: updated,
+		}
+		if item.Status == "future" && !created.IsZero() {
+			publishAt := created
+			post.PublishAt = &publishAt
+		}
+		if item.Status == "private" {
+			report.warn("private post %q was imported as a draft", slug)
+		}
+		if thumbnail := attachments[item.meta("_thumbnail_id")]; thumbnail != "" {
+			post.FeaturedImage = localizer.localize(thumbnail)
+		}
+		items = append(items, Item{Source: source, Post: post})
+	}
+	return items, nil
+}
+
+// wxrTags collects the categories and tags of an item, leaving out WordPress's default category
+func wxrTags(categories []wxrCategory) []string {
+	var names []string
+	for _, c := range categories {
+		if (c.Domain == "category" || c.Domain == "post_tag") && c.Nicename != "uncategorized" {
+			names = append(names, strings.TrimSpace(c.Name))
+		}
+	}
+	return uniqueTags(names)
+}
+
+// wxrDate parses the first usable date: the GMT date, then the site-local date read as UTC, then the RSS pubDate
+func wxrDate(gmt, local, pubDate string) time.Time {
+	for _, value := range []string{gmt, local} {
+		if t, err := time.Parse(wxrDateLayout, strings.TrimSpace(value)); err == nil && t.Year() > 1 {
+			return t
+		}
+	}
+	if t, err := time.Parse(time.RFC1123Z, strings.TrimSpace(pubDate)); err == nil {
+		return t.UTC()
+	}
+	return time.Time{}
+}
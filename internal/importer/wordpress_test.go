@@ -0,0 +1,207 @@
+package importer
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/db"
+	"github.com/ariefbayu/personal-blog-generator/internal/models"
+	"github.com/ariefbayu/personal-blog-generator/internal/repository"
+)
+
+const testWXR = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/" xmlns:wp="http://wordpress.org/export/1.2/">
+<channel>
+	<title>Old Blog</title>
+	<item>
+		<title>Hello &amp; Welcome</title>
+		<content:encoded><![CDATA[<p>Hi there.</p>
+<p><img src="MEDIA/wp-content/uploads/2019/05/photo.png" alt="Photo"></p>
+<p><img src="MEDIA/missing.png" alt="Gone"></p>]]></content:encoded>
+		<wp:post_id>10</wp:post_id>
+		<wp:post_date><![CDATA[2019-05-01 10:00:00]]></wp:post_date>
+		<wp:post_date_gmt><![CDATA[2019-05-01 08:00:00]]></wp:post_date_gmt>
+		<wp:post_name><![CDATA[hello-welcome]]></wp:post_name>
+		<wp:status><![CDATA[publish]]></wp:status>
+		<wp:post_type><![CDATA[post]]></wp:post_type>
+		<category domain="category" nicename="uncategorized"><![CDATA[Uncategorized]]></category>
+		<category domain="category" nicename="travel"><![CDATA[Travel]]></category>
+		<category domain="post_tag" nicename="go"><![CDATA[Go]]></category>
+		<wp:postmeta><wp:meta_key><![CDATA[_thumbnail_id]]></wp:meta_key><wp:meta_value><![CDATA[11]]></wp:meta_value></wp:postmeta>
+	</item>
+	<item>
+		<title>Cover</title>
+		<wp:post_id>11</wp:post_id>
+		<wp:status><![CDATA[inherit]]></wp:status>
+		<wp:post_type><![CDATA[attachment]]></wp:post_type>
+		<wp:attachment_url><![CDATA[MEDIA/wp-content/uploads/2019/05/cover.png]]></wp:attachment_url>
+	</item>
+	<item>
+		<title>Work in progress</title>
+		<content:encoded><![CDATA[Draft text]]></content:encoded>
+		<wp:post_id>12</wp:post_id>
+		<wp:post_date><![CDATA[2020-01-01 00:00:00]]></wp:post_date>
+		<wp:post_date_gmt><![CDATA[0000-00-00 00:00:00]]></wp:post_date_gmt>
+		<wp:post_name><![CDATA[]]></wp:post_name>
+		<wp:status><![CDATA[draft]]></wp:status>
+		<wp:post_type><![CDATA[post]]></wp:post_type>
+	</item>
+	<item>
+		<title>Taken</title>
+		<content:encoded><![CDATA[Imported body]]></content:encoded>
+		<wp:post_id>13</wp:post_id>
+		<wp:post_name><![CDATA[taken]]></wp:post_name>
+		<wp:status><![CDATA[publish]]></wp:status>
+		<wp:post_type><![CDATA[post]]></wp:post_type>
+	</item>
+	<item>
+		<title>About</title>
+		<content:encoded><![CDATA[About me]]></content:encoded>
+		<wp:post_id>14</wp:post_id>
+		<wp:post_name><![CDATA[about]]></wp:post_name>
+		<wp:status><![CDATA[publish]]></wp:status>
+		<wp:post_type><![CDATA[page]]></wp:post_type>
+		<wp:menu_order>2</wp:menu_order>
+	</item>
+	<item>
+		<title>Deleted</title>
+		<wp:post_id>15</wp:post_id>
+		<wp:status><![CDATA[trash]]></wp:status>
+		<wp:post_type><![CDATA[post]]></wp:post_type>
+	</item>
+</channel>
+</rss>`
+
+func setupImporter(t *testing.T) (*Importer, *repository.PostRepository, *repository.PageRepository) {
+	t.Helper()
+	testDB, err := db.Connect(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to connect to test DB: %v", err)
+	}
+	t.Cleanup(func() { testDB.Close() })
+	if err := db.Migrate(testDB); err != nil {
+		t.Fatalf("Failed to migrate test DB: %v", err)
+	}
+	postRepo := repository.NewPostRepository(testDB)
+	pageRepo := repository.NewPageRepository(testDB)
+	return NewImporter(postRepo, pageRepo), postRepo, pageRepo
+}
+
+func mediaServer(t *testing.T) *httptest.Server {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 1, 1))); err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/wp-content/uploads/") {
+			w.Write(buf.Bytes())
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestImportWordPress(t *testing.T) {
+	im, postRepo, pageRepo := setupImporter(t)
+	server := mediaServer(t)
+	wxr := strings.ReplaceAll(testWXR, "MEDIA", server.URL)
+	imagesDir := filepath.Join(t.TempDir(), "images")
+
+	if err := postRepo.CreatePost(&models.Post{Title: "Taken", Slug: "taken", Content: "Original"}); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := WordPressOptions{Media: MediaOptions{ImagesDir: imagesDir, Download: true}}
+	opts.DryRun = true
+	report, err := im.ImportWordPress(strings.NewReader(wxr), opts)
+	if err != nil {
+		t.Fatalf("Dry run failed: %v", err)
+	}
+	if report.PostsCreated != 2 || report.PagesCreated != 1 {
+		t.Errorf("Unexpected dry run report: %+v", report)
+	}
+	if _, err := postRepo.GetPostBySlug("hello-welcome"); err == nil {
+		t.Error("Dry run should not create posts")
+	}
+	if _, err := os.Stat(imagesDir); !os.IsNotExist(err) {
+		t.Error("Dry run should not download media")
+	}
+
+	opts.DryRun = false
+	report, err = im.ImportWordPress(strings.NewReader(wxr), opts)
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if report.PostsCreated != 2 || report.Drafts != 1 || report.PagesCreated != 1 {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+	if len(report.Conflicts) != 1 || report.Conflicts[0].Slug != "taken" || report.Conflicts[0].Overwritten {
+		t.Errorf("Expected a skipped conflict for taken, got %+v", report.Conflicts)
+	}
+	if len(report.Skipped) != 1 || report.Skipped[0].Source != "post 15" {
+		t.Errorf("Expected the trashed post to be skipped, got %+v", report.Skipped)
+	}
+
+	post, err := postRepo.GetPostBySlug("hello-welcome")
+	if err != nil {
+		t.Fatalf("Imported post not found: %v", err)
+	}
+	if post.Title != "Hello & Welcome" || post.Tags != "Travel,Go" || !post.Published {
+		t.Errorf("Unexpected post: %+v", post)
+	}
+	if got := post.CreatedAt.UTC().Format(wxrDateLayout); got != "2019-05-01 08:00:00" {
+		t.Errorf("Expected the GMT post date, got %s", got)
+	}
+	if !strings.Contains(post.Content, "![Photo](/images/2019-05-photo.png)") || !strings.Contains(post.Content, server.URL+"/missing.png") {
+		t.Errorf("Expected media to be relinked, got:\n%s", post.Content)
+	}
+	if post.FeaturedImage != "/images/2019-05-cover.png" {
+		t.Errorf("Unexpected featured image %q", post.FeaturedImage)
+	}
+	if _, err := os.Stat(filepath.Join(imagesDir, "2019-05-photo.png")); err != nil {
+		t.Errorf("Expected downloaded image: %v", err)
+	}
+
+	draft, err := postRepo.GetPostBySlug("work-in-progress")
+	if err != nil || draft.Published {
+		t.Errorf("Expected unpublished draft with a slug from its title, got %+v (%v)", draft, err)
+	}
+
+	taken, _ := postRepo.GetPostBySlug("taken")
+	if taken.Content != "Original" {
+		t.Error("Conflicting post should not be overwritten")
+	}
+
+	page, err := pageRepo.GetPageBySlug("about")
+	if err != nil || page.SortOrder != 2 {
+		t.Errorf("Unexpected page %+v (%v)", page, err)
+	}
+
+	// Importing again relinks the existing images and overwrites on request
+	opts.Overwrite = true
+	report, err = im.ImportWordPress(strings.NewReader(wxr), opts)
+	if err != nil {
+		t.Fatalf("Second import failed: %v", err)
+	}
+	if report.PostsUpdated != 3 || report.PostsCreated != 0 {
+		t.Errorf("Expected all posts to be updated, got %+v", report)
+	}
+	for _, m := range report.Media {
+		if m.Status != MediaRelinked && m.Status != MediaFailed {
+			t.Errorf("Expected existing media to be relinked, got %+v", m)
+		}
+	}
+	taken, _ = postRepo.GetPostBySlug("taken")
+	if taken.Content != "Imported body\n" {
+		t.Errorf("Expected overwritten post, got %q", taken.Content)
+	}
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// Deployment statuses
+const (
+	DeploymentSucceeded = "succeeded"
+	DeploymentFailed    = "failed"
+)
+
+// Deployment records one upload of the generated site to a deployment target
+type Deployment struct {
+	ID        int64  `db:"id" json:"id"`
+	Target    string `db:"target" json:"target"`
+	Status    string `db:"status" json:"status"`
+	Uploaded  int    `db:"uploaded" json:"uploaded"`
+	Deleted   int    `db:"deleted" json:"deleted"`
+	Unchanged int    `db:"unchanged" json:"unchanged"`
+	Bytes     int64  `db:"bytes" json:"bytes"`
+	// Error is set when the deployment failed
+	Error      string    `db:"error" json:"error"`
+	StartedAt  time.Time `db:"started_at" json:"started_at"`
+	FinishedAt time.Time `db:"finished_at" json:"finished_at"`
+}
@@ -3,13 +3,23 @@ package models
 import "time"
 
 type Post struct {
-	ID            int64     `db:"id" json:"id"`
-	Title         string    `db:"title" json:"title"`
-	Slug          string    `db:"slug" json:"slug"`
-	Content       string    `db:"content" json:"content"`
-	Tags          string    `db:"tags" json:"tags"`
-	FeaturedImage string    `db:"featured_image" json:"featuredImage"`
-	Published     bool      `db:"published" json:"published"`
-	CreatedAt     time.Time `db:"created_at" json:"created_at"`
-	UpdatedAt     time.Time `db:"updated_at" json:"updated_at"`
+	ID            int64      `db:"id" json:"id"`
+	Title         string     `db:"title" json:"title"`
+	Slug          string     `db:"slug" json:"slug"`
+	Content       string     `db:"content" json:"content"`
+	Tags          string     `db:"tags" json:"tags"`
+	FeaturedImage string     `db:"featured_image" json:"featuredImage"`
+	Published     bool       `db:"published" json:"published"`
+	PublishAt     *time.Time `db:"publish_at" json:"publish_at"`
+	Scheduled     bool       `db:"-" json:"scheduled"`
+	// Snippet is escaped HTML around the matched text of a search, with matches wrapped in <mark>
+	Snippet   string    `db:"-" json:"snippet,omitempty"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// IsDue reports whether a published post is visible at the given time.
+// Posts without a publish_at timestamp are visible as soon as they are published.
+func (p *Post) IsDue(now time.Time) bool {
+	return p.Published && (p.PublishAt == nil || !p.PublishAt.After(now))
 }
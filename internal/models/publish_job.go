@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// Publish job statuses
+const (
+	PublishJobRunning   = "running"
+	PublishJobSucceeded = "succeeded"
+	PublishJobFailed    = "failed"
+)
+
+// Sources that start publish jobs
+const (
+	PublishSourceManual   = "manual"
+	PublishSourceSchedule = "schedule"
+	PublishSourceDeploy   = "deploy"
+)
+
+// PublishJob records one background build of the site
+type PublishJob struct {
+	ID      string `db:"id" json:"id"`
+	Source  string `db:"source" json:"source"`
+	DryRun  bool   `db:"dry_run" json:"dry_run"`
+	Status  string `db:"status" json:"status"`
+	Written int    `db:"written" json:"written"`
+	Skipped int    `db:"skipped" json:"skipped"`
+	Deleted int    `db:"deleted" json:"deleted"`
+	// DeletedFiles is only known while the job is kept in memory, it is not stored
+	DeletedFiles []string `db:"-" json:"deleted_files,omitempty"`
+	// FailedPages lists the pages that failed to render. Like DeletedFiles it is not stored.
+	FailedPages []string `db:"-" json:"failed_pages,omitempty"`
+	// Error is set when the job failed, either outright or because some pages failed to render.
+	// In the latter case the rest of the site was still published.
+	Error     string    `db:"error" json:"error"`
+	StartedAt time.Time `db:"started_at" json:"started_at"`
+	// FinishedAt is nil while the job is running
+	FinishedAt *time.Time `db:"finished_at" json:"finished_at"`
+	DurationMS int64      `db:"-" json:"duration_ms"`
+}
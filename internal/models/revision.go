@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// PostRevision is a snapshot of a post's content taken each time the post is saved
+type PostRevision struct {
+	ID            int64     `db:"id" json:"id"`
+	PostID        int64     `db:"post_id" json:"post_id"`
+	Revision      int       `db:"revision" json:"revision"`
+	Title         string    `db:"title" json:"title"`
+	Slug          string    `db:"slug" json:"slug"`
+	Content       string    `db:"content" json:"content,omitempty"`
+	Tags          string    `db:"tags" json:"tags"`
+	FeaturedImage string    `db:"featured_image" json:"featuredImage"`
+	CreatedAt     time.Time `db:"created_at" json:"created_at"`
+}
+
+// PageRevision is a snapshot of a page's content taken each time the page is saved
+type PageRevision struct {
+	ID        int64     `db:"id" json:"id"`
+	PageID    int64     `db:"page_id" json:"page_id"`
+	Revision  int       `db:"revision" json:"revision"`
+	Title     string    `db:"title" json:"title"`
+	Slug      string    `db:"slug" json:"slug"`
+	Content   string    `db:"content" json:"content,omitempty"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+type User struct {
+	ID           int64     `db:"id" json:"id"`
+	Username     string    `db:"username" json:"username"`
+	PasswordHash string    `db:"password_hash" json:"-"`
+	CreatedAt    time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt    time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// Session is a logged-in browser session. Only a hash of the cookie token is stored.
+type Session struct {
+	ID        int64     `db:"id" json:"id"`
+	TokenHash string    `db:"token_hash" json:"-"`
+	UserID    int64     `db:"user_id" json:"user_id"`
+	CSRFToken string    `db:"csrf_token" json:"-"`
+	ExpiresAt time.Time `db:"expires_at" json:"expires_at"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
@@ -0,0 +1,240 @@
+package publish
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/generator"
+	"github.com/ariefbayu/personal-blog-generator/internal/models"
+	"github.com/ariefbayu/personal-blog-generator/internal/repository"
+)
+
+// ErrBusy is returned when a job is started while a job of the other kind, publish or dry run, is running
+var ErrBusy = errors.New("another publish job is running")
+
+// ErrJobNotFound is returned for jobs that are unknown or no longer kept in memory
+var ErrJobNotFound = errors.New("publish job not found")
+
+// keepJobs is the number of finished jobs whose events are kept in memory
+const keepJobs = 20
+
+// Event is a progress step of a publish job
+type Event struct {
+	ID   int       `json:"id"`
+	Step string    `json:"step"`
+	Time time.Time `json:"time"`
+}
+
+type job struct {
+	info   models.PublishJob
+	events []Event
+	// changed is closed and replaced whenever an event is added. It stays closed once the job finished.
+	changed chan struct{}
+	done    chan struct{}
+}
+
+// Queue runs publish jobs in the background, one at a time, and keeps their progress for event streams.
+// Finished jobs are recorded in the job history.
+type Queue struct {
+	site    *Site
+	jobRepo *repository.PublishJobRepository
+
+	mu      sync.Mutex
+	current *job
+	// finished holds the most recently finished jobs, oldest first
+	finished []*job
+	jobs     map[string]*job
+}
+
+func NewQueue(site *Site, jobRepo *repository.PublishJobRepository) *Queue {
+	return &Queue{site: site, jobRepo: jobRepo, jobs: make(map[string]*job)}
+}
+
+// Site returns the site the queue publishes
+func (q *Queue) Site() *Site {
+	return q.site
+}
+
+// Start starts a publish job in the background. When a job of the same kind is already running,
+// that job is returned instead, so repeated clicks share one build. started reports whether a new job was started.
+func (q *Queue) Start(source string, dryRun bool) (info models.PublishJob, started bool, err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.current != nil {
+		if q.current.info.DryRun == dryRun {
+			return q.current.info, false, nil
+		}
+		return q.current.info, false, ErrBusy
+	}
+
+	j := &job{
+		info: models.PublishJob{
+			ID:        uuid.New().String(),
+			Source:    source,
+			DryRun:    dryRun,
+			Status:    models.PublishJobRunning,
+			StartedAt: time.Now(),
+		},
+		changed: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	q.current = j
+	q.jobs[j.info.ID] = j
+	go q.run(j)
+	return j.info, true, nil
+}
+
+// Run publishes and waits for the result. A job that is already running may have read the content
+// before the caller's change, so Run waits for it to finish and then starts a job of its own.
+func (q *Queue) Run(ctx context.Context, source string, dryRun bool) (models.PublishJob, error) {
+	for {
+		info, started, err := q.Start(source, dryRun)
+		if started {
+			return q.Wait(ctx, info.ID)
+		}
+		if err != nil && !errors.Is(err, ErrBusy) {
+			return info, err
+		}
+		if _, err := q.Wait(ctx, info.ID); ctx.Err() != nil {
+			return info, err
+		}
+	}
+}
+
+// Wait waits for a job to finish. The error of a failed job is returned along with the job.
+func (q *Queue) Wait(ctx context.Context, id string) (models.PublishJob, error) {
+	q.mu.Lock()
+	j, ok := q.jobs[id]
+	q.mu.Unlock()
+	if !ok {
+		return models.PublishJob{}, ErrJobNotFound
+	}
+
+	select {
+	case <-j.done:
+	case <-ctx.Done():
+		return models.PublishJob{}, ctx.Err()
+	}
+
+	q.mu.Lock()
+	info := j.info
+	q.mu.Unlock()
+	if info.Status == models.PublishJobFailed {
+		return info, errors.New(info.Error)
+	}
+	return info, nil
+}
+
+// Events returns the job's events after the event with ID after, the job as it is now,
+// and a channel that is closed when there is more to read. It is closed at once if the job has finished.
+func (q *Queue) Events(id string, after int) ([]Event, models.PublishJob, <-chan struct{}, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	j, ok := q.jobs[id]
+	if !ok {
+		return nil, models.PublishJob{}, nil, ErrJobNotFound
+	}
+	var events []Event
+	if after < len(j.events) {
+		events = append(events, j.events[max(after, 0):]...)
+	}
+	return events, j.info, j.changed, nil
+}
+
+// History returns the running job, if any, followed by the most recent finished jobs
+func (q *Queue) History(limit int) ([]models.PublishJob, error) {
+	// Jobs are recorded under the lock, so holding it keeps a job from being missed as it finishes
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	jobs, err := q.jobRepo.GetPublishJobs(limit)
+	if err != nil {
+		return nil, err
+	}
+	if q.current != nil {
+		running := q.current.info
+		running.DurationMS = time.Since(running.StartedAt).Milliseconds()
+		jobs = append([]models.PublishJob{running}, jobs...)
+	}
+	if jobs == nil {
+		jobs = []models.PublishJob{}
+	}
+	return jobs, nil
+}
+
+func (q *Queue) run(j *job) {
+	if j.info.DryRun {
+		q.emit(j, "Dry run started")
+	} else {
+		q.emit(j, "Publishing started")
+	}
+	result, err := q.publish(j)
+	finished := time.Now()
+
+	q.mu.Lock()
+	j.info.FinishedAt = &finished
+	j.info.DurationMS = finished.Sub(j.info.StartedAt).Milliseconds()
+	if err == nil && len(result.Failed) > 0 {
+		err = fmt.Errorf("%d pages failed to render", len(result.Failed))
+	}
+	if result != nil {
+		j.info.Written = result.Written
+		j.info.Skipped = result.Skipped
+		j.info.Deleted = result.Deleted
+		j.info.DeletedFiles = result.DeletedFiles
+		j.info.FailedPages = result.Failed
+	}
+	if err != nil {
+		j.info.Status = models.PublishJobFailed
+		j.info.Error = err.Error()
+	} else {
+		j.info.Status = models.PublishJobSucceeded
+	}
+	// Record the job before it stops being current, so History always lists it
+	if recordErr := q.jobRepo.CreatePublishJob(&j.info); recordErr != nil {
+		log.Printf("Failed to record publish job %s: %v", j.info.ID, recordErr)
+	}
+	q.current = nil
+	q.finished = append(q.finished, j)
+	if len(q.finished) > keepJobs {
+		delete(q.jobs, q.finished[0].info.ID)
+		q.finished = q.finished[1:]
+	}
+	close(j.changed)
+	close(j.done)
+	q.mu.Unlock()
+
+	if err != nil {
+		log.Printf("Publish job %s failed: %v", j.info.ID, err)
+	}
+}
+
+// publish builds the site for the job. There is no request to recover from a panic, so it fails the job instead.
+func (q *Queue) publish(j *job) (result *generator.BuildResult, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("publish panicked: %v", p)
+		}
+	}()
+	return q.site.Publish(generator.BuildOptions{
+		DryRun:   j.info.DryRun,
+		Progress: func(step string) { q.emit(j, step) },
+	})
+}
+
+// emit adds a progress event to the job and wakes up its event streams
+func (q *Queue) emit(j *job, step string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	j.events = append(j.events, Event{ID: len(j.events) + 1, Step: step, Time: time.Now()})
+	close(j.changed)
+	j.changed = make(chan struct{})
+}
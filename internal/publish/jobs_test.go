@@ -0,0 +1,67 @@
+package publish
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/db"
+	"github.com/ariefbayu/personal-blog-generator/internal/models"
+	"github.com/ariefbayu/personal-blog-generator/internal/repository"
+)
+
+func TestQueueSingleFlight(t *testing.T) {
+	site := newTestSite(t)
+	jobDB, err := db.Connect(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer jobDB.Close()
+	if err := db.Migrate(jobDB); err != nil {
+		t.Fatal(err)
+	}
+	queue := NewQueue(site, repository.NewPublishJobRepository(jobDB))
+	ctx := context.Background()
+
+	// Holding the publish lock keeps the first job running
+	mu.Lock()
+	first, started, err := queue.Start(models.PublishSourceManual, false)
+	if err != nil || !started {
+		mu.Unlock()
+		t.Fatalf("Expected a new job, got %v", err)
+	}
+	second, started, err := queue.Start(models.PublishSourceManual, false)
+	if err != nil || started || second.ID != first.ID {
+		t.Errorf("Expected a second click to join the running job, got %+v, %v", second, err)
+	}
+	if _, _, err := queue.Start(models.PublishSourceManual, true); err != ErrBusy {
+		t.Errorf("Expected ErrBusy for a dry run during a publish, got %v", err)
+	}
+	mu.Unlock()
+
+	job, err := queue.Wait(ctx, first.ID)
+	if err != nil || job.Status != models.PublishJobSucceeded || job.FinishedAt == nil {
+		t.Fatalf("Unexpected finished job %+v: %v", job, err)
+	}
+	events, _, changed, err := queue.Events(first.ID, 0)
+	if err != nil || len(events) < 2 || events[0].Step != "Publishing started" {
+		t.Errorf("Unexpected events %+v: %v", events, err)
+	}
+	select {
+	case <-changed:
+	default:
+		t.Error("The change channel of a finished job should be closed")
+	}
+
+	ran, err := queue.Run(ctx, models.PublishSourceSchedule, false)
+	if err != nil || ran.ID == first.ID || ran.Source != models.PublishSourceSchedule {
+		t.Errorf("Expected Run to start a job of its own, got %+v: %v", ran, err)
+	}
+
+	history, err := queue.History(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 2 || history[0].ID != ran.ID || history[1].ID != first.ID {
+		t.Errorf("Unexpected history: %+v", history)
+	}
+}
@@ -0,0 +1,468 @@
+package publish
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/generator"
+	"github.com/ariefbayu/personal-blog-generator/internal/repository"
+)
+
+// DefaultKeepBuilds is the number of completed builds kept for rollback when PUBLISH_KEEP_BUILDS is not set
+const DefaultKeepBuilds = 3
+
+// stagingPrefix marks build directories that are still being generated
+const stagingPrefix = ".staging-"
+
+// ErrNoPreviousBuild is returned when there is no build to roll back to
+var ErrNoPreviousBuild = errors.New("no previous build to roll back to")
+
+// ErrBuildNotFound is returned when rolling back to a build that does not exist
+var ErrBuildNotFound = errors.New("build not found")
+
+// uploadDirs are output subdirectories written outside of site generation, which every build must carry over
+var uploadDirs = []string{"images"}
+
+// mu serializes publishes and rollbacks, which both move the output symlink
+var mu sync.Mutex
+
+// Site publishes the generated site atomically.
+// The output path is a symlink to the live build inside a sibling "<output>.builds" directory.
+// Each publish generates into a staging directory seeded from the live build and only swaps
+// the symlink once generation has fully succeeded, so the web server never sees a partial site.
+type Site struct {
+	PostRepo      *repository.PostRepository
+	PortfolioRepo *repository.PortfolioRepository
+	PageRepo      *repository.PageRepository
+	SettingsRepo  *repository.SettingsRepository
+	TemplatePath  string
+	OutputPath    string
+	// Keep is the number of completed builds kept for rollback, including the live one
+	Keep int
+}
+
+// Build describes a completed build directory
+type Build struct {
+	ID      string    `json:"id"`
+	Current bool      `json:"current"`
+	BuiltAt time.Time `json:"built_at"`
+}
+
+// NewSite creates a publisher for the given template and output paths, keeping KeepBuilds() builds
+func NewSite(postRepo *repository.PostRepository, portfolioRepo *repository.PortfolioRepository, pageRepo *repository.PageRepository, settingsRepo *repository.SettingsRepository, templatePath, outputPath string) *Site {
+	return &Site{
+		PostRepo:      postRepo,
+		PortfolioRepo: portfolioRepo,
+		PageRepo:      pageRepo,
+		SettingsRepo:  settingsRepo,
+		TemplatePath:  templatePath,
+		OutputPath:    outputPath,
+		Keep:          KeepBuilds(),
+	}
+}
+
+// KeepBuilds returns the number of builds to keep from PUBLISH_KEEP_BUILDS, falling back to DefaultKeepBuilds
+func KeepBuilds() int {
+	if n, err := strconv.Atoi(os.Getenv("PUBLISH_KEEP_BUILDS")); err == nil && n > 0 {
+		return n
+	}
+	return DefaultKeepBuilds
+}
+
+// buildsPath returns the directory holding the completed builds
+func (s *Site) buildsPath() string {
+	return filepath.Clean(s.OutputPath) + ".builds"
+}
+
+// Publish generates the site into a staging directory and swaps it live.
+// A dry run generates nothing and reports what publishing would change in the live build.
+func (s *Site) Publish(opts generator.BuildOptions) (*generator.BuildResult, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if opts.DryRun {
+		return s.generate(s.OutputPath, opts)
+	}
+
+	report := func(step string) {
+		if opts.Progress != nil {
+			opts.Progress(step)
+		}
+	}
+
+	if err := s.adoptOutputDir(); err != nil {
+		return nil, err
+	}
+
+	buildsPath := s.buildsPath()
+	if err := os.MkdirAll(buildsPath, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create builds directory: %w", err)
+	}
+
+	id := newBuildID(buildsPath)
+	stagingPath := filepath.Join(buildsPath, stagingPrefix+id)
+	livePath, err := s.livePath()
+	if err != nil {
+		return nil, err
+	}
+
+	// Start from the live build so unchanged files are skipped and uploads are carried over
+	if livePath != "" {
+		report("Preparing the staging build")
+		if err := linkTree(livePath, stagingPath); err != nil {
+			os.RemoveAll(stagingPath)
+			return nil, fmt.Errorf("failed to seed staging directory: %w", err)
+		}
+	}
+
+	result, err := s.generate(stagingPath, opts)
+	if err != nil {
+		os.RemoveAll(stagingPath)
+		return nil, err
+	}
+
+	// Pick up files uploaded while the build was running
+	if livePath != "" {
+		report("Copying uploads")
+		if err := syncUploads(livePath, stagingPath); err != nil {
+			os.RemoveAll(stagingPath)
+			return nil, fmt.Errorf("failed to copy uploads: %w", err)
+		}
+	}
+
+	report("Making the new build live")
+	buildPath := filepath.Join(buildsPath, id)
+	if err := os.Rename(stagingPath, buildPath); err != nil {
+		os.RemoveAll(stagingPath)
+		return nil, fmt.Errorf("failed to finalize build: %w", err)
+	}
+	if err := s.swap(id); err != nil {
+		return nil, err
+	}
+
+	if err := s.prune(); err != nil {
+		// Old builds only cost disk space, so the publish still counts as successful
+		log.Printf("Failed to prune old builds: %v", err)
+	}
+
+	return result, nil
+}
+
+// Rollback makes an earlier build live again. An empty id selects the build published before the live one.
+func (s *Site) Rollback(id string) (*Build, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	builds, err := s.listBuilds()
+	if err != nil {
+		return nil, err
+	}
+
+	current := -1
+	for i, build := range builds {
+		if build.Current {
+			current = i
+		}
+	}
+
+	target := -1
+	if id == "" {
+		// Builds are ordered oldest first
+		if current > 0 {
+			target = current - 1
+		} else if current == -1 && len(builds) > 0 {
+			target = len(builds) - 1
+		}
+		if target == -1 {
+			return nil, ErrNoPreviousBuild
+		}
+	} else {
+		for i, build := range builds {
+			if build.ID == id {
+				target = i
+			}
+		}
+		if target == -1 {
+			return nil, ErrBuildNotFound
+		}
+	}
+
+	// Uploads made since the target build was live must survive the rollback
+	targetPath := filepath.Join(s.buildsPath(), builds[target].ID)
+	if livePath, err := s.livePath(); err == nil && livePath != "" {
+		if err := syncUploads(livePath, targetPath); err != nil {
+			return nil, fmt.Errorf("failed to copy uploads: %w", err)
+		}
+	}
+
+	if err := s.swap(builds[target].ID); err != nil {
+		return nil, err
+	}
+
+	build := builds[target]
+	build.Current = true
+	return &build, nil
+}
+
+// Builds lists the completed builds, oldest first
+func (s *Site) Builds() ([]Build, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	return s.listBuilds()
+}
+
+// generate runs the site generator into the given directory
+func (s *Site) generate(outputPath string, opts generator.BuildOptions) (*generator.BuildResult, error) {
+	return generator.GenerateStaticSiteWithOptions(s.PostRepo, s.PortfolioRepo, s.PageRepo, s.SettingsRepo, s.TemplatePath, outputPath, opts)
+}
+
+// adoptOutputDir converts an output directory created before atomic publishing into the first build
+func (s *Site) adoptOutputDir() error {
+	info, err := os.Lstat(s.OutputPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to inspect output path: %w", err)
+	}
+	if info.Mode()&os.ModeSymlink != 0 {
+		return nil
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("output path %s is not a directory", s.OutputPath)
+	}
+
+	buildsPath := s.buildsPath()
+	if err := os.MkdirAll(buildsPath, 0755); err != nil {
+		return fmt.Errorf("failed to create builds directory: %w", err)
+	}
+
+	id := newBuildID(buildsPath)
+	log.Printf("Moving existing output directory into %s", filepath.Join(buildsPath, id))
+	if err := os.Rename(s.OutputPath, filepath.Join(buildsPath, id)); err != nil {
+		return fmt.Errorf("failed to move output directory into builds: %w", err)
+	}
+	return s.swap(id)
+}
+
+// livePath returns the directory the output symlink points to, or an empty string before the first publish
+func (s *Site) livePath() (string, error) {
+	target, err := os.Readlink(s.OutputPath)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read output symlink: %w", err)
+	}
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(filepath.Dir(s.OutputPath), target)
+	}
+	return target, nil
+}
+
+// swap atomically points the output symlink at a build by renaming a new symlink over it
+func (s *Site) swap(id string) error {
+	// A relative target keeps the link valid if the parent directory is moved or mounted elsewhere
+	target := filepath.Join(filepath.Base(s.buildsPath()), id)
+	tmpLink := fmt.Sprintf("%s.tmp-%d", filepath.Clean(s.OutputPath), time.Now().UnixNano())
+
+	if err := os.Symlink(target, tmpLink); err != nil {
+		return fmt.Errorf("failed to create output symlink: %w", err)
+	}
+	if err := os.Rename(tmpLink, s.OutputPath); err != nil {
+		os.Remove(tmpLink)
+		return fmt.Errorf("failed to swap output symlink: %w", err)
+	}
+	return nil
+}
+
+// listBuilds returns the completed builds, oldest first, marking the live one
+func (s *Site) listBuilds() ([]Build, error) {
+	entries, err := os.ReadDir(s.buildsPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list builds: %w", err)
+	}
+
+	livePath, err := s.livePath()
+	if err != nil {
+		return nil, err
+	}
+
+	var builds []Build
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		builds = append(builds, Build{
+			ID:      entry.Name(),
+			Current: livePath != "" && filepath.Base(livePath) == entry.Name(),
+			BuiltAt: info.ModTime(),
+		})
+	}
+
+	// Build IDs are timestamps, so name order is build order
+	sort.Slice(builds, func(i, j int) bool {
+		return builds[i].ID < builds[j].ID
+	})
+	return builds, nil
+}
+
+// prune removes the oldest builds beyond the keep limit and any staging directories left by failed runs.
+// The live build is never removed.
+func (s *Site) prune() error {
+	keep := s.Keep
+	if keep <= 0 {
+		keep = DefaultKeepBuilds
+	}
+
+	builds, err := s.listBuilds()
+	if err != nil {
+		return err
+	}
+
+	for i, build := range builds {
+		if i >= len(builds)-keep || build.Current {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(s.buildsPath(), build.ID)); err != nil {
+			return err
+		}
+	}
+
+	entries, err := os.ReadDir(s.buildsPath())
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), stagingPrefix) {
+			if err := os.RemoveAll(filepath.Join(s.buildsPath(), entry.Name())); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// buildIDFormat gives fixed-width IDs, so name order is build order
+const buildIDFormat = "20060102-150405.000000"
+
+// newBuildID returns a build ID that sorts after every existing build, even ones pruned in the same instant
+func newBuildID(buildsPath string) string {
+	latest := ""
+	if entries, err := os.ReadDir(buildsPath); err == nil {
+		for _, entry := range entries {
+			name := strings.TrimPrefix(entry.Name(), stagingPrefix)
+			if name > latest {
+				latest = name
+			}
+		}
+	}
+
+	t := time.Now().UTC()
+	for {
+		id := t.Format(buildIDFormat)
+		if id > latest {
+			return id
+		}
+		t = t.Add(time.Microsecond)
+	}
+}
+
+// linkTree recreates the directory tree at src under dst, hard-linking files.
+// The generator replaces files by renaming new ones into place, so linked files are never modified in place.
+func linkTree(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		return linkOrCopy(path, target)
+	})
+}
+
+// syncUploads links upload files present in src but missing from dst
+func syncUploads(src, dst string) error {
+	for _, dir := range uploadDirs {
+		srcDir := filepath.Join(src, dir)
+		if _, err := os.Stat(srcDir); os.IsNotExist(err) {
+			continue
+		}
+
+		err := filepath.WalkDir(srcDir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(src, path)
+			if err != nil {
+				return err
+			}
+			target := filepath.Join(dst, rel)
+
+			if d.IsDir() {
+				return os.MkdirAll(target, 0755)
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+			if _, err := os.Lstat(target); err == nil {
+				return nil
+			}
+			return linkOrCopy(path, target)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// linkOrCopy hard-links src to dst, copying the file when linking is not supported
+func linkOrCopy(src, dst string) error {
+	if err := os.Link(src, dst); err == nil {
+		return nil
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
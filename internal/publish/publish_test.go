@@ -0,0 +1,208 @@
+package publish
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/db"
+	"github.com/ariefbayu/personal-blog-generator/internal/generator"
+	"github.com/ariefbayu/personal-blog-generator/internal/models"
+	"github.com/ariefbayu/personal-blog-generator/internal/repository"
+)
+
+func newTestSite(t *testing.T) *Site {
+	testDB, err := db.Connect(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to connect to test DB: %v", err)
+	}
+	t.Cleanup(func() { testDB.Close() })
+	if err := db.Migrate(testDB); err != nil {
+		t.Fatalf("Failed to migrate test DB: %v", err)
+	}
+
+	templatePath, err := filepath.Abs("../../templates")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &Site{
+		PostRepo:      repository.NewPostRepository(testDB),
+		PortfolioRepo: repository.NewPortfolioRepository(testDB),
+		PageRepo:      repository.NewPageRepository(testDB),
+		SettingsRepo:  repository.NewSettingsRepository(testDB),
+		TemplatePath:  templatePath,
+		OutputPath:    filepath.Join(t.TempDir(), "html-outputs"),
+		Keep:          2,
+	}
+}
+
+func TestPublishSwapsAndPrunes(t *testing.T) {
+	site := newTestSite(t)
+
+	// An output directory from before atomic publishing, holding an uploaded image
+	imagePath := filepath.Join(site.OutputPath, "images", "photo.jpg")
+	if err := os.MkdirAll(filepath.Dir(imagePath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(imagePath, []byte("jpeg"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := site.Publish(generator.BuildOptions{}); err != nil {
+			t.Fatalf("Publish failed: %v", err)
+		}
+	}
+
+	info, err := os.Lstat(site.OutputPath)
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("Expected output path to be a symlink, got %v, %v", info, err)
+	}
+	if _, err := os.Stat(filepath.Join(site.OutputPath, "index.html")); err != nil {
+		t.Error("Expected index.html in the live build")
+	}
+	if data, err := os.ReadFile(imagePath); err != nil || string(data) != "jpeg" {
+		t.Error("Expected the uploaded image to be carried into the live build")
+	}
+
+	builds, err := site.Builds()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(builds) != 2 || !builds[1].Current {
+		t.Errorf("Expected the two newest builds with the last one live, got %+v", builds)
+	}
+}
+
+func TestPublishFailureKeepsLiveBuild(t *testing.T) {
+	site := newTestSite(t)
+	if _, err := site.Publish(generator.BuildOptions{}); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	live, err := os.Readlink(site.OutputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	site.TemplatePath = filepath.Join(t.TempDir(), "missing")
+	if _, err := site.Publish(generator.BuildOptions{}); err == nil {
+		t.Fatal("Expected publish with missing templates to fail")
+	}
+
+	if after, _ := os.Readlink(site.OutputPath); after != live {
+		t.Errorf("Expected output to still point at %s, got %s", live, after)
+	}
+	if _, err := os.Stat(filepath.Join(site.OutputPath, "index.html")); err != nil {
+		t.Error("Expected the live build to be intact")
+	}
+	entries, err := os.ReadDir(site.buildsPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("Expected the failed staging directory to be removed, got %d entries", len(entries))
+	}
+}
+
+func TestPublishWithFailedPages(t *testing.T) {
+	site := newTestSite(t)
+	for _, post := range []models.Post{
+		{Title: "Good", Slug: "good", Content: "Fine", Published: true},
+		{Title: "Broken", Slug: "broken", Content: "Fails to render", Published: true},
+	} {
+		if err := site.PostRepo.CreatePost(&post); err != nil {
+			t.Fatal(err)
+		}
+	}
+	settings, err := site.SettingsRepo.GetSettings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	settings.BaseURL = "https://example.com"
+	if err := site.SettingsRepo.UpdateSettings(settings); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := site.Publish(generator.BuildOptions{}); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	before, err := os.ReadFile(filepath.Join(site.OutputPath, "broken.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Templates where the broken post fails to render
+	templatePath := t.TempDir()
+	if err := os.CopyFS(templatePath, os.DirFS(site.TemplatePath)); err != nil {
+		t.Fatal(err)
+	}
+	post := `<h1>{{.Title}}</h1>{{if eq .Slug "broken"}}{{index .Tags 9}}{{end}}`
+	if err := os.WriteFile(filepath.Join(templatePath, "post.html"), []byte(post), 0644); err != nil {
+		t.Fatal(err)
+	}
+	site.TemplatePath = templatePath
+
+	result, err := site.Publish(generator.BuildOptions{})
+	if err != nil {
+		t.Fatalf("Expected the rest of the site to be published, got %v", err)
+	}
+	if len(result.Failed) != 1 || !strings.Contains(result.Failed[0], "post broken") {
+		t.Errorf("Expected the broken post to be reported, got %v", result.Failed)
+	}
+	if data, err := os.ReadFile(filepath.Join(site.OutputPath, "good.html")); err != nil || !strings.Contains(string(data), "<h1>Good</h1>") {
+		t.Errorf("Expected the good post from the new templates, got %q (%v)", data, err)
+	}
+	if after, err := os.ReadFile(filepath.Join(site.OutputPath, "broken.html")); err != nil || string(after) != string(before) {
+		t.Errorf("Expected the broken post to keep its previous version, got %v", err)
+	}
+	for _, file := range []string{"search-index.json", "feed.xml", "sitemap.xml", generator.ManifestFile} {
+		if _, err := os.Stat(filepath.Join(site.OutputPath, file)); err != nil {
+			t.Errorf("Expected %s to be written: %v", file, err)
+		}
+	}
+	if sitemap, _ := os.ReadFile(filepath.Join(site.OutputPath, "sitemap.xml")); !strings.Contains(string(sitemap), "https://example.com/broken.html") {
+		t.Error("Expected the kept page to stay in the sitemap")
+	}
+}
+
+func TestRollback(t *testing.T) {
+	site := newTestSite(t)
+	if _, err := site.Rollback(""); err != ErrNoPreviousBuild {
+		t.Fatalf("Expected ErrNoPreviousBuild, got %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := site.Publish(generator.BuildOptions{}); err != nil {
+			t.Fatalf("Publish failed: %v", err)
+		}
+	}
+	builds, err := site.Builds()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// An image uploaded after the build being rolled back to
+	imagePath := filepath.Join(site.OutputPath, "images", "late.png")
+	if err := os.MkdirAll(filepath.Dir(imagePath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(imagePath, []byte("png"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	build, err := site.Rollback("")
+	if err != nil {
+		t.Fatalf("Rollback failed: %v", err)
+	}
+	if build.ID != builds[0].ID {
+		t.Errorf("Expected rollback to %s, got %s", builds[0].ID, build.ID)
+	}
+	if _, err := os.Stat(imagePath); err != nil {
+		t.Error("Expected uploads to survive the rollback")
+	}
+
+	if _, err := site.Rollback("missing"); err != ErrBuildNotFound {
+		t.Errorf("Expected ErrBuildNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/models"
+)
+
+type APITokenRepository struct {
+	db *sql.DB
+}
+
+func NewAPITokenRepository(db *sql.DB) *APITokenRepository {
+	return &APITokenRepository{db: db}
+}
+
+const apiTokenColumns = "id, user_id, name, token_hash, token_prefix, scopes, expires_at, last_used_at, revoked_at, created_at"
+
+// GetAPITokens returns the user's tokens, newest first
+func (r *APITokenRepository) GetAPITokens(userID int64) ([]models.APIToken, error) {
+	rows, err := r.db.Query("SELECT "+apiTokenColumns+" FROM api_tokens WHERE user_id = ? ORDER BY created_at DESC, id DESC", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tokens []models.APIToken
+	for rows.Next() {
+		token, err := scanAPIToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, *token)
+	}
+	return tokens, rows.Err()
+}
+
+func (r *APITokenRepository) GetAPITokenByHash(tokenHash string) (*models.APIToken, error) {
+	return scanAPIToken(r.db.QueryRow("SELECT "+apiTokenColumns+" FROM api_tokens WHERE token_hash = ?", tokenHash))
+}
+
+func (r *APITokenRepository) CreateAPIToken(token *models.APIToken) error {
+	return r.db.QueryRow(
+		"INSERT INTO api_tokens (user_id, name, token_hash, token_prefix, scopes, expires_at) VALUES (?, ?, ?, ?, ?, ?) RETURNING id, created_at",
+		token.UserID, token.Name, token.TokenHash, token.Prefix, strings.Join(token.Scopes, ","), nullableTime(token.ExpiresAt),
+	).Scan(&token.ID, &token.CreatedAt)
+}
+
+// RevokeAPIToken marks one of the user's tokens as revoked. It returns sql.ErrNoRows if the user has no such active token.
+func (r *APITokenRepository) RevokeAPIToken(id, userID int64) error {
+	result, err := r.db.Exec("UPDATE api_tokens SET revoked_at = ? WHERE id = ? AND user_id = ? AND revoked_at IS NULL", time.Now().UTC(), id, userID)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// TouchAPIToken records when a token was last used
+func (r *APITokenRepository) TouchAPIToken(id int64, now time.Time) error {
+	_, err := r.db.Exec("UPDATE api_tokens SET last_used_at = ? WHERE id = ?", now.UTC(), id)
+	return err
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanAPIToken(row rowScanner) (*models.APIToken, error) {
+	var token models.APIToken
+	var scopes string
+	var expiresAt, lastUsedAt, revokedAt sql.NullTime
+	err := row.Scan(&token.ID, &token.UserID, &token.Name, &token.TokenHash, &token.Prefix, &scopes, &expiresAt, &lastUsedAt, &revokedAt, &token.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	token.Scopes = []string{}
+	if scopes != "" {
+		token.Scopes = strings.Split(scopes, ",")
+	}
+	token.ExpiresAt = timePtr(expiresAt)
+	token.LastUsedAt = timePtr(lastUsedAt)
+	token.RevokedAt = timePtr(revokedAt)
+	return &token, nil
+}
+
+func nullableTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return t.UTC()
+}
+
+func timePtr(nt sql.NullTime) *time.Time {
+	if !nt.Valid {
+		return nil
+	}
+	t := nt.Time
+	return &t
+}
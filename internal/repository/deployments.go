@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/models"
+)
+
+type DeploymentRepository struct {
+	db *sql.DB
+}
+
+func NewDeploymentRepository(db *sql.DB) *DeploymentRepository {
+	return &DeploymentRepository{db: db}
+}
+
+func (r *DeploymentRepository) CreateDeployment(d *models.Deployment) error {
+	return r.db.QueryRow(
+		"INSERT INTO deployments (target, status, uploaded, deleted, unchanged, bytes, error, started_at, finished_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING id",
+		d.Target, d.Status, d.Uploaded, d.Deleted, d.Unchanged, d.Bytes, d.Error, d.StartedAt.UTC(), d.FinishedAt.UTC(),
+	).Scan(&d.ID)
+}
+
+// GetDeployments returns the most recent deployments, newest first
+func (r *DeploymentRepository) GetDeployments(limit int) ([]models.Deployment, error) {
+	rows, err := r.db.Query("SELECT id, target, status, uploaded, deleted, unchanged, bytes, error, started_at, finished_at FROM deployments ORDER BY id DESC LIMIT ?", limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deployments []models.Deployment
+	for rows.Next() {
+		var d models.Deployment
+		if err := rows.Scan(&d.ID, &d.Target, &d.Status, &d.Uploaded, &d.Deleted, &d.Unchanged, &d.Bytes, &d.Error, &d.StartedAt, &d.FinishedAt); err != nil {
+			return nil, err
+		}
+		deployments = append(deployments, d)
+	}
+	return deployments, rows.Err()
+}
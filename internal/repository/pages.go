@@ -98,17 +98,60 @@ func (r *PageRepository) GetPagesForNavigation() ([]models.Page, error) {
 }
 
 func (r *PageRepository) CreatePage(page *models.Page) error {
-	err := r.db.QueryRow("INSERT INTO pages (title, slug, content, show_in_nav, sort_order) VALUES (?, ?, ?, ?, ?) RETURNING id", page.Title, page.Slug, page.Content, page.ShowInNav, page.SortOrder).Scan(&page.ID)
-	return err
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// A zero CreatedAt means now, imports set it to keep the original date
+	var createdAt interface{}
+	if !page.CreatedAt.IsZero() {
+		createdAt = page.CreatedAt.UTC()
+	}
+
+	err = tx.QueryRow("INSERT INTO pages (title, slug, content, show_in_nav, sort_order, created_at) VALUES (?, ?, ?, ?, ?, COALESCE(?, CURRENT_TIMESTAMP)) RETURNING id", page.Title, page.Slug, page.Content, page.ShowInNav, page.SortOrder, createdAt).Scan(&page.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := insertPageRevision(tx, page); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 func (r *PageRepository) UpdatePage(page *models.Page) error {
-	_, err := r.db.Exec("UPDATE pages SET title = ?, slug = ?, content = ?, show_in_nav = ?, sort_order = ? WHERE id = ?", page.Title, page.Slug, page.Content, page.ShowInNav, page.SortOrder, page.ID)
-	return err
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("UPDATE pages SET title = ?, slug = ?, content = ?, show_in_nav = ?, sort_order = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?", page.Title, page.Slug, page.Content, page.ShowInNav, page.SortOrder, page.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := insertPageRevision(tx, page); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 func (r *PageRepository) DeletePage(id int64) error {
-	result, err := r.db.Exec("DELETE FROM pages WHERE id = ?", id)
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM page_revisions WHERE page_id = ?", id); err != nil {
+		return err
+	}
+	result, err := tx.Exec("DELETE FROM pages WHERE id = ?", id)
 	if err != nil {
 		return err
 	}
@@ -119,5 +162,5 @@ func (r *PageRepository) DeletePage(id int64) error {
 	if rowsAffected == 0 {
 		return sql.ErrNoRows
 	}
-	return nil
+	return tx.Commit()
 }
\ No newline at end of file
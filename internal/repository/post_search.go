@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/models"
+	"github.com/ariefbayu/personal-blog-generator/internal/utils"
+)
+
+// Sort orders accepted by PostFilter.Sort
+const (
+	PostSortNewest    = "newest"
+	PostSortOldest    = "oldest"
+	PostSortUpdated   = "updated"
+	PostSortTitle     = "title"
+	PostSortRelevance = "relevance"
+)
+
+// postSortClauses maps each sort order to its ORDER BY clause.
+// Relevance weights title matches above tag matches above content matches.
+var postSortClauses = map[string]string{
+	PostSortNewest:    "p.created_at DESC, p.id DESC",
+	PostSortOldest:    "p.created_at ASC, p.id ASC",
+	PostSortUpdated:   "COALESCE(p.updated_at, p.created_at) DESC, p.id DESC",
+	PostSortTitle:     "p.title COLLATE NOCASE ASC, p.id ASC",
+	PostSortRelevance: "bm25(posts_fts, 10.0, 1.0, 5.0), p.created_at DESC",
+}
+
+// filterDateLayout is the layout of PostFilter.From and PostFilter.To
+const filterDateLayout = "2006-01-02"
+
+// Snippet match markers, replaced with <mark> tags once the snippet is escaped
+const (
+	snippetOpen  = "\x02"
+	snippetClose = "\x03"
+)
+
+// PostFilter narrows and orders the posts listed by GetPostsPaginated.
+// The zero value lists every post, newest first.
+type PostFilter struct {
+	// Query is a full-text search over title, content and tags. Every word must match, as a prefix.
+	Query string
+	// Tag is a tag name or slug
+	Tag string
+	// Published limits the list to published (true) or draft (false) posts when set
+	Published *bool
+	// From and To limit the creation date, inclusive, as YYYY-MM-DD
+	From string
+	To   string
+	// Sort is one of the PostSort orders. It defaults to relevance when searching and newest otherwise.
+	Sort string
+}
+
+// Validate checks the sort order and date range
+func (f PostFilter) Validate() error {
+	if f.Sort != "" {
+		if _, ok := postSortClauses[f.Sort]; !ok {
+			return fmt.Errorf("unknown sort %q", f.Sort)
+		}
+		if f.Sort == PostSortRelevance && ftsQuery(f.Query) == "" {
+			return errors.New("sorting by relevance needs a search query")
+		}
+	}
+
+	var from, to time.Time
+	var err error
+	if f.From != "" {
+		if from, err = time.Parse(filterDateLayout, f.From); err != nil {
+			return fmt.Errorf("invalid from date %q, expected YYYY-MM-DD", f.From)
+		}
+	}
+	if f.To != "" {
+		if to, err = time.Parse(filterDateLayout, f.To); err != nil {
+			return fmt.Errorf("invalid to date %q, expected YYYY-MM-DD", f.To)
+		}
+	}
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		return errors.New("the to date is before the from date")
+	}
+	return nil
+}
+
+// GetPostsPaginated lists one page of the posts matching the filter, with the total number of matches.
+// When searching, each post carries an HTML snippet of the matched text with matches wrapped in <mark>.
+func (r *PostRepository) GetPostsPaginated(limit, offset int, filter PostFilter) ([]models.Post, int, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, 0, err
+	}
+
+	from := " FROM posts p"
+	var where []string
+	var args []interface{}
+
+	query := ftsQuery(filter.Query)
+	if query != "" {
+		from += " JOIN posts_fts ON posts_fts.rowid = p.id"
+		where = append(where, "posts_fts MATCH ?")
+		args = append(args, query)
+	}
+	if filter.Tag != "" {
+		where = append(where, "EXISTS (SELECT 1 FROM post_tags pt JOIN tags t ON t.id = pt.tag_id WHERE pt.post_id = p.id AND t.slug = ?)")
+		args = append(args, utils.TagSlug(filter.Tag))
+	}
+	if filter.Published != nil {
+		where = append(where, "p.published = ?")
+		args = append(args, *filter.Published)
+	}
+	// created_at is stored as text starting with the date, so comparing the first ten
+	// characters filters by the day the post was written in its own time zone
+	if filter.From != "" {
+		where = append(where, "substr(p.created_at, 1, 10) >= ?")
+		args = append(args, filter.From)
+	}
+	if filter.To != "" {
+		where = append(where, "substr(p.created_at, 1, 10) <= ?")
+		args = append(args, filter.To)
+	}
+	if len(where) > 0 {
+		from += " WHERE " + strings.Join(where, " AND ")
+	}
+
+	// Get total count
+	var total int
+	err := r.db.QueryRow("SELECT COUNT(*)"+from, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	sort := filter.Sort
+	if sort == "" {
+		sort = PostSortNewest
+		if query != "" {
+			sort = PostSortRelevance
+		}
+	}
+
+	columns := "SELECT p.id, p.title, p.slug, p.featured_image, p.published, p.publish_at, p.created_at"
+	if query != "" {
+		columns += ", snippet(posts_fts, -1, char(2), char(3), '…', 24)"
+	}
+
+	// Get paginated posts
+	rows, err := r.db.Query(columns+from+" ORDER BY "+postSortClauses[sort]+" LIMIT ? OFFSET ?", append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	now := time.Now()
+	var posts []models.Post
+	for rows.Next() {
+		var post models.Post
+		var publishAt sql.NullTime
+		dest := []interface{}{&post.ID, &post.Title, &post.Slug, &post.FeaturedImage, &post.Published, &publishAt, &post.CreatedAt}
+		var snippet string
+		if query != "" {
+			dest = append(dest, &snippet)
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, 0, err
+		}
+		setPublishAt(&post, publishAt, now)
+		post.Snippet = highlightSnippet(snippet)
+		posts = append(posts, post)
+	}
+	return posts, total, rows.Err()
+}
+
+// ftsQuery turns free text into an FTS5 query matching every word as a prefix.
+// Words are quoted so that FTS5 operators and punctuation in the search are taken literally.
+func ftsQuery(text string) string {
+	var terms []string
+	for _, word := range strings.Fields(text) {
+		if strings.IndexFunc(word, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) < 0 {
+			continue
+		}
+		terms = append(terms, `"`+strings.ReplaceAll(word, `"`, `""`)+`"*`)
+	}
+	return strings.Join(terms, " ")
+}
+
+// highlightSnippet escapes a snippet returned by FTS5 and turns its match markers into <mark> tags
+func highlightSnippet(snippet string) string {
+	escaped := html.EscapeString(snippet)
+	escaped = strings.ReplaceAll(escaped, snippetOpen, "<mark>")
+	return strings.ReplaceAll(escaped, snippetClose, "</mark>")
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"database/sql"
+	"time"
 
 	"github.com/ariefbayu/personal-blog-generator/internal/models"
 )
@@ -15,45 +16,111 @@ func NewPostRepository(db *sql.DB) *PostRepository {
 }
 
 func (r *PostRepository) GetAllPosts() ([]models.Post, error) {
-	rows, err := r.db.Query("SELECT id, title, slug, published, created_at FROM posts ORDER BY created_at DESC")
+	rows, err := r.db.Query("SELECT id, title, slug, published, publish_at, created_at FROM posts ORDER BY created_at DESC")
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
+	now := time.Now()
 	var posts []models.Post
 	for rows.Next() {
 		var post models.Post
-		err := rows.Scan(&post.ID, &post.Title, &post.Slug, &post.Published, &post.CreatedAt)
+		var publishAt sql.NullTime
+		err := rows.Scan(&post.ID, &post.Title, &post.Slug, &post.Published, &publishAt, &post.CreatedAt)
 		if err != nil {
 			return nil, err
 		}
+		setPublishAt(&post, publishAt, now)
 		posts = append(posts, post)
 	}
 	return posts, nil
 }
 
 func (r *PostRepository) CreatePost(post *models.Post) error {
-	err := r.db.QueryRow("INSERT INTO posts (title, slug, content, tags, featured_image, published, created_at) VALUES (?, ?, ?, ?, ?, ?, ?) RETURNING id", post.Title, post.Slug, post.Content, post.Tags, post.FeaturedImage, post.Published, post.CreatedAt).Scan(&post.ID)
-	return err
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow("INSERT INTO posts (title, slug, content, tags, featured_image, published, publish_at, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?) RETURNING id", post.Title, post.Slug, post.Content, post.Tags, post.FeaturedImage, post.Published, publishAtValue(post.PublishAt), post.CreatedAt).Scan(&post.ID)
+	if err != nil {
+		return err
+	}
+
+	post.Tags, err = SyncPostTags(tx, post.ID, post.Tags)
+	if err != nil {
+		return err
+	}
+
+	if err := insertPostRevision(tx, post); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 func (r *PostRepository) GetPostByID(id int64) (*models.Post, error) {
 	var post models.Post
-	err := r.db.QueryRow("SELECT id, title, slug, content, tags, featured_image, published, created_at, updated_at FROM posts WHERE id = ?", id).Scan(&post.ID, &post.Title, &post.Slug, &post.Content, &post.Tags, &post.FeaturedImage, &post.Published, &post.CreatedAt, &post.UpdatedAt)
+	var publishAt sql.NullTime
+	err := r.db.QueryRow("SELECT id, title, slug, content, tags, featured_image, published, publish_at, created_at, updated_at FROM posts WHERE id = ?", id).Scan(&post.ID, &post.Title, &post.Slug, &post.Content, &post.Tags, &post.FeaturedImage, &post.Published, &publishAt, &post.CreatedAt, &post.UpdatedAt)
 	if err != nil {
 		return nil, err
 	}
+	setPublishAt(&post, publishAt, time.Now())
+	return &post, nil
+}
+
+func (r *PostRepository) GetPostBySlug(slug string) (*models.Post, error) {
+	var post models.Post
+	var publishAt sql.NullTime
+	err := r.db.QueryRow("SELECT id, title, slug, content, tags, featured_image, published, publish_at, created_at, updated_at FROM posts WHERE slug = ?", slug).Scan(&post.ID, &post.Title, &post.Slug, &post.Content, &post.Tags, &post.FeaturedImage, &post.Published, &publishAt, &post.CreatedAt, &post.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	setPublishAt(&post, publishAt, time.Now())
 	return &post, nil
 }
 
 func (r *PostRepository) UpdatePost(post *models.Post) error {
-	_, err := r.db.Exec("UPDATE posts SET title = ?, slug = ?, content = ?, tags = ?, featured_image = ?, published = ?, updated_at = ? WHERE id = ?", post.Title, post.Slug, post.Content, post.Tags, post.FeaturedImage, post.Published, post.UpdatedAt, post.ID)
-	return err
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("UPDATE posts SET title = ?, slug = ?, content = ?, tags = ?, featured_image = ?, published = ?, publish_at = ?, updated_at = ? WHERE id = ?", post.Title, post.Slug, post.Content, post.Tags, post.FeaturedImage, post.Published, publishAtValue(post.PublishAt), post.UpdatedAt, post.ID)
+	if err != nil {
+		return err
+	}
+
+	post.Tags, err = SyncPostTags(tx, post.ID, post.Tags)
+	if err != nil {
+		return err
+	}
+
+	if err := insertPostRevision(tx, post); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 func (r *PostRepository) DeletePost(id int64) error {
-	result, err := r.db.Exec("DELETE FROM posts WHERE id = ?", id)
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM post_tags WHERE post_id = ?", id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM post_revisions WHERE post_id = ?", id); err != nil {
+		return err
+	}
+	result, err := tx.Exec("DELETE FROM posts WHERE id = ?", id)
 	if err != nil {
 		return err
 	}
@@ -64,52 +131,59 @@ func (r *PostRepository) DeletePost(id int64) error {
 	if rowsAffected == 0 {
 		return sql.ErrNoRows
 	}
-	return nil
+	return tx.Commit()
 }
 
+// GetPublishedPosts returns published posts whose publish_at time, if any, has passed
 func (r *PostRepository) GetPublishedPosts() ([]models.Post, error) {
-	rows, err := r.db.Query("SELECT id, title, slug, content, tags, featured_image, published, created_at, updated_at FROM posts WHERE published = true ORDER BY created_at DESC")
+	// publish_at is stored in UTC, so it compares correctly against a UTC time
+	return r.queryFullPosts("SELECT id, title, slug, content, tags, featured_image, published, publish_at, created_at, updated_at FROM posts WHERE published = true AND (publish_at IS NULL OR publish_at <= ?) ORDER BY created_at DESC", time.Now().UTC())
+}
+
+// GetPostsDueBetween returns published posts whose publish_at time falls after since and at or before until.
+// It is used by the publish scheduler to detect posts that became visible since its last check.
+func (r *PostRepository) GetPostsDueBetween(since, until time.Time) ([]models.Post, error) {
+	return r.queryFullPosts("SELECT id, title, slug, content, tags, featured_image, published, publish_at, created_at, updated_at FROM posts WHERE published = true AND publish_at IS NOT NULL AND publish_at > ? AND publish_at <= ? ORDER BY publish_at", since.UTC(), until.UTC())
+}
+
+// queryFullPosts runs a query selecting every post column and scans the results
+func (r *PostRepository) queryFullPosts(query string, args ...interface{}) ([]models.Post, error) {
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
+	now := time.Now()
 	var posts []models.Post
 	for rows.Next() {
 		var post models.Post
-		err := rows.Scan(&post.ID, &post.Title, &post.Slug, &post.Content, &post.Tags, &post.FeaturedImage, &post.Published, &post.CreatedAt, &post.UpdatedAt)
+		var publishAt sql.NullTime
+		err := rows.Scan(&post.ID, &post.Title, &post.Slug, &post.Content, &post.Tags, &post.FeaturedImage, &post.Published, &publishAt, &post.CreatedAt, &post.UpdatedAt)
 		if err != nil {
 			return nil, err
 		}
+		setPublishAt(&post, publishAt, now)
 		posts = append(posts, post)
 	}
 
 	return posts, rows.Err()
 }
 
-func (r *PostRepository) GetPostsPaginated(limit, offset int) ([]models.Post, int, error) {
-	// Get total count
-	var total int
-	err := r.db.QueryRow("SELECT COUNT(*) FROM posts").Scan(&total)
-	if err != nil {
-		return nil, 0, err
-	}
-
-	// Get paginated posts
-	rows, err := r.db.Query("SELECT id, title, slug, featured_image, published, created_at FROM posts ORDER BY created_at DESC LIMIT ? OFFSET ?", limit, offset)
-	if err != nil {
-		return nil, 0, err
+// publishAtValue converts an optional publish time to the UTC value stored in the publish_at column
+func publishAtValue(publishAt *time.Time) interface{} {
+	if publishAt == nil {
+		return nil
 	}
-	defer rows.Close()
+	return publishAt.UTC()
+}
 
-	var posts []models.Post
-	for rows.Next() {
-		var post models.Post
-		err := rows.Scan(&post.ID, &post.Title, &post.Slug, &post.FeaturedImage, &post.Published, &post.CreatedAt)
-		if err != nil {
-			return nil, 0, err
-		}
-		posts = append(posts, post)
+// setPublishAt applies a scanned publish_at column to a post and derives its scheduled state
+func setPublishAt(post *models.Post, publishAt sql.NullTime, now time.Time) {
+	post.PublishAt = nil
+	if publishAt.Valid {
+		t := publishAt.Time
+		post.PublishAt = &t
 	}
-	return posts, total, rows.Err()
+	post.Scheduled = post.Published && !post.IsDue(now)
 }
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/models"
+)
+
+type PublishJobRepository struct {
+	db *sql.DB
+}
+
+func NewPublishJobRepository(db *sql.DB) *PublishJobRepository {
+	return &PublishJobRepository{db: db}
+}
+
+// CreatePublishJob stores a finished job
+func (r *PublishJobRepository) CreatePublishJob(job *models.PublishJob) error {
+	finished := time.Now()
+	if job.FinishedAt != nil {
+		finished = *job.FinishedAt
+	}
+	_, err := r.db.Exec(
+		"INSERT INTO publish_jobs (id, source, dry_run, status, written, skipped, deleted, error, started_at, finished_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		job.ID, job.Source, job.DryRun, job.Status, job.Written, job.Skipped, job.Deleted, job.Error, job.StartedAt.UTC(), finished.UTC(),
+	)
+	return err
+}
+
+// GetPublishJobs returns the most recently finished jobs, newest first
+func (r *PublishJobRepository) GetPublishJobs(limit int) ([]models.PublishJob, error) {
+	rows, err := r.db.Query("SELECT id, source, dry_run, status, written, skipped, deleted, error, started_at, finished_at FROM publish_jobs ORDER BY rowid DESC LIMIT ?", limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []models.PublishJob
+	for rows.Next() {
+		var job models.PublishJob
+		var finished time.Time
+		if err := rows.Scan(&job.ID, &job.Source, &job.DryRun, &job.Status, &job.Written, &job.Skipped, &job.Deleted, &job.Error, &job.StartedAt, &finished); err != nil {
+			return nil, err
+		}
+		job.FinishedAt = &finished
+		job.DurationMS = finished.Sub(job.StartedAt).Milliseconds()
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/models"
+)
+
+// insertPostRevision records the current content of a post as its next revision
+func insertPostRevision(tx *sql.Tx, post *models.Post) error {
+	_, err := tx.Exec(`INSERT INTO post_revisions (post_id, revision, title, slug, content, tags, featured_image, created_at)
+		VALUES (?, (SELECT COALESCE(MAX(revision), 0) + 1 FROM post_revisions WHERE post_id = ?), ?, ?, ?, ?, ?, ?)`,
+		post.ID, post.ID, post.Title, post.Slug, post.Content, post.Tags, post.FeaturedImage, time.Now())
+	return err
+}
+
+// insertPageRevision records the current content of a page as its next revision
+func insertPageRevision(tx *sql.Tx, page *models.Page) error {
+	_, err := tx.Exec(`INSERT INTO page_revisions (page_id, revision, title, slug, content, created_at)
+		VALUES (?, (SELECT COALESCE(MAX(revision), 0) + 1 FROM page_revisions WHERE page_id = ?), ?, ?, ?, ?)`,
+		page.ID, page.ID, page.Title, page.Slug, page.Content, time.Now())
+	return err
+}
+
+// GetPostRevisions returns the revisions of a post, newest first, without their content
+func (r *PostRepository) GetPostRevisions(postID int64) ([]models.PostRevision, error) {
+	rows, err := r.db.Query("SELECT id, post_id, revision, title, slug, tags, featured_image, created_at FROM post_revisions WHERE post_id = ? ORDER BY revision DESC", postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var revisions []models.PostRevision
+	for rows.Next() {
+		var rev models.PostRevision
+		if err := rows.Scan(&rev.ID, &rev.PostID, &rev.Revision, &rev.Title, &rev.Slug, &rev.Tags, &rev.FeaturedImage, &rev.CreatedAt); err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, rev)
+	}
+	return revisions, rows.Err()
+}
+
+// GetPostRevision returns a single revision of a post including its content.
+// A revision number of zero selects the latest revision.
+func (r *PostRepository) GetPostRevision(postID int64, revision int) (*models.PostRevision, error) {
+	query := "SELECT id, post_id, revision, title, slug, content, tags, featured_image, created_at FROM post_revisions WHERE post_id = ? AND revision = ?"
+	args := []interface{}{postID, revision}
+	if revision == 0 {
+		query = "SELECT id, post_id, revision, title, slug, content, tags, featured_image, created_at FROM post_revisions WHERE post_id = ? ORDER BY revision DESC LIMIT 1"
+		args = args[:1]
+	}
+
+	var rev models.PostRevision
+	err := r.db.QueryRow(query, args...).Scan(&rev.ID, &rev.PostID, &rev.Revision, &rev.Title, &rev.Slug, &rev.Content, &rev.Tags, &rev.FeaturedImage, &rev.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &rev, nil
+}
+
+// RestorePostRevision copies the content of a revision back onto the post, recording it as a new revision.
+// The post's published state and schedule are left unchanged.
+func (r *PostRepository) RestorePostRevision(postID int64, revision int) (*models.Post, error) {
+	rev, err := r.GetPostRevision(postID, revision)
+	if err != nil {
+		return nil, err
+	}
+	post, err := r.GetPostByID(postID)
+	if err != nil {
+		return nil, err
+	}
+
+	post.Title = rev.Title
+	post.Slug = rev.Slug
+	post.Content = rev.Content
+	post.Tags = rev.Tags
+	post.FeaturedImage = rev.FeaturedImage
+	post.UpdatedAt = time.Now()
+
+	if err := r.UpdatePost(post); err != nil {
+		return nil, err
+	}
+	return post, nil
+}
+
+// GetPageRevisions returns the revisions of a page, newest first, without their content
+func (r *PageRepository) GetPageRevisions(pageID int64) ([]models.PageRevision, error) {
+	rows, err := r.db.Query("SELECT id, page_id, revision, title, slug, created_at FROM page_revisions WHERE page_id = ? ORDER BY revision DESC", pageID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var revisions []models.PageRevision
+	for rows.Next() {
+		var rev models.PageRevision
+		if err := rows.Scan(&rev.ID, &rev.PageID, &rev.Revision, &rev.Title, &rev.Slug, &rev.CreatedAt); err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, rev)
+	}
+	return revisions, rows.Err()
+}
+
+// GetPageRevision returns a single revision of a page including its content.
+// A revision number of zero selects the latest revision.
+func (r *PageRepository) GetPageRevision(pageID int64, revision int) (*models.PageRevision, error) {
+	query := "SELECT id, page_id, revision, title, slug, content, created_at FROM page_revisions WHERE page_id = ? AND revision = ?"
+	args := []interface{}{pageID, revision}
+	if revision == 0 {
+		query = "SELECT id, page_id, revision, title, slug, content, created_at FROM page_revisions WHERE page_id = ? ORDER BY revision DESC LIMIT 1"
+		args = args[:1]
+	}
+
+	var rev models.PageRevision
+	err := r.db.QueryRow(query, args...).Scan(&rev.ID, &rev.PageID, &rev.Revision, &rev.Title, &rev.Slug, &rev.Content, &rev.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &rev, nil
+}
+
+// RestorePageRevision copies the content of a revision back onto the page, recording it as a new revision.
+// Navigation settings are left unchanged.
+func (r *PageRepository) RestorePageRevision(pageID int64, revision int) (*models.Page, error) {
+	rev, err := r.GetPageRevision(pageID, revision)
+	if err != nil {
+		return nil, err
+	}
+	page, err := r.GetPageByID(pageID)
+	if err != nil {
+		return nil, err
+	}
+
+	page.Title = rev.Title
+	page.Slug = rev.Slug
+	page.Content = rev.Content
+
+	if err := r.UpdatePage(page); err != nil {
+		return nil, err
+	}
+	return r.GetPageByID(pageID)
+}
@@ -11,6 +11,9 @@ type Settings struct {
 	ShowPortfolioMenu bool      `json:"show_portfolio_menu"`
 	ShowPostsMenu     bool      `json:"show_posts_menu"`
 	MenuOrder         string    `json:"menu_order"`
+	BaseURL           string    `json:"base_url"`
+	RobotsTxt         string    `json:"robots_txt"`
+	PostsPerPage      int       `json:"posts_per_page"`
 	CreatedAt         time.Time `json:"created_at"`
 	UpdatedAt         time.Time `json:"updated_at"`
 }
@@ -26,7 +29,7 @@ func NewSettingsRepository(db *sql.DB) *SettingsRepository {
 func (r *SettingsRepository) GetSettings() (*Settings, error) {
 	settings := &Settings{}
 	err := r.db.QueryRow(`
-		SELECT id, site_name, show_portfolio_menu, show_posts_menu, menu_order, created_at, updated_at
+		SELECT id, site_name, show_portfolio_menu, show_posts_menu, menu_order, base_url, robots_txt, posts_per_page, created_at, updated_at
 		FROM settings WHERE id = 1
 	`).Scan(
 		&settings.ID,
@@ -34,6 +37,9 @@ func (r *SettingsRepository) GetSettings() (*Settings, error) {
 		&settings.ShowPortfolioMenu,
 		&settings.ShowPostsMenu,
 		&settings.MenuOrder,
+		&settings.BaseURL,
+		&settings.RobotsTxt,
+		&settings.PostsPerPage,
 		&settings.CreatedAt,
 		&settings.UpdatedAt,
 	)
@@ -51,6 +57,9 @@ func (r *SettingsRepository) UpdateSettings(settings *Settings) error {
 			show_portfolio_menu = ?,
 			show_posts_menu = ?,
 			menu_order = ?,
+			base_url = ?,
+			robots_txt = ?,
+			posts_per_page = ?,
 			updated_at = ?
 		WHERE id = 1
 	`,
@@ -58,6 +67,9 @@ func (r *SettingsRepository) UpdateSettings(settings *Settings) error {
 		settings.ShowPortfolioMenu,
 		settings.ShowPostsMenu,
 		settings.MenuOrder,
+		settings.BaseURL,
+		settings.RobotsTxt,
+		settings.PostsPerPage,
 		settings.UpdatedAt,
 	)
 	return err
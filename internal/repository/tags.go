@@ -0,0 +1,300 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/models"
+	"github.com/ariefbayu/personal-blog-generator/internal/utils"
+)
+
+// ErrTagExists is returned when renaming a tag would collide with another tag's slug
+var ErrTagExists = errors.New("tag already exists")
+
+// ErrInvalidTagName is returned when a tag name is empty
+var ErrInvalidTagName = errors.New("invalid tag name")
+
+type TagRepository struct {
+	db *sql.DB
+}
+
+func NewTagRepository(db *sql.DB) *TagRepository {
+	return &TagRepository{db: db}
+}
+
+func (r *TagRepository) GetAllTags() ([]models.Tag, error) {
+	rows, err := r.db.Query(`
+		SELECT t.id, t.name, t.slug, COUNT(pt.post_id), t.created_at, t.updated_at
+		FROM tags t
+		LEFT JOIN post_tags pt ON pt.tag_id = t.id
+		GROUP BY t.id
+		ORDER BY LOWER(t.name) ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tags []models.Tag
+	for rows.Next() {
+		var tag models.Tag
+		err := rows.Scan(&tag.ID, &tag.Name, &tag.Slug, &tag.PostCount, &tag.CreatedAt, &tag.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, rows.Err()
+}
+
+func (r *TagRepository) GetTagByID(id int64) (*models.Tag, error) {
+	var tag models.Tag
+	err := r.db.QueryRow(`
+		SELECT t.id, t.name, t.slug, (SELECT COUNT(*) FROM post_tags WHERE tag_id = t.id), t.created_at, t.updated_at
+		FROM tags t WHERE t.id = ?
+	`, id).Scan(&tag.ID, &tag.Name, &tag.Slug, &tag.PostCount, &tag.CreatedAt, &tag.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// RenameTag changes a tag's name and slug and rewrites the tags column of every post using it
+func (r *TagRepository) RenameTag(id int64, name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return ErrInvalidTagName
+	}
+	slug := utils.TagSlug(name)
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var existingID int64
+	err = tx.QueryRow("SELECT id FROM tags WHERE slug = ?", slug).Scan(&existingID)
+	if err == nil && existingID != id {
+		return ErrTagExists
+	}
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+
+	result, err := tx.Exec("UPDATE tags SET name = ?, slug = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?", name, slug, id)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	if err := refreshPostTagsColumn(tx, "SELECT post_id FROM post_tags WHERE tag_id = ?", id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// MergeTags moves every post from the source tags onto the target tag and deletes the source tags
+func (r *TagRepository) MergeTags(sourceIDs []int64, targetID int64) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var exists int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM tags WHERE id = ?", targetID).Scan(&exists); err != nil {
+		return err
+	}
+	if exists == 0 {
+		return sql.ErrNoRows
+	}
+
+	for _, sourceID := range sourceIDs {
+		if sourceID == targetID {
+			continue
+		}
+
+		// Collect affected posts before the source associations are removed
+		affected, err := queryIDs(tx, "SELECT post_id FROM post_tags WHERE tag_id = ?", sourceID)
+		if err != nil {
+			return err
+		}
+
+		// Posts already carrying the target tag keep their original position for it
+		_, err = tx.Exec(`
+			INSERT OR IGNORE INTO post_tags (post_id, tag_id, position)
+			SELECT post_id, ?, position FROM post_tags WHERE tag_id = ?
+		`, targetID, sourceID)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec("DELETE FROM post_tags WHERE tag_id = ?", sourceID); err != nil {
+			return err
+		}
+		result, err := tx.Exec("DELETE FROM tags WHERE id = ?", sourceID)
+		if err != nil {
+			return err
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rowsAffected == 0 {
+			return sql.ErrNoRows
+		}
+
+		for _, postID := range affected {
+			if err := writePostTagsColumn(tx, postID); err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+// DeleteTag removes a tag from every post and deletes it
+func (r *TagRepository) DeleteTag(id int64) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	affected, err := queryIDs(tx, "SELECT post_id FROM post_tags WHERE tag_id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM post_tags WHERE tag_id = ?", id); err != nil {
+		return err
+	}
+	result, err := tx.Exec("DELETE FROM tags WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	for _, postID := range affected {
+		if err := writePostTagsColumn(tx, postID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// SyncPostTags replaces a post's tag associations with the tags in a comma-separated string,
+// creating missing tags. It returns the tags string rewritten with canonical tag names, so
+// "go" is stored as "Go" when a tag named "Go" already exists. Every tag is kept.
+// The tags migration runs it to backfill posts written before tags had tables of their own.
+func SyncPostTags(tx *sql.Tx, postID int64, raw string) (string, error) {
+	if _, err := tx.Exec("DELETE FROM post_tags WHERE post_id = ?", postID); err != nil {
+		return "", err
+	}
+
+	var names []string
+	seen := make(map[int64]bool)
+	for _, tag := range utils.ParseTags(raw) {
+		slug := utils.TagSlug(tag)
+		if _, err := tx.Exec("INSERT OR IGNORE INTO tags (name, slug) VALUES (?, ?)", tag, slug); err != nil {
+			return "", err
+		}
+		var tagID int64
+		var name string
+		if err := tx.QueryRow("SELECT id, name FROM tags WHERE slug = ?", slug).Scan(&tagID, &name); err != nil {
+			return "", err
+		}
+		if seen[tagID] {
+			continue
+		}
+		seen[tagID] = true
+		if _, err := tx.Exec("INSERT INTO post_tags (post_id, tag_id, position) VALUES (?, ?, ?)", postID, tagID, len(names)); err != nil {
+			return "", err
+		}
+		names = append(names, name)
+	}
+
+	canonical := strings.Join(names, ",")
+	if _, err := tx.Exec("UPDATE posts SET tags = ? WHERE id = ?", canonical, postID); err != nil {
+		return "", err
+	}
+	return canonical, nil
+}
+
+// refreshPostTagsColumn rewrites the tags column for every post returned by the query
+func refreshPostTagsColumn(tx *sql.Tx, query string, args ...interface{}) error {
+	postIDs, err := queryIDs(tx, query, args...)
+	if err != nil {
+		return err
+	}
+	for _, postID := range postIDs {
+		if err := writePostTagsColumn(tx, postID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writePostTagsColumn rebuilds a post's comma-separated tags column from post_tags
+func writePostTagsColumn(tx *sql.Tx, postID int64) error {
+	rows, err := tx.Query(`
+		SELECT t.name FROM post_tags pt
+		JOIN tags t ON t.id = pt.tag_id
+		WHERE pt.post_id = ?
+		ORDER BY pt.position ASC, t.name ASC
+	`, postID)
+	if err != nil {
+		return err
+	}
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return err
+		}
+		names = append(names, name)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("UPDATE posts SET tags = ? WHERE id = ?", strings.Join(names, ","), postID)
+	return err
+}
+
+// queryIDs runs a query returning a single integer column
+func queryIDs(tx *sql.Tx, query string, args ...interface{}) ([]int64, error) {
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
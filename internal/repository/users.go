@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/models"
+)
+
+// ErrUserExists is returned when creating a user whose username is already taken
+var ErrUserExists = errors.New("user already exists")
+
+type UserRepository struct {
+	db *sql.DB
+}
+
+func NewUserRepository(db *sql.DB) *UserRepository {
+	return &UserRepository{db: db}
+}
+
+func (r *UserRepository) CountUsers() (int, error) {
+	var count int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM users").Scan(&count)
+	return count, err
+}
+
+func (r *UserRepository) GetUserByID(id int64) (*models.User, error) {
+	var user models.User
+	err := r.db.QueryRow("SELECT id, username, password_hash, created_at, updated_at FROM users WHERE id = ?", id).Scan(&user.ID, &user.Username, &user.PasswordHash, &user.CreatedAt, &user.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *UserRepository) GetUserByUsername(username string) (*models.User, error) {
+	var user models.User
+	err := r.db.QueryRow("SELECT id, username, password_hash, created_at, updated_at FROM users WHERE username = ?", username).Scan(&user.ID, &user.Username, &user.PasswordHash, &user.CreatedAt, &user.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// CreateUser stores a user with an already hashed password
+func (r *UserRepository) CreateUser(user *models.User) error {
+	err := r.db.QueryRow("INSERT INTO users (username, password_hash) VALUES (?, ?) RETURNING id, created_at, updated_at", user.Username, user.PasswordHash).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
+	if err != nil && strings.Contains(err.Error(), "UNIQUE constraint failed") {
+		return ErrUserExists
+	}
+	return err
+}
+
+// CreateFirstUser creates a user only while the users table is empty, so concurrent setup requests cannot both succeed
+func (r *UserRepository) CreateFirstUser(user *models.User) error {
+	result, err := r.db.Exec("INSERT INTO users (username, password_hash) SELECT ?, ? WHERE NOT EXISTS (SELECT 1 FROM users)", user.Username, user.PasswordHash)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrUserExists
+	}
+
+	created, err := r.GetUserByUsername(user.Username)
+	if err != nil {
+		return err
+	}
+	*user = *created
+	return nil
+}
+
+// UpdatePasswordHash replaces a user's password and signs out all of their sessions
+func (r *UserRepository) UpdatePasswordHash(userID int64, passwordHash string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("UPDATE users SET password_hash = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?", passwordHash, userID)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	if _, err := tx.Exec("DELETE FROM sessions WHERE user_id = ?", userID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *UserRepository) CreateSession(session *models.Session) error {
+	return r.db.QueryRow("INSERT INTO sessions (token_hash, user_id, csrf_token, expires_at) VALUES (?, ?, ?, ?) RETURNING id, created_at", session.TokenHash, session.UserID, session.CSRFToken, session.ExpiresAt.UTC()).Scan(&session.ID, &session.CreatedAt)
+}
+
+// GetSession returns the session with the given token hash, or sql.ErrNoRows if it does not exist or has expired
+func (r *UserRepository) GetSession(tokenHash string) (*models.Session, error) {
+	var session models.Session
+	err := r.db.QueryRow("SELECT id, token_hash, user_id, csrf_token, expires_at, created_at FROM sessions WHERE token_hash = ?", tokenHash).Scan(&session.ID, &session.TokenHash, &session.UserID, &session.CSRFToken, &session.ExpiresAt, &session.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if !session.ExpiresAt.After(time.Now()) {
+		return nil, sql.ErrNoRows
+	}
+	return &session, nil
+}
+
+func (r *UserRepository) DeleteSession(tokenHash string) error {
+	_, err := r.db.Exec("DELETE FROM sessions WHERE token_hash = ?", tokenHash)
+	return err
+}
+
+// DeleteExpiredSessions removes sessions that expired before now
+func (r *UserRepository) DeleteExpiredSessions(now time.Time) error {
+	// expires_at is stored in UTC, so it compares correctly against a UTC time
+	_, err := r.db.Exec("DELETE FROM sessions WHERE expires_at <= ?", now.UTC())
+	return err
+}
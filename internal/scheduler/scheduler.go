@@ -0,0 +1,81 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/repository"
+)
+
+// DefaultInterval is how often the scheduler checks for posts that became due
+const DefaultInterval = time.Minute
+
+// Scheduler regenerates the static site when a scheduled post's publish_at time passes
+type Scheduler struct {
+	postRepo  *repository.PostRepository
+	generate  func() error
+	interval  time.Duration
+	lastCheck time.Time
+}
+
+// New creates a scheduler that calls generate whenever a scheduled post becomes due.
+// lastPublish is when the current site was last generated, such as generator.LastBuildTime; posts that
+// became due after it, including while the server was stopped, are published on the first check.
+// A zero lastPublish only considers posts that become due from now on.
+func New(postRepo *repository.PostRepository, generate func() error, interval time.Duration, lastPublish time.Time) *Scheduler {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	if lastPublish.IsZero() {
+		lastPublish = time.Now()
+	}
+	return &Scheduler{
+		postRepo:  postRepo,
+		generate:  generate,
+		interval:  interval,
+		lastCheck: lastPublish,
+	}
+}
+
+// Run checks for due posts right away and then on every tick until the context is cancelled
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	if _, err := s.check(time.Now()); err != nil {
+		log.Printf("Scheduled publish failed: %v", err)
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if _, err := s.check(now); err != nil {
+				log.Printf("Scheduled publish failed: %v", err)
+			}
+		}
+	}
+}
+
+// check regenerates the site if any post became due since the previous check.
+// It reports whether generation ran. A failed check is retried on the next tick.
+func (s *Scheduler) check(now time.Time) (bool, error) {
+	posts, err := s.postRepo.GetPostsDueBetween(s.lastCheck, now)
+	if err != nil {
+		return false, err
+	}
+	if len(posts) == 0 {
+		s.lastCheck = now
+		return false, nil
+	}
+
+	for _, post := range posts {
+		log.Printf("Scheduled post %q is due, regenerating site", post.Slug)
+	}
+	if err := s.generate(); err != nil {
+		return false, err
+	}
+	s.lastCheck = now
+	return true, nil
+}
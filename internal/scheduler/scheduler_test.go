@@ -0,0 +1,98 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ariefbayu/personal-blog-generator/internal/db"
+	"github.com/ariefbayu/personal-blog-generator/internal/models"
+	"github.com/ariefbayu/personal-blog-generator/internal/repository"
+)
+
+func newTestPostRepo(t *testing.T) *repository.PostRepository {
+	t.Helper()
+	testDB, err := db.Connect(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to connect to test DB: %v", err)
+	}
+	t.Cleanup(func() { testDB.Close() })
+
+	if err := db.Migrate(testDB); err != nil {
+		t.Fatalf("Failed to migrate test DB: %v", err)
+	}
+	return repository.NewPostRepository(testDB)
+}
+
+func TestSchedulerCheck(t *testing.T) {
+	postRepo := newTestPostRepo(t)
+	start := time.Now()
+	publishAt := start.Add(time.Hour)
+	post := &models.Post{Title: "Later", Slug: "later", Content: "Soon", Published: true, PublishAt: &publishAt, CreatedAt: start}
+	if err := postRepo.CreatePost(post); err != nil {
+		t.Fatalf("Failed to create post: %v", err)
+	}
+
+	generated := 0
+	s := New(postRepo, func() error {
+		generated++
+		return nil
+	}, time.Minute, start)
+
+	ran, err := s.check(start.Add(30 * time.Minute))
+	if err != nil {
+		t.Fatalf("check failed: %v", err)
+	}
+	if ran || generated != 0 {
+		t.Errorf("Expected no generation before publish_at, got %d", generated)
+	}
+
+	ran, err = s.check(start.Add(90 * time.Minute))
+	if err != nil {
+		t.Fatalf("check failed: %v", err)
+	}
+	if !ran || generated != 1 {
+		t.Errorf("Expected one generation once due, got %d", generated)
+	}
+
+	// The post is only announced once
+	ran, err = s.check(start.Add(120 * time.Minute))
+	if err != nil {
+		t.Fatalf("check failed: %v", err)
+	}
+	if ran || generated != 1 {
+		t.Errorf("Expected no further generation, got %d", generated)
+	}
+}
+
+func TestSchedulerCatchesUpAfterRestart(t *testing.T) {
+	postRepo := newTestPostRepo(t)
+	lastBuild := time.Now().Add(-2 * time.Hour)
+	// The post came due while the server was stopped
+	publishAt := lastBuild.Add(time.Hour)
+	post := &models.Post{Title: "Missed", Slug: "missed", Content: "Late", Published: true, PublishAt: &publishAt, CreatedAt: lastBuild}
+	if err := postRepo.CreatePost(post); err != nil {
+		t.Fatalf("Failed to create post: %v", err)
+	}
+
+	generated := 0
+	s := New(postRepo, func() error {
+		generated++
+		return nil
+	}, time.Minute, lastBuild)
+	ran, err := s.check(time.Now())
+	if err != nil {
+		t.Fatalf("check failed: %v", err)
+	}
+	if !ran || generated != 1 {
+		t.Errorf("Expected the missed post to be published on the first check, got %d", generated)
+	}
+
+	// Without a previous build only posts due from now on count
+	s = New(postRepo, func() error {
+		generated++
+		return nil
+	}, time.Minute, time.Time{})
+	if ran, err := s.check(time.Now()); err != nil || ran {
+		t.Errorf("Expected no generation without a last build time, got %v, %v", ran, err)
+	}
+}
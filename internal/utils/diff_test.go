@@ -0,0 +1,79 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to string
+		expected string
+	}{
+		{
+			name:     "Equal",
+			from:     "a\nb\n",
+			to:       "a\nb\n",
+			expected: "",
+		},
+		{
+			name:     "ChangedLine",
+			from:     "one\ntwo\nthree\n",
+			to:       "one\n2\nthree\n",
+			expected: "--- a\n+++ b\n@@ -1,3 +1,3 @@\n one\n-two\n+2\n three\n",
+		},
+		{
+			name:     "FromEmpty",
+			from:     "",
+			to:       "new\n",
+			expected: "--- a\n+++ b\n@@ -0,0 +1,1 @@\n+new\n",
+		},
+		{
+			name:     "SeparateHunks",
+			from:     "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			to:       "x\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\ny\n",
+			expected: "--- a\n+++ b\n@@ -1,4 +1,4 @@\n-1\n+x\n 2\n 3\n 4\n@@ -9,4 +9,4 @@\n 9\n 10\n 11\n-12\n+y\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := UnifiedDiff("a", "b", tt.from, tt.to)
+			if got != tt.expected {
+				t.Errorf("UnifiedDiff() =\n%s\nexpected:\n%s", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDiffLinesLargeChange(t *testing.T) {
+	// Every line differs, so the changed region is far larger than maxDiffCells
+	var a, b []string
+	for i := 0; i < 5000; i++ {
+		a = append(a, fmt.Sprintf("old %d", i))
+		b = append(b, fmt.Sprintf("new %d", i))
+	}
+	a = append([]string{"same"}, append(a, "end")...)
+	b = append([]string{"same"}, append(b, "end")...)
+
+	ops := diffLines(a, b)
+	if len(ops) != 10002 || ops[0] != (diffOp{' ', "same"}) || ops[len(ops)-1] != (diffOp{' ', "end"}) {
+		t.Fatalf("Expected the unchanged ends to be kept, got %d ops", len(ops))
+	}
+	for i, op := range ops[1 : len(ops)-1] {
+		want := byte('-')
+		if i >= 5000 {
+			want = '+'
+		}
+		if op.kind != want {
+			t.Fatalf("Expected the old lines removed and the new lines added, got %q at %d", op.kind, i)
+		}
+	}
+
+	diff := UnifiedDiff("a", "b", strings.Join(a, "\n"), strings.Join(b, "\n"))
+	if !strings.HasPrefix(diff, "--- a\n+++ b\n@@ -1,5002 +1,5002 @@\n same\n-old 0\n") {
+		t.Errorf("Unexpected diff header: %.60q", diff)
+	}
+}
@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// GetDBPath returns the SQLite database file used by the server and the command line
+func GetDBPath() string {
+	dbPath := os.Getenv("DB_PATH")
+	if dbPath == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "./blog.db" // fallback
+		}
+		dbPath = filepath.Join(homeDir, ".personal-blog-generator", "blog.db")
+	}
+	return dbPath
+}
+
+// GetTemplatePath returns the template directory used for site generation
+func GetTemplatePath() string {
+	templatePath := os.Getenv("TEMPLATE_PATH")
+	if templatePath == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "./templates" // fallback
+		}
+		templatePath = filepath.Join(homeDir, ".personal-blog-generator", "templates")
+	}
+	return templatePath
+}
+
+// GetOutputPath returns the directory the generated site is written to
+func GetOutputPath() string {
+	outputPath := os.Getenv("OUTPUT_PATH")
+	if outputPath == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "./html-outputs" // fallback
+		}
+		outputPath = filepath.Join(homeDir, "html-outputs")
+	}
+	return outputPath
+}
+
+// GetBackupPath returns the directory backups are written to
+func GetBackupPath() string {
+	backupPath := os.Getenv("BACKUP_PATH")
+	if backupPath == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "./backups" // fallback
+		}
+		backupPath = filepath.Join(homeDir, ".personal-blog-generator", "backups")
+	}
+	return backupPath
+}
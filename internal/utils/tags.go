@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"unicode"
+)
+
+// reservedTagSlugs are tag slugs whose page path is taken by another page: tags/index.html lists every tag
+var reservedTagSlugs = map[string]bool{"index": true}
+
+// slugSymbols spells out symbols that tell names apart, so "C++" and "C#" get different slugs
+var slugSymbols = map[rune]string{'+': "plus", '#': "sharp", '&': "and", '@': "at"}
+
+// ParseTags splits a comma-separated tag string into trimmed, non-empty tags
+func ParseTags(raw string) []string {
+	var tags []string
+	for _, tag := range strings.Split(raw, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// Slugify converts a name into a lowercase, hyphen-separated slug. Letters and digits of any script
+// are kept, a few symbols are spelled out and everything else separates words.
+// The result is empty when the name has no letters, digits or spelled-out symbols.
+func Slugify(name string) string {
+	var words []string
+	var word strings.Builder
+	flush := func() {
+		if word.Len() > 0 {
+			words = append(words, word.String())
+			word.Reset()
+		}
+	}
+	for _, r := range strings.ToLower(name) {
+		if symbol, ok := slugSymbols[r]; ok {
+			flush()
+			words = append(words, symbol)
+			continue
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || (unicode.Is(unicode.Mn, r) && word.Len() > 0) {
+			word.WriteRune(r)
+			continue
+		}
+		flush()
+	}
+	flush()
+	return strings.Join(words, "-")
+}
+
+// TagSlug returns the slug of a tag name. Tags made only of characters Slugify drops, such as emoji,
+// and tags whose slug is reserved, such as "Index", get a slug derived from a hash of the name,
+// so every tag has a page and a slug of its own.
+func TagSlug(name string) string {
+	if slug := Slugify(name); slug != "" && !reservedTagSlugs[slug] {
+		return slug
+	}
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(name))))
+	return "tag-" + hex.EncodeToString(sum[:4])
+}
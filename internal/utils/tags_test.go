@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := map[string]string{
+		"Web Dev":        "web-dev",
+		"  Go!  ":        "go",
+		"Node.js":        "node-js",
+		"C++":            "c-plus-plus",
+		"C#":             "c-sharp",
+		"Q&A":            "q-and-a",
+		"Café":           "café",
+		"cafe\u0301":     "cafe\u0301", // a combining accent stays with its letter
+		"日本語":            "日本語",
+		"Привет, мир":    "привет-мир",
+		"2024 in Review": "2024-in-review",
+		"🎉":              "",
+	}
+	for name, want := range tests {
+		if got := Slugify(name); got != want {
+			t.Errorf("Slugify(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestTagSlug(t *testing.T) {
+	if got := TagSlug("Go"); got != "go" {
+		t.Errorf("TagSlug(Go) = %q", got)
+	}
+	party, rocket := TagSlug("🎉"), TagSlug("🚀")
+	if !strings.HasPrefix(party, "tag-") || party == rocket {
+		t.Errorf("Expected distinct fallback slugs, got %q and %q", party, rocket)
+	}
+	if TagSlug(" 🎉 ") != party {
+		t.Error("The fallback slug should ignore surrounding spaces")
+	}
+	if index := TagSlug("Index"); index == "index" || !strings.HasPrefix(index, "tag-") {
+		t.Errorf("Expected the reserved index slug to be replaced, got %q", index)
+	}
+}
@@ -1,19 +1,31 @@
 package main
 
 import (
+	"context"
 	"embed"
-	"io/fs"
+	"errors"
+	"fmt"
+	iofs "io/fs"
 	"log"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 
+	"github.com/ariefbayu/personal-blog-generator/internal/auth"
+	"github.com/ariefbayu/personal-blog-generator/internal/backup"
+	"github.com/ariefbayu/personal-blog-generator/internal/cli"
+	"github.com/ariefbayu/personal-blog-generator/internal/content"
 	"github.com/ariefbayu/personal-blog-generator/internal/db"
+	"github.com/ariefbayu/personal-blog-generator/internal/generator"
 	"github.com/ariefbayu/personal-blog-generator/internal/handlers"
+	"github.com/ariefbayu/personal-blog-generator/internal/models"
+	"github.com/ariefbayu/personal-blog-generator/internal/publish"
 	"github.com/ariefbayu/personal-blog-generator/internal/repository"
+	"github.com/ariefbayu/personal-blog-generator/internal/scheduler"
 	"github.com/ariefbayu/personal-blog-generator/internal/utils"
 )
 
@@ -23,25 +35,53 @@ var adminFS embed.FS
 func main() {
 	utils.LoadEnv()
 
-	dbPath := os.Getenv("DB_PATH")
-	if dbPath == "" {
-		homeDir, err := os.UserHomeDir()
-		if err != nil {
-			dbPath = "./blog.db" // fallback
-		} else {
-			dbPath = filepath.Join(homeDir, ".personal-blog-generator", "blog.db")
+	args := os.Args[1:]
+	if len(args) == 0 || args[0] == "serve" || strings.HasPrefix(args[0], "-") {
+		if len(args) > 0 && args[0] == "serve" {
+			args = args[1:]
 		}
+		if err := serve(args); err != nil {
+			exit(err)
+		}
+		return
+	}
+
+	if err := cli.Run(args, cli.StdIO()); err != nil {
+		exit(err)
+	}
+}
+
+// exit reports a command error and exits. Usage errors have already been printed.
+func exit(err error) {
+	if !errors.Is(err, cli.ErrUsage) {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+	}
+	os.Exit(1)
+}
+
+// serve starts the admin server
+func serve(args []string) error {
+	fs, apply := cli.NewFlagSet("serve", cli.StdIO())
+	portFlag := fs.String("port", "", "port to listen on (overrides APP_PORT)")
+	if err := fs.Parse(args); err != nil {
+		return cli.ErrUsage
 	}
+	apply()
+	if *portFlag != "" {
+		os.Setenv("APP_PORT", *portFlag)
+	}
+
+	dbPath := utils.GetDBPath()
 
 	database, err := db.Connect(dbPath)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	defer database.Close()
 
 	err = db.Migrate(database)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 
 	log.Println("Database connected and migrated successfully")
@@ -50,70 +90,207 @@ func main() {
 	portfolioRepo := repository.NewPortfolioRepository(database)
 	pageRepo := repository.NewPageRepository(database)
 	settingsRepo := repository.NewSettingsRepository(database)
+	tagRepo := repository.NewTagRepository(database)
+	userRepo := repository.NewUserRepository(database)
+	apiTokenRepo := repository.NewAPITokenRepository(database)
 	apiHandlers := handlers.NewAPIHandlers(postRepo, portfolioRepo, pageRepo, settingsRepo)
 	portfolioHandlers := handlers.NewPortfolioHandlers(portfolioRepo)
 	pageHandlers := handlers.NewPageHandlers(pageRepo)
+	tagHandlers := handlers.NewTagHandlers(tagRepo)
+	revisionHandlers := handlers.NewRevisionHandlers(postRepo, pageRepo)
+	previewHandlers := handlers.NewPreviewHandlers(postRepo, pageRepo, settingsRepo)
+	authenticator := auth.NewAuthenticator(userRepo, apiTokenRepo)
+	authHandlers := handlers.NewAuthHandlers(userRepo, authenticator)
+	tokenHandlers := handlers.NewTokenHandlers(apiTokenRepo, authenticator)
+	contentHandlers := handlers.NewContentHandlers(content.NewStore(postRepo, pageRepo, portfolioRepo))
+	backupManager := backup.NewManager(database, utils.GetBackupPath(), utils.GetOutputPath(), utils.GetTemplatePath())
+	backupHandlers := handlers.NewBackupHandlers(backupManager)
+	site := publish.NewSite(postRepo, portfolioRepo, pageRepo, settingsRepo, utils.GetTemplatePath(), utils.GetOutputPath())
+	publishQueue := publish.NewQueue(site, repository.NewPublishJobRepository(database))
+	publishHandlers := handlers.NewPublishHandlers(publishQueue)
+	deploymentRepo := repository.NewDeploymentRepository(database)
+	deployHandlers := handlers.NewDeployHandlers(publishQueue, deploymentRepo)
+
+	// Create the first admin account from the environment, for servers set up without a browser
+	if username := os.Getenv("ADMIN_USERNAME"); username != "" {
+		user, err := auth.CreateInitialUser(userRepo, username, os.Getenv("ADMIN_PASSWORD"))
+		if err == nil {
+			log.Printf("Created admin account %q", user.Username)
+		} else if !errors.Is(err, repository.ErrUserExists) {
+			return err
+		}
+	}
+
+	// Regenerate the site in the background when scheduled posts become due,
+	// catching up on posts that came due since the last build
+	lastBuild, err := generator.LastBuildTime(site.OutputPath)
+	if err != nil {
+		log.Printf("Failed to read the last build time: %v", err)
+	}
+	publishScheduler := scheduler.New(postRepo, func() error {
+		_, err := publishQueue.Run(context.Background(), models.PublishSourceSchedule, false)
+		return err
+	}, scheduler.DefaultInterval, lastBuild)
+	go publishScheduler.Run(context.Background())
+
+	if interval := backup.Interval(); interval > 0 {
+		log.Printf("Backing up to %s every %s, keeping %d backups", backupManager.BackupPath, interval, backupManager.Keep)
+		go backupManager.Run(context.Background(), interval)
+	}
 
 	// Create sub-filesystem to strip admin-files/ prefix
-	adminSubFS, err := fs.Sub(adminFS, "admin-files")
+	adminSubFS, err := iofs.Sub(adminFS, "admin-files")
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	handlers.AdminFS = adminSubFS
 	handlers.DBPath = dbPath
+	handlers.TemplatePath = utils.GetTemplatePath()
+	handlers.OutputPath = utils.GetOutputPath()
 
 	r := chi.NewRouter()
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
 
-	r.Get("/api/posts", apiHandlers.GetPostsHandler)
-	r.Post("/api/posts", apiHandlers.CreatePostHandler)
-	r.Get("/api/posts/{id}", apiHandlers.GetPostHandler)
-	r.Put("/api/posts/{id}", apiHandlers.UpdatePostHandler)
-	r.Delete("/api/posts/{id}", apiHandlers.DeletePostHandler)
-	r.Get("/api/portfolio", portfolioHandlers.GetPortfolioItemsHandler)
-	r.Post("/api/portfolio", portfolioHandlers.CreatePortfolioItemHandler)
-	r.Get("/api/portfolio/{id}", portfolioHandlers.GetPortfolioItemHandler)
-	r.Put("/api/portfolio/{id}", portfolioHandlers.UpdatePortfolioItemHandler)
-	r.Delete("/api/portfolio/{id}", portfolioHandlers.DeletePortfolioItemHandler)
-	r.Get("/api/pages", pageHandlers.GetPagesHandler)
-	r.Post("/api/pages", pageHandlers.CreatePageHandler)
-	r.Get("/api/pages/{id}", pageHandlers.GetPageHandler)
-	r.Put("/api/pages/{id}", pageHandlers.UpdatePageHandler)
-	r.Delete("/api/pages/{id}", pageHandlers.DeletePageHandler)
-	r.Get("/api/settings", apiHandlers.GetSettingsHandler)
-	r.Post("/api/settings", apiHandlers.UpdateSettingsHandler)
-	r.Get("/api/settings/templates", apiHandlers.GetTemplatesHandler)
-	r.Get("/api/settings/templates/content", apiHandlers.GetTemplateContentHandler)
-	r.Post("/api/settings/templates/save", apiHandlers.SaveTemplateHandler)
-	r.Post("/api/upload/image", handlers.UploadImageHandler)
-	r.Post("/api/publish", apiHandlers.PublishSiteHandler)
+	// Sign-in and the assets the login page needs are public
+	adminFiles := http.StripPrefix("/admin/", http.FileServer(http.FS(handlers.AdminFS)))
+	r.Get("/admin/login", authHandlers.ServeLoginPage)
+	r.Handle("/admin/css/styles.css", adminFiles)
+	r.Handle("/admin/js/login.js", adminFiles)
+	r.Post("/api/auth/login", authHandlers.LoginHandler)
+	r.Post("/api/auth/setup", authHandlers.SetupHandler)
 	r.Handle("/images/*", http.StripPrefix("/images/", http.FileServer(http.Dir("html-outputs/images/"))))
+	// Template stylesheets, so previews render with the site's styles
+	r.Handle("/css/*", http.StripPrefix("/css/", http.FileServer(http.Dir(filepath.Join(utils.GetTemplatePath(), "static", "css")))))
 
-	// Admin root redirects (must come before static assets)
-	r.Get("/admin", func(w http.ResponseWriter, r *http.Request) {
-		http.Redirect(w, r, "/admin/dashboard", http.StatusFound)
-	})
-	r.Get("/admin/", func(w http.ResponseWriter, r *http.Request) {
-		http.Redirect(w, r, "/admin/dashboard", http.StatusFound)
-	})
+	// Everything else requires a signed-in user or an API token
+	r.Group(func(r chi.Router) {
+		r.Use(authenticator.RequireUser)
+
+		// Account management and the admin UI are for signed-in browsers only
+		r.Group(func(r chi.Router) {
+			r.Use(auth.RequireSession)
+
+			r.Post("/api/auth/logout", authHandlers.LogoutHandler)
+			r.Get("/api/auth/me", authHandlers.MeHandler)
+			r.Put("/api/auth/password", authHandlers.ChangePasswordHandler)
+			r.Get("/api/tokens", tokenHandlers.GetTokensHandler)
+			r.Post("/api/tokens", tokenHandlers.CreateTokenHandler)
+			r.Delete("/api/tokens/{id}", tokenHandlers.RevokeTokenHandler)
+			r.Post("/api/backups/restore", backupHandlers.RestoreUploadHandler)
+			r.Post("/api/backups/{name}/restore", backupHandlers.RestoreBackupHandler)
+
+			// Admin root redirects (must come before static assets)
+			r.Get("/admin", func(w http.ResponseWriter, r *http.Request) {
+				http.Redirect(w, r, "/admin/dashboard", http.StatusFound)
+			})
+			r.Get("/admin/", func(w http.ResponseWriter, r *http.Request) {
+				http.Redirect(w, r, "/admin/dashboard", http.StatusFound)
+			})
+
+			// Admin page routes
+			r.Get("/admin/dashboard", handlers.ServeDashboard)
+			r.Get("/admin/posts", handlers.ServePostsPage)
+			r.Get("/admin/posts/new", handlers.ServeNewPostPage)
+			r.Get("/admin/posts/{id}/edit", handlers.ServeEditPostPage)
+			r.Get("/admin/portfolio", handlers.ServePortfolioPage)
+			r.Get("/admin/portfolio/new", handlers.ServeNewPortfolioPage)
+			r.Get("/admin/portfolio/{id}/edit", handlers.ServeEditPortfolioPage)
+			r.Get("/admin/pages", handlers.ServePagesPage)
+			r.Get("/admin/pages/new", handlers.ServeNewPagePage)
+			r.Get("/admin/pages/{id}/edit", handlers.ServeEditPagePage)
+			r.Get("/admin/settings", handlers.ServeSettingsPage)
+			r.Get("/admin/templates", handlers.ServeTemplatesPage)
+			r.Get("/admin/preview/posts/{id}", previewHandlers.PreviewPostHandler)
+			r.Get("/admin/preview/pages/{id}", previewHandlers.PreviewPageHandler)
+
+			// Admin static assets, matched after the more specific routes above
+			r.Handle("/admin/*", adminFiles)
+		})
+
+		// Content API, also open to API tokens with the matching scope
+		r.Group(func(r chi.Router) {
+			r.Use(auth.RequireScope(auth.ScopePostsRead))
 
-	// Admin page routes
-	r.Get("/admin/dashboard", handlers.ServeDashboard)
-	r.Get("/admin/posts", handlers.ServePostsPage)
-	r.Get("/admin/posts/new", handlers.ServeNewPostPage)
-	r.Get("/admin/posts/{id}/edit", handlers.ServeEditPostPage)
-	r.Get("/admin/portfolio", handlers.ServePortfolioPage)
-	r.Get("/admin/portfolio/new", handlers.ServeNewPortfolioPage)
-	r.Get("/admin/portfolio/{id}/edit", handlers.ServeEditPortfolioPage)
-	r.Get("/admin/pages", handlers.ServePagesPage)
-	r.Get("/admin/pages/new", handlers.ServeNewPagePage)
-	r.Get("/admin/pages/{id}/edit", handlers.ServeEditPagePage)
-	r.Get("/admin/settings", handlers.ServeSettingsPage)
-	r.Get("/admin/templates", handlers.ServeTemplatesPage)
-
-	// Admin static assets (must come after specific routes to avoid catching them)
-	r.Handle("/admin/*", http.StripPrefix("/admin/", http.FileServer(http.FS(handlers.AdminFS))))
+			r.Get("/api/posts", apiHandlers.GetPostsHandler)
+			r.Get("/api/posts/{id}", apiHandlers.GetPostHandler)
+			r.Get("/api/posts/{id}/revisions", revisionHandlers.GetPostRevisionsHandler)
+			r.Get("/api/posts/{id}/revisions/diff", revisionHandlers.DiffPostRevisionsHandler)
+			r.Get("/api/posts/{id}/revisions/{rev}", revisionHandlers.GetPostRevisionHandler)
+			r.Get("/api/tags", tagHandlers.GetTagsHandler)
+		})
+		r.Group(func(r chi.Router) {
+			r.Use(auth.RequireScope(auth.ScopePostsWrite))
+
+			r.Post("/api/posts", apiHandlers.CreatePostHandler)
+			r.Put("/api/posts/{id}", apiHandlers.UpdatePostHandler)
+			r.Delete("/api/posts/{id}", apiHandlers.DeletePostHandler)
+			r.Post("/api/posts/{id}/revisions/{rev}/restore", revisionHandlers.RestorePostRevisionHandler)
+			r.Post("/api/tags/merge", tagHandlers.MergeTagsHandler)
+			r.Put("/api/tags/{id}", tagHandlers.RenameTagHandler)
+			r.Delete("/api/tags/{id}", tagHandlers.DeleteTagHandler)
+		})
+		r.Group(func(r chi.Router) {
+			r.Use(auth.RequireScope(auth.ScopePortfolioRead))
+
+			r.Get("/api/portfolio", portfolioHandlers.GetPortfolioItemsHandler)
+			r.Get("/api/portfolio/{id}", portfolioHandlers.GetPortfolioItemHandler)
+		})
+		r.Group(func(r chi.Router) {
+			r.Use(auth.RequireScope(auth.ScopePortfolioWrite))
+
+			r.Post("/api/portfolio", portfolioHandlers.CreatePortfolioItemHandler)
+			r.Put("/api/portfolio/{id}", portfolioHandlers.UpdatePortfolioItemHandler)
+			r.Delete("/api/portfolio/{id}", portfolioHandlers.DeletePortfolioItemHandler)
+		})
+		r.Group(func(r chi.Router) {
+			r.Use(auth.RequireScope(auth.ScopePagesRead))
+
+			r.Get("/api/pages", pageHandlers.GetPagesHandler)
+			r.Get("/api/pages/{id}", pageHandlers.GetPageHandler)
+			r.Get("/api/pages/{id}/revisions", revisionHandlers.GetPageRevisionsHandler)
+			r.Get("/api/pages/{id}/revisions/diff", revisionHandlers.DiffPageRevisionsHandler)
+			r.Get("/api/pages/{id}/revisions/{rev}", revisionHandlers.GetPageRevisionHandler)
+		})
+		r.Group(func(r chi.Router) {
+			r.Use(auth.RequireScope(auth.ScopePagesWrite))
+
+			r.Post("/api/pages", pageHandlers.CreatePageHandler)
+			r.Put("/api/pages/{id}", pageHandlers.UpdatePageHandler)
+			r.Delete("/api/pages/{id}", pageHandlers.DeletePageHandler)
+			r.Post("/api/pages/{id}/revisions/{rev}/restore", revisionHandlers.RestorePageRevisionHandler)
+		})
+		r.With(auth.RequireScope(auth.ScopeSettingsRead)).Get("/api/settings", apiHandlers.GetSettingsHandler)
+		r.With(auth.RequireScope(auth.ScopeSettingsWrite)).Post("/api/settings", apiHandlers.UpdateSettingsHandler)
+		r.Group(func(r chi.Router) {
+			r.Use(auth.RequireScope(auth.ScopeTemplatesRead))
+
+			r.Get("/api/settings/templates", apiHandlers.GetTemplatesHandler)
+			r.Get("/api/settings/templates/content", apiHandlers.GetTemplateContentHandler)
+		})
+		r.With(auth.RequireScope(auth.ScopeTemplatesWrite)).Post("/api/settings/templates/save", apiHandlers.SaveTemplateHandler)
+		r.With(auth.RequireScope(auth.ScopeMediaWrite)).Post("/api/upload/image", handlers.UploadImageHandler)
+		r.Group(func(r chi.Router) {
+			r.Use(auth.RequireScope(auth.ScopePublish))
+
+			r.Post("/api/publish", publishHandlers.PublishSiteHandler)
+			r.Get("/api/publish/jobs", publishHandlers.GetPublishJobsHandler)
+			r.Get("/api/publish/{job}/events", publishHandlers.PublishEventsHandler)
+			r.Get("/api/publish/builds", apiHandlers.GetPublishBuildsHandler)
+			r.Post("/api/publish/rollback", apiHandlers.PublishRollbackHandler)
+			r.Post("/api/deploy", deployHandlers.DeployHandler)
+			r.Get("/api/deploy/history", deployHandlers.GetDeploymentsHandler)
+		})
+		r.With(auth.RequireScope(auth.ScopeExport)).Get("/api/export", contentHandlers.ExportHandler)
+		r.With(auth.RequireScope(auth.ScopeImport)).Post("/api/import", contentHandlers.ImportHandler)
+		r.Group(func(r chi.Router) {
+			r.Use(auth.RequireScope(auth.ScopeBackup))
+
+			r.Get("/api/backups", backupHandlers.GetBackupsHandler)
+			r.Post("/api/backups", backupHandlers.CreateBackupHandler)
+			r.Get("/api/backups/{name}", backupHandlers.DownloadBackupHandler)
+		})
+	})
 
 	port := os.Getenv("APP_PORT")
 	if port == "" {
@@ -121,5 +298,5 @@ func main() {
 	}
 
 	log.Println("Starting server on port", port)
-	log.Fatal(http.ListenAndServe(":"+port, r))
+	return http.ListenAndServe(":"+port, r)
 }
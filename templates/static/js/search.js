@@ -0,0 +1,155 @@
+// Client-side search over /search-index.json, generated on every publish
+(function() {
+    const input = document.getElementById('search-input');
+    const status = document.getElementById('search-status');
+    const results = document.getElementById('search-results');
+    const maxResults = 50;
+    const snippetLength = 160;
+    let index = null;
+
+    function escapeHTML(text) {
+        const div = document.createElement('div');
+        div.textContent = text;
+        return div.innerHTML;
+    }
+
+    function normalize(text) {
+        return text.toLowerCase().normalize('NFD').replace(/[\u0300-\u036f]/g, '');
+    }
+
+    function terms(query) {
+        return normalize(query).split(/\s+/).filter(term => term.length > 0);
+    }
+
+    // score ranks title matches above tag matches above text matches.
+    // An entry matches only when every term is found somewhere.
+    function score(entry, queryTerms) {
+        const title = normalize(entry.title);
+        const tags = normalize((entry.tags || []).join(' '));
+        const text = normalize(entry.text);
+        let total = 0;
+        for (const term of queryTerms) {
+            let termScore = 0;
+            if (title.includes(term)) termScore += 10;
+            if (tags.includes(term)) termScore += 5;
+            if (text.includes(term)) termScore += 1;
+            if (termScore === 0) return 0;
+            total += termScore;
+        }
+        return total;
+    }
+
+    // fold normalizes text one character at a time. origin[i] is the offset in text of the character
+    // normalized[i] came from, so matches found in the normalized text can be located in the original.
+    function fold(text) {
+        let normalized = '';
+        const origin = [];
+        let offset = 0;
+        for (const char of text) {
+            const folded = normalize(char);
+            for (let i = 0; i < folded.length; i++) origin.push(offset);
+            normalized += folded;
+            offset += char.length;
+        }
+        origin.push(text.length);
+        return { normalized, origin };
+    }
+
+    // boundary moves an offset back off the second half of a surrogate pair
+    function boundary(text, offset) {
+        const code = text.charCodeAt(offset);
+        return offset > 0 && code >= 0xdc00 && code <= 0xdfff ? offset - 1 : offset;
+    }
+
+    // snippet returns escaped text around the first matching term, with matches highlighted
+    function snippet(text, queryTerms) {
+        const { normalized, origin } = fold(text);
+        const matches = [];
+        for (const term of queryTerms) {
+            for (let found = normalized.indexOf(term); found !== -1; found = normalized.indexOf(term, found + term.length)) {
+                matches.push([origin[found], origin[found + term.length]]);
+            }
+        }
+        matches.sort((a, b) => a[0] - b[0]);
+
+        const position = matches.length > 0 ? matches[0][0] : 0;
+        const start = boundary(text, Math.max(0, position - snippetLength / 4));
+        const end = boundary(text, Math.min(text.length, start + snippetLength));
+        let excerpt = start > 0 ? '…' : '';
+        let cursor = start;
+        for (const [from, to] of matches) {
+            // Skip matches overlapping one already highlighted or running past the excerpt
+            if (from < cursor || to > end) continue;
+            excerpt += escapeHTML(text.slice(cursor, from)) + '<mark>' + escapeHTML(text.slice(from, to)) + '</mark>';
+            cursor = to;
+        }
+        excerpt += escapeHTML(text.slice(cursor, end));
+        if (end < text.length) excerpt += '…';
+        return excerpt;
+    }
+
+    function render(query) {
+        const queryTerms = terms(query);
+        results.innerHTML = '';
+        if (queryTerms.length === 0) {
+            status.textContent = '';
+            return;
+        }
+
+        const matches = index
+            .map((entry, order) => ({ entry, order, score: score(entry, queryTerms) }))
+            .filter(match => match.score > 0)
+            .sort((a, b) => b.score - a.score || a.order - b.order);
+
+        status.textContent = matches.length === 1 ? '1 result' : matches.length + ' results';
+        matches.slice(0, maxResults).forEach(({ entry }) => {
+            const article = document.createElement('article');
+            article.className = 'card card-content card-hover';
+            const tags = (entry.tags || []).map(tag => '<span class="badge">' + escapeHTML(tag) + '</span>').join(' ');
+            article.innerHTML = `
+                ${tags ? '<div class="flex flex-wrap gap-2">' + tags + '</div>' : ''}
+                <h3 class="heading-3"><a class="link" href="/${encodeURIComponent(entry.slug)}.html">${escapeHTML(entry.title)}</a></h3>
+                <p class="text-body">${snippet(entry.text, queryTerms)}</p>
+                ${entry.date ? '<span class="text-caption">' + escapeHTML(entry.date) + '</span>' : ''}`;
+            results.appendChild(article);
+        });
+    }
+
+    function update() {
+        const query = input.value;
+        const url = new URL(window.location.href);
+        if (query) {
+            url.searchParams.set('q', query);
+        } else {
+            url.searchParams.delete('q');
+        }
+        history.replaceState(null, '', url);
+        if (index) render(query);
+    }
+
+    input.value = new URLSearchParams(window.location.search).get('q') || '';
+    input.form.addEventListener('submit', event => {
+        event.preventDefault();
+        update();
+    });
+
+    status.textContent = 'Loading…';
+    fetch('/search-index.json')
+        .then(response => {
+            if (!response.ok) throw new Error('HTTP error! status: ' + response.status);
+            return response.json();
+        })
+        .then(entries => {
+            index = entries;
+            let timer = null;
+            input.addEventListener('input', () => {
+                clearTimeout(timer);
+                timer = setTimeout(update, 150);
+            });
+            render(input.value);
+        })
+        .catch(error => {
+            console.error('Error loading search index:', error);
+            status.textContent = 'Search is not available right now.';
+        });
+})();